@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"net"
 	"os"
 
 	"rancher-questions-generator/internal/api"
@@ -13,10 +14,23 @@ func main() {
 		port = "8080"
 	}
 
-	router := api.SetupRouter()
-	
-	log.Printf("Starting server on port %s", port)
-	if err := router.Run(":" + port); err != nil {
+	certFile, keyFile, err := api.TLSFiles()
+	if err != nil {
+		log.Fatal("Invalid TLS configuration:", err)
+	}
+
+	server := api.NewServer(":"+port, api.SetupRouter())
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		log.Fatal("Failed to start server:", err)
+	}
+
+	if certFile != "" {
+		log.Printf("Starting HTTPS server on port %s", port)
+	} else {
+		log.Printf("Starting server on port %s", port)
+	}
+	if err := api.Serve(server, listener, certFile, keyFile); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"rancher-questions-generator/pkg/helm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// errorBody is the envelope every error response uses:
+// {"error": {"code": "...", "message": "...", "request_id": "..."}}.
+// Messages are meant for end users; internal details are only logged.
+type errorBody struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	RequestID string      `json:"request_id,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// respondError writes an error envelope and stops further handlers.
+func respondError(c *gin.Context, status int, code, message string) {
+	respondErrorDetails(c, status, code, message, nil)
+}
+
+// routeNotFound answers requests for unknown routes with the error envelope
+// instead of gin's plain-text 404.
+func routeNotFound(c *gin.Context) {
+	respondError(c, http.StatusNotFound, "not_found", "Route not found")
+}
+
+// respondErrorDetails is respondError with extra machine-readable details,
+// such as the list of valid choices for a bad parameter.
+func respondErrorDetails(c *gin.Context, status int, code, message string, details interface{}) {
+	c.AbortWithStatusJSON(status, gin.H{"error": errorBody{
+		Code:      code,
+		Message:   message,
+		RequestID: requestIDFrom(c),
+		Details:   details,
+	}})
+}
+
+// respondInternalError logs err with the request ID and returns a generic
+// message so paths and command output never reach the client.
+func respondInternalError(c *gin.Context, code, message string, err error) {
+	requestLogger(c).Error(message, "error", err)
+	respondError(c, http.StatusInternalServerError, code, message)
+}
+
+// respondBindError reports a request body that failed to decode or validate
+// without echoing Go type names from the binding error.
+func respondBindError(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrs):
+		problems := make([]string, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			problems = append(problems, fmt.Sprintf("%s is %s", strings.ToLower(fieldErr.Field()), fieldErr.Tag()))
+		}
+		respondError(c, http.StatusBadRequest, "invalid_request", strings.Join(problems, "; "))
+	case errors.Is(err, io.EOF):
+		respondError(c, http.StatusBadRequest, "invalid_request", "Request body is required")
+	default:
+		respondError(c, http.StatusBadRequest, "invalid_request", "Request body is not valid JSON")
+	}
+}
+
+// respondProcessError reports a chart processing failure.
+func respondProcessError(c *gin.Context, err error) {
+	if errors.Is(err, helm.ErrVerificationFailed) {
+		requestLogger(c).Warn("chart verification failed", "error", err)
+		respondError(c, http.StatusUnprocessableEntity, "chart_verification_failed", "Chart provenance verification failed")
+		return
+	}
+	if errors.Is(err, helm.ErrChartTooLarge) {
+		respondError(c, http.StatusRequestEntityTooLarge, "chart_too_large", err.Error())
+		return
+	}
+	respondInternalError(c, "chart_processing_failed", "Failed to download or process the chart", err)
+}
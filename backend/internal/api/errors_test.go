@@ -0,0 +1,103 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type errorEnvelope struct {
+	Error struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"request_id"`
+	} `json:"error"`
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/chart/non-existent", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var envelope errorEnvelope
+	err := json.Unmarshal(w.Body.Bytes(), &envelope)
+	assert.NoError(t, err)
+	assert.Equal(t, "session_not_found", envelope.Error.Code)
+	assert.Equal(t, "Session not found", envelope.Error.Message)
+	assert.NotEmpty(t, envelope.Error.RequestID)
+}
+
+func TestUnknownRouteEnvelope(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/non-existent", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	var envelope errorEnvelope
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	assert.Equal(t, "not_found", envelope.Error.Code)
+	assert.Equal(t, "Route not found", envelope.Error.Message)
+	assert.NotEmpty(t, envelope.Error.RequestID)
+	assert.NotContains(t, w.Body.String(), "404 page not found")
+}
+
+func TestErrorEnvelopeRequestIDsDiffer(t *testing.T) {
+	router := setupRouter()
+
+	ids := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/chart/non-existent", nil)
+		router.ServeHTTP(w, req)
+
+		var envelope errorEnvelope
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+		ids[envelope.Error.RequestID] = true
+	}
+	assert.Len(t, ids, 2)
+}
+
+func TestBindErrorsAreUserSafe(t *testing.T) {
+	router := setupRouter()
+
+	tests := []struct {
+		name            string
+		path            string
+		body            string
+		expectedMessage string
+	}{
+		{"missing field", "/api/repositories", `{"name": "test"}`, "url is required"},
+		{"malformed json", "/api/chart", `{"url": `, "Request body is not valid JSON"},
+		{"wrong field type", "/api/chart", `{"url": 42}`, "Request body is not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var envelope errorEnvelope
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+			assert.Equal(t, "invalid_request", envelope.Error.Code)
+			assert.Equal(t, tt.expectedMessage, envelope.Error.Message)
+			assert.NotContains(t, w.Body.String(), "RepositoryRequest")
+			assert.NotContains(t, w.Body.String(), "Go struct")
+		})
+	}
+}
@@ -1,12 +1,25 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"rancher-questions-generator/internal/models"
+	"rancher-questions-generator/internal/version"
 	"rancher-questions-generator/pkg/helm"
+	"rancher-questions-generator/pkg/questions"
 	"rancher-questions-generator/pkg/session"
+	"rancher-questions-generator/pkg/templates"
 
 	"github.com/gin-gonic/gin"
 	"gopkg.in/yaml.v3"
@@ -16,41 +29,115 @@ type Handlers struct {
 	sessionManager    *session.Manager
 	helmProcessor     *helm.Processor
 	repositoryManager *helm.RepositoryManager
+	// ordering is the house style applied to processed questions, if any.
+	ordering *questions.Ordering
 }
 
 func NewHandlers() *Handlers {
+	repositoryManager := helm.NewRepositoryManager()
+	processor := helm.NewProcessor()
+	processor.Credentials = repositoryManager.AuthForURL
+	// EXTRA_QUESTION_TYPES lists custom types accepted alongside Rancher's own
+	questions.RegisterTypes(strings.Split(os.Getenv("EXTRA_QUESTION_TYPES"), ",")...)
+
+	// WARM_CHART_CACHE=true fetches all repository indexes in the background
+	// at startup so the first searches are fast
+	if os.Getenv("WARM_CHART_CACHE") == "true" {
+		go repositoryManager.WarmCache(0)
+	}
+
+	// QUESTION_ORDERING_FILE points to group and variable weights that
+	// order processed questions in a house style
+	ordering, err := questions.LoadOrderingFile(os.Getenv("QUESTION_ORDERING_FILE"))
+	if err != nil {
+		slog.Warn("ignoring question ordering", "error", err)
+	}
+
 	return &Handlers{
-		sessionManager:    session.NewManager(),
-		helmProcessor:     helm.NewProcessor(),
-		repositoryManager: helm.NewRepositoryManager(),
+		sessionManager:    session.NewManagerWithStore(session.NewStoreFromEnv()),
+		helmProcessor:     processor,
+		repositoryManager: repositoryManager,
+		ordering:          ordering,
 	}
 }
 
 func (h *Handlers) ProcessChart(c *gin.Context) {
 	var req models.ChartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	session := h.sessionManager.CreateSession(req.URL)
+	if err := questions.ValidateTypeOverrides(req.TypeOverrides); err != nil {
+		respondError(c, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
 
-	values, questions, err := h.helmProcessor.ProcessChart(req.URL)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	profile, ok := parseProfile(c, req.Profile)
+	if !ok {
 		return
 	}
 
-	session.Values = values
-	session.Questions = questions
+	// ?reuse=true returns a recent session for the same chart as is, so
+	// clients retrying or double-submitting don't pile up sessions
+	if c.Query("reuse") == "true" {
+		if existing, err := h.sessionManager.FindByChartURL(req.URL); err == nil {
+			c.JSON(http.StatusOK, newChartResponse(existing))
+			return
+		}
+	}
 
-	response := models.ChartResponse{
-		SessionID: session.ID,
-		Values:    values,
-		Questions: questions,
+	h.processChartURL(c, req.URL, req.TypeOverrides, profile)
+}
+
+// parseProfile resolves the requested generation profile, responding with
+// 400 and reporting false when it is unknown.
+func parseProfile(c *gin.Context, name string) (helm.Profile, bool) {
+	profile, err := helm.ParseProfile(name)
+	if err != nil {
+		respondErrorDetails(c, http.StatusBadRequest, "invalid_parameter", err.Error(), gin.H{"available": helm.Profiles()})
+		return "", false
 	}
+	return profile, true
+}
 
-	c.JSON(http.StatusOK, response)
+// processChartURL processes the chart at chartURL into a new session and
+// writes the chart response.
+func (h *Handlers) processChartURL(c *gin.Context, chartURL string, typeOverrides map[string]string, profile helm.Profile) {
+	session := h.sessionManager.CreateSession(chartURL)
+
+	// The request context aborts the download if the client goes away
+	result, err := h.helmProcessor.ProcessChartProfile(c.Request.Context(), chartURL, profile)
+	if err != nil {
+		respondProcessError(c, err)
+		return
+	}
+
+	result.Questions = questions.ApplyTypeOverrides(result.Questions, typeOverrides)
+	// A chart's own questions.yaml keeps the order its authors chose, also
+	// when generated questions were merged after it
+	switch result.QuestionsSource {
+	case helm.QuestionsSourceChart:
+	case helm.QuestionsSourceMerged:
+		result.Questions = h.ordering.ApplyAfter(result.Questions, result.OriginalQuestions)
+	default:
+		result.Questions = h.ordering.Apply(result.Questions)
+	}
+	applyChartResult(session, result)
+	if err := h.sessionManager.SaveSession(session); err != nil {
+		respondInternalError(c, "session_save_failed", "Failed to save session", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newChartResponse(session))
+}
+
+// ClearSessions deletes every session, for resetting test and staging
+// deployments without a restart.
+func (h *Handlers) ClearSessions(c *gin.Context) {
+	removed := h.sessionManager.Clear()
+	requestLogger(c).Info("cleared sessions", "removed", removed)
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
 }
 
 func (h *Handlers) GetChart(c *gin.Context) {
@@ -58,35 +145,319 @@ func (h *Handlers) GetChart(c *gin.Context) {
 
 	session, err := h.sessionManager.GetSession(sessionID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
 		return
 	}
 
+	c.JSON(http.StatusOK, newChartResponse(session))
+}
+
+// applyChartResult copies the output of chart processing onto a session.
+func applyChartResult(session *models.Session, result *helm.ChartResult) {
+	session.Values = result.Values
+	session.Questions = result.Questions
+	session.Chart = result.Chart
+	session.Readme = result.Readme
+	session.Notes = result.Notes
+	session.ValuesSchema = result.ValuesSchema
+	session.OriginalQuestions = result.OriginalQuestions
+	session.QuestionsSource = result.QuestionsSource
+	session.HelmAvailable = result.HelmAvailable
+	session.QuestionsTruncated = result.QuestionsTruncated
+}
+
+// exampleDataWarning is shown when an OCI chart could not be pulled because
+// the helm CLI is missing and example values were used instead.
+const exampleDataWarning = "Showing example data; install helm for real charts"
+
+func newChartResponse(session *models.Session) models.ChartResponse {
 	response := models.ChartResponse{
-		SessionID: session.ID,
-		Values:    session.Values,
-		Questions: session.Questions,
+		SessionID:     session.ID,
+		Values:        session.Values,
+		Questions:     session.Questions,
+		Chart:         session.Chart,
+		Readme:        session.Readme,
+		Notes:         session.Notes,
+		HelmAvailable: session.HelmAvailable,
+
+		QuestionsTruncated: session.QuestionsTruncated,
+		QuestionsSource:    session.QuestionsSource,
 	}
+	if session.HelmAvailable != nil && !*session.HelmAvailable {
+		response.Warning = exampleDataWarning
+	}
+	return response
+}
 
+func (h *Handlers) UpdateChart(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	// Decode numbers as json.Number so integer defaults don't pass through
+	// float64 and lose precision or their type
+	var updated models.Questions
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&updated); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	updated = questions.NormalizeDefaults(updated)
+
+	warnings, err := questions.ValidateQuestions(updated)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
+
+	// Clients that only edit questions keep the session's group weights
+	if updated.Groups == nil {
+		if session, err := h.sessionManager.GetSession(sessionID); err == nil {
+			updated.Groups = session.Questions.Groups
+		}
+	}
+
+	err = h.sessionManager.UpdateSession(sessionID, questions.OrderByGroupWeight(updated))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	response := gin.H{"message": "Questions updated successfully"}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
 	c.JSON(http.StatusOK, response)
 }
 
-func (h *Handlers) UpdateChart(c *gin.Context) {
+// ValidateQuestions checks the questions in the body without saving them,
+// reporting errors, which would make an update fail, separately from
+// warnings about questions that work but may confuse users.
+func (h *Handlers) ValidateQuestions(c *gin.Context) {
+	var set models.Questions
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&set); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	warnings, err := questions.ValidateQuestions(questions.NormalizeDefaults(set))
+	problems := []string{}
+	var validationErr *questions.ValidationError
+	if errors.As(err, &validationErr) {
+		problems = validationErr.Problems
+	} else if err != nil {
+		problems = []string{err.Error()}
+	}
+	if warnings == nil {
+		warnings = []string{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": len(problems) == 0, "errors": problems, "warnings": warnings})
+}
+
+// EvaluateVisibility reports which questions would be visible for the
+// answers in the body (variable to value), resolving show_if and
+// show_subquestion_if the way the Rancher UI does.
+func (h *Handlers) EvaluateVisibility(c *gin.Context) {
+	var answers map[string]interface{}
+	if err := c.ShouldBindJSON(&answers); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(c.Param("session_id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"visible": questions.VisibleQuestions(session.Questions, answers)})
+}
+
+// GetGroups lists the session's question groups in first-appearance order
+// with the number of questions in each.
+func (h *Handlers) GetGroups(c *gin.Context) {
+	session, err := h.sessionManager.GetSession(c.Param("session_id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"groups": questions.GroupCounts(session.Questions)})
+}
+
+// SetGroupWeights replaces the session's group weights and reorders its
+// questions so the groups render in weight order.
+func (h *Handlers) SetGroupWeights(c *gin.Context) {
 	sessionID := c.Param("session_id")
 
-	var questions models.Questions
-	if err := c.ShouldBindJSON(&questions); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req models.GroupWeightsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(sessionID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	updated := session.Questions
+	updated.Groups = req.Groups
+	updated = questions.OrderByGroupWeight(updated)
+	if err := h.sessionManager.UpdateSession(sessionID, updated); err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *Handlers) ApplyTemplate(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	var req models.ApplyTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	template, err := templates.Get(req.Template)
+	if err != nil {
+		respondErrorDetails(c, http.StatusBadRequest, "template_not_found", err.Error(), gin.H{"available": templates.Names()})
 		return
 	}
 
-	err := h.sessionManager.UpdateSession(sessionID, questions)
+	session, err := h.sessionManager.GetSession(sessionID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	updated := questions.OrderByGroupWeight(template.Apply(session.Questions))
+	if err := h.sessionManager.UpdateSession(sessionID, updated); err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Questions updated successfully"})
+	c.JSON(http.StatusOK, updated)
+}
+
+// BulkSetType changes the type of every question whose variable matches a
+// glob pattern, e.g. making all `resources.*` questions strings.
+func (h *Handlers) BulkSetType(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	var req models.BulkTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if !questions.IsKnownType(req.Type) {
+		respondErrorDetails(c, http.StatusBadRequest, "validation_failed", fmt.Sprintf("unknown type %q", req.Type), gin.H{"available": questions.KnownTypes()})
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(sessionID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	updated, changed, err := questions.SetTypeByPattern(session.Questions, req.Pattern, req.Type)
+	if err == nil {
+		_, err = questions.ValidateQuestions(updated)
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
+
+	if err := h.sessionManager.UpdateSession(sessionID, updated); err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	if changed == nil {
+		changed = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{"changed": changed, "questions": updated})
+}
+
+// UndoChart restores the session's questions to their state before the last
+// edit.
+func (h *Handlers) UndoChart(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	restored, err := h.sessionManager.Undo(sessionID)
+	if errors.Is(err, session.ErrNoRevisions) {
+		respondError(c, http.StatusConflict, "nothing_to_undo", "No earlier revision to restore")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, restored.Questions)
+}
+
+func (h *Handlers) GetFlatValues(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	session, err := h.sessionManager.GetSession(sessionID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"values": helm.FlattenValues(session.Values)})
+}
+
+// ApplyFlatValues rebuilds the session's nested values from dotted-path
+// defaults edited in the UI. Submitted paths replace the existing values
+// entirely; string inputs are coerced to the types the chart defined.
+func (h *Handlers) ApplyFlatValues(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	var flat map[string]interface{}
+	if err := c.ShouldBindJSON(&flat); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(sessionID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	coerced, err := helm.CoerceFlatValues(flat, helm.FlattenValues(session.Values))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_value", err.Error())
+		return
+	}
+
+	values := helm.UnflattenValues(coerced)
+	var schemaErr *helm.ValuesSchemaError
+	if err := helm.ValidateValues(session.ValuesSchema, values); errors.As(err, &schemaErr) {
+		respondErrorDetails(c, http.StatusBadRequest, "schema_validation_failed", helm.ErrInvalidValues.Error(), gin.H{"errors": schemaErr.Problems})
+		return
+	} else if err != nil {
+		// A broken schema would fail helm install too, but shouldn't block
+		// editing values here
+		requestLogger(c).Warn("skipping values schema validation", "error", err)
+	}
+
+	if err := h.sessionManager.UpdateValues(sessionID, values); err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"values": values})
 }
 
 func (h *Handlers) GetQuestionsYAML(c *gin.Context) {
@@ -94,31 +465,141 @@ func (h *Handlers) GetQuestionsYAML(c *gin.Context) {
 
 	session, err := h.sessionManager.GetSession(sessionID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
 		return
 	}
 
-	yamlData, err := yaml.Marshal(session.Questions)
+	// An explicit ?format wins; otherwise JSON is served only when the
+	// client asks for it, keeping YAML the default.
+	format := c.Query("format")
+	if format == "" && strings.Contains(c.GetHeader("Accept"), "application/json") {
+		format = "json"
+	}
+
+	var yamlData []byte
+	switch format {
+	case "", "yaml":
+		yamlData, err = yaml.Marshal(questions.NormalizeDefaults(session.Questions))
+	case "multidoc":
+		yamlData, err = marshalQuestionsByGroup(questions.NormalizeDefaults(session.Questions))
+	case "json":
+		c.JSON(http.StatusOK, session.Questions)
+		return
+	default:
+		respondError(c, http.StatusBadRequest, "invalid_parameter", "format must be one of yaml, json, multidoc")
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate YAML"})
+		respondInternalError(c, "yaml_generation_failed", "Failed to generate YAML", err)
 		return
 	}
 
+	// Scripts piping the output can ask for it inline instead of as a download
+	disposition := "attachment"
+	if c.Query("inline") == "true" {
+		disposition = "inline"
+	}
 	c.Header("Content-Type", "application/x-yaml")
-	c.Header("Content-Disposition", "attachment; filename=questions.yaml")
+	c.Header("Content-Disposition", disposition+"; filename=questions.yaml")
 	c.String(http.StatusOK, string(yamlData))
 }
 
+// GetOriginalQuestions returns the chart's own questions.yaml as shipped,
+// before merging with generated questions, or an empty set when the chart
+// has none.
+func (h *Handlers) GetOriginalQuestions(c *gin.Context) {
+	session, err := h.sessionManager.GetSession(c.Param("session_id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	original := session.OriginalQuestions
+	if original.Questions == nil {
+		original.Questions = []models.Question{}
+	}
+	c.JSON(http.StatusOK, original)
+}
+
+// RenderChart renders the session's chart with its current values through
+// helm template, so users can check their defaults produce valid manifests.
+func (h *Handlers) RenderChart(c *gin.Context) {
+	session, err := h.sessionManager.GetSession(c.Param("session_id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	manifests, err := h.helmProcessor.RenderChart(c.Request.Context(), session.ChartURL, session.Values)
+	switch {
+	case errors.Is(err, helm.ErrHelmUnavailable):
+		respondError(c, http.StatusServiceUnavailable, "helm_unavailable", "Rendering requires the helm CLI on the server")
+		return
+	case errors.Is(err, helm.ErrRenderFailed):
+		respondError(c, http.StatusUnprocessableEntity, "render_failed", err.Error())
+		return
+	case err != nil:
+		respondProcessError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"manifests": manifests})
+}
+
+// GetValuesSchema serves a values.schema.json derived from the session's
+// questions and values, suitable for committing alongside the chart.
+func (h *Handlers) GetValuesSchema(c *gin.Context) {
+	session, err := h.sessionManager.GetSession(c.Param("session_id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "session_not_found", "Session not found")
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=values.schema.json")
+	c.IndentedJSON(http.StatusOK, questions.ValuesSchema(session.Questions, session.Values))
+}
+
+// marshalQuestionsByGroup renders one YAML document per question group, in
+// order of each group's first appearance, separated by "---".
+func marshalQuestionsByGroup(set models.Questions) ([]byte, error) {
+	var order []string
+	groups := make(map[string][]models.Question)
+	for _, question := range set.Questions {
+		if _, seen := groups[question.Group]; !seen {
+			order = append(order, question.Group)
+		}
+		groups[question.Group] = append(groups[question.Group], question)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	for _, group := range order {
+		if err := encoder.Encode(models.Questions{Questions: groups[group]}); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (h *Handlers) HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
+// Version reports the running build: version, git commit, build date and Go
+// runtime version.
+func (h *Handlers) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, version.Get())
+}
+
 // Repository management endpoints
 
 func (h *Handlers) AddRepository(c *gin.Context) {
 	var req models.RepositoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
@@ -128,18 +609,160 @@ func (h *Handlers) AddRepository(c *gin.Context) {
 		repoType = "oci"
 	}
 
-	err := h.repositoryManager.AddRepositoryWithAuth(req.Name, req.URL, req.Description, repoType, req.Auth)
+	add := h.repositoryManager.AddRepositoryWithAuth
+	if c.Query("overwrite") == "true" {
+		add = h.repositoryManager.ReplaceRepositoryWithAuth
+	}
+
+	err := add(req.Name, req.URL, req.Description, repoType, req.Auth)
+	if errors.Is(err, helm.ErrInvalidRepositoryName) || errors.Is(err, helm.ErrInvalidRepositoryURL) {
+		respondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if errors.Is(err, helm.ErrRepositoryExists) {
+		respondError(c, http.StatusConflict, "repository_exists",
+			fmt.Sprintf("Repository %q already exists; pass ?overwrite=true to replace it", req.Name))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternalError(c, "repository_add_failed", "Failed to add repository", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Repository added successfully"})
 }
 
+// GetRepositoryStatus reports which default repositories were registered at
+// startup and which failed, with the error for each failure.
+func (h *Handlers) GetRepositoryStatus(c *gin.Context) {
+	statuses := h.repositoryManager.InitStatus()
+	failed := 0
+	for _, status := range statuses {
+		if !status.Ready {
+			failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"repositories": statuses,
+		"failed":       failed,
+	})
+}
+
+// WarmRepositories fetches every repository's charts into the chart cache
+// and reports the time taken and charts found per repository.
+func (h *Handlers) WarmRepositories(c *gin.Context) {
+	start := time.Now()
+	results := h.repositoryManager.WarmCache(0)
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"repositories": results,
+		"failed":       failed,
+		"duration_ms":  time.Since(start).Milliseconds(),
+	})
+}
+
 func (h *Handlers) ListRepositories(c *gin.Context) {
-	repositories := h.repositoryManager.ListRepositories()
-	c.JSON(http.StatusOK, gin.H{"repositories": repositories})
+	repoType := c.Query("type")
+	if repoType != "" && repoType != "http" && repoType != "oci" {
+		respondError(c, http.StatusBadRequest, "invalid_parameter", "type must be http or oci")
+		return
+	}
+
+	repositories := filterRepositories(h.repositoryManager.ListRepositories(), repoType, c.Query("q"))
+	if err := sortRepositories(repositories, c.Query("sort")); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+	total := len(repositories)
+
+	offset, err := nonNegativeQueryInt(c, "offset", 0)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+	limit, err := nonNegativeQueryInt(c, "limit", total)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"repositories": repositories[offset:end],
+		"total":        total,
+	})
+}
+
+// filterRepositories keeps repositories of the given type whose name contains
+// query (case-insensitive). Empty filters match everything.
+func filterRepositories(repositories []*models.Repository, repoType, query string) []*models.Repository {
+	query = strings.ToLower(query)
+	filtered := make([]*models.Repository, 0, len(repositories))
+	for _, repo := range repositories {
+		if repoType != "" && repo.Type != repoType {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(repo.Name), query) {
+			continue
+		}
+		filtered = append(filtered, repo)
+	}
+	return filtered
+}
+
+// sortRepositories orders repositories by "name" or "added_at", descending
+// when the key is prefixed with "-". An empty key keeps the existing order.
+func sortRepositories(repositories []*models.Repository, key string) error {
+	if key == "" {
+		return nil
+	}
+
+	descending := strings.HasPrefix(key, "-")
+	var less func(a, b *models.Repository) bool
+	switch strings.TrimPrefix(key, "-") {
+	case "name":
+		less = func(a, b *models.Repository) bool { return a.Name < b.Name }
+	case "added_at":
+		less = func(a, b *models.Repository) bool { return a.AddedAt.Before(b.AddedAt) }
+	default:
+		return fmt.Errorf("sort must be one of name, -name, added_at, -added_at")
+	}
+
+	sort.SliceStable(repositories, func(i, j int) bool {
+		if descending {
+			return less(repositories[j], repositories[i])
+		}
+		return less(repositories[i], repositories[j])
+	})
+	return nil
+}
+
+// nonNegativeQueryInt parses an optional non-negative integer query parameter.
+func nonNegativeQueryInt(c *gin.Context, name string, fallback int) (int, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return value, nil
 }
 
 func (h *Handlers) RemoveRepository(c *gin.Context) {
@@ -147,7 +770,7 @@ func (h *Handlers) RemoveRepository(c *gin.Context) {
 	
 	err := h.repositoryManager.RemoveRepository(name)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, http.StatusNotFound, "repository_not_found", err.Error())
 		return
 	}
 
@@ -164,9 +787,12 @@ func (h *Handlers) SearchCharts(c *gin.Context) {
 
 	charts, err := h.repositoryManager.SearchCharts(req.Query, req.Repository)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternalError(c, "search_failed", "Failed to search charts", err)
 		return
 	}
+	if c.Query("dedupe") == "true" {
+		charts = helm.DedupeCharts(charts)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"charts": charts})
 }
@@ -174,44 +800,177 @@ func (h *Handlers) SearchCharts(c *gin.Context) {
 func (h *Handlers) ProcessChartFromRepository(c *gin.Context) {
 	var req models.ChartProcessRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	// Get chart URL from repository
-	chartURL, err := h.repositoryManager.PullChart(req.Repository, req.Chart, req.Version)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := questions.ValidateTypeOverrides(req.TypeOverrides); err != nil {
+		respondError(c, http.StatusBadRequest, "validation_failed", err.Error())
 		return
 	}
 
-	// Create session
-	session := h.sessionManager.CreateSession(chartURL)
+	profile, ok := parseProfile(c, req.Profile)
+	if !ok {
+		return
+	}
 
-	// Process the chart
-	values, questions, err := h.helmProcessor.ProcessChart(chartURL)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Dry run only resolves the chart URL, skipping download and session creation
+	if c.Query("dry_run") == "true" {
+		chartURL, err := h.repositoryManager.ResolveChartURL(req.Repository, req.Chart, req.Version)
+		if err != nil {
+			respondError(c, http.StatusNotFound, "repository_not_found", err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, models.ChartResolveResponse{
+			ChartURL:   chartURL,
+			Repository: req.Repository,
+			Chart:      req.Chart,
+			Version:    req.Version,
+		})
 		return
 	}
 
-	session.Values = values
-	session.Questions = questions
+	h.processRepositoryChart(c, req.Repository, req.Chart, req.Version, req.TypeOverrides, profile)
+}
 
-	response := models.ChartResponse{
-		SessionID: session.ID,
-		Values:    values,
-		Questions: questions,
+// processRepositoryChart resolves a chart from a repository and processes it
+// into a new session.
+func (h *Handlers) processRepositoryChart(c *gin.Context, repository, chart, version string, typeOverrides map[string]string, profile helm.Profile) {
+	chartURL, err := h.repositoryManager.PullChart(repository, chart, version)
+	switch {
+	case errors.Is(err, helm.ErrRepositoryNotFound):
+		respondError(c, http.StatusNotFound, "repository_not_found", err.Error())
+		return
+	case errors.Is(err, helm.ErrAuthRequired):
+		respondError(c, http.StatusUnauthorized, "auth_required", "The registry requires valid credentials for this chart")
+		return
+	case err != nil:
+		respondInternalError(c, "chart_pull_failed", "Failed to pull the chart", err)
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	h.processChartURL(c, chartURL, typeOverrides, profile)
+}
+
+// ProcessChartSource accepts either a direct chart URL or a repository and
+// chart name, so clients don't have to pick between /chart and
+// /charts/process.
+func (h *Handlers) ProcessChartSource(c *gin.Context) {
+	var req models.ChartSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	hasURL := req.URL != ""
+	hasRepository := req.Repository != "" || req.Chart != "" || req.Version != ""
+	switch {
+	case hasURL && hasRepository:
+		respondError(c, http.StatusBadRequest, "invalid_request", "Provide either url or repository and chart, not both")
+		return
+	case !hasURL && !hasRepository:
+		respondError(c, http.StatusBadRequest, "invalid_request", "Provide either url or repository and chart")
+		return
+	case hasRepository && (req.Repository == "" || req.Chart == ""):
+		respondError(c, http.StatusBadRequest, "invalid_request", "repository and chart are both required")
+		return
+	}
+
+	if err := questions.ValidateTypeOverrides(req.TypeOverrides); err != nil {
+		respondError(c, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
+
+	profile, ok := parseProfile(c, req.Profile)
+	if !ok {
+		return
+	}
+
+	if hasURL {
+		h.processChartURL(c, req.URL, req.TypeOverrides, profile)
+		return
+	}
+	h.processRepositoryChart(c, req.Repository, req.Chart, req.Version, req.TypeOverrides, profile)
 }
+
+// DiffChartVersions processes two versions of a repository chart and reports
+// the values and questions that were added, removed or changed between them.
+func (h *Handlers) DiffChartVersions(c *gin.Context) {
+	repository, chart := c.Param("repository"), c.Param("chart")
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		respondError(c, http.StatusBadRequest, "invalid_parameter", "from and to versions are required")
+		return
+	}
+
+	if _, err := h.repositoryManager.ResolveChartURL(repository, chart, from); err != nil {
+		respondError(c, http.StatusNotFound, "repository_not_found", err.Error())
+		return
+	}
+
+	results := make([]*helm.ChartResult, 0, 2)
+	for _, version := range []string{from, to} {
+		result, err := h.processChartVersion(c.Request.Context(), repository, chart, version)
+		if err != nil {
+			requestLogger(c).Warn("chart version unavailable", "chart", chart, "version", version, "error", err)
+			respondError(c, http.StatusBadGateway, "chart_version_unavailable",
+				fmt.Sprintf("Could not fetch %s version %s: %s", chart, version, helm.ErrorSummary(err)))
+			return
+		}
+		results = append(results, result)
+	}
+
+	added, removed := diffQuestionVariables(results[0].Questions, results[1].Questions)
+	c.JSON(http.StatusOK, gin.H{
+		"repository": repository,
+		"chart":      chart,
+		"from":       from,
+		"to":         to,
+		"values":     helm.DiffValues(results[0].Values, results[1].Values),
+		"questions":  gin.H{"added": added, "removed": removed},
+	})
+}
+
+// processChartVersion pulls and processes one version of a repository chart
+// without creating a session.
+func (h *Handlers) processChartVersion(ctx context.Context, repository, chart, version string) (*helm.ChartResult, error) {
+	chartURL, err := h.repositoryManager.PullChart(repository, chart, version)
+	if err != nil {
+		return nil, err
+	}
+	return h.helmProcessor.ProcessChartContext(ctx, chartURL)
+}
+
+// diffQuestionVariables lists the question variables only in to (added) and
+// only in from (removed), in question order.
+func diffQuestionVariables(from, to models.Questions) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	fromVars := make(map[string]bool, len(from.Questions))
+	for _, q := range from.Questions {
+		fromVars[q.Variable] = true
+	}
+	toVars := make(map[string]bool, len(to.Questions))
+	for _, q := range to.Questions {
+		toVars[q.Variable] = true
+		if !fromVars[q.Variable] {
+			added = append(added, q.Variable)
+		}
+	}
+	for _, q := range from.Questions {
+		if !toVars[q.Variable] {
+			removed = append(removed, q.Variable)
+		}
+	}
+	return added, removed
+}
+
 func (h *Handlers) GetRepositoryCharts(c *gin.Context) {
 	repositoryName := c.Param("repository")
 	
 	charts, err := h.repositoryManager.GetRepositoryCharts(repositoryName)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, http.StatusNotFound, "repository_not_found", err.Error())
 		return
 	}
 	
@@ -221,7 +980,7 @@ func (h *Handlers) GetRepositoryCharts(c *gin.Context) {
 func (h *Handlers) GetStorageClasses(c *gin.Context) {
 	storageClasses, err := h.repositoryManager.GetStorageClasses()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternalError(c, "storage_classes_failed", "Failed to list storage classes", err)
 		return
 	}
 	
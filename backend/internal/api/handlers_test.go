@@ -1,16 +1,26 @@
 package api
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"rancher-questions-generator/internal/models"
+	"rancher-questions-generator/internal/version"
+	"rancher-questions-generator/pkg/helm"
+	"rancher-questions-generator/pkg/questions"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func setupRouter() *gin.Engine {
@@ -33,6 +43,28 @@ func TestHealthCheck(t *testing.T) {
 	assert.Equal(t, "healthy", response["status"])
 }
 
+func TestVersion(t *testing.T) {
+	defer func(v, commit, date string) {
+		version.Version, version.Commit, version.BuildDate = v, commit, date
+	}(version.Version, version.Commit, version.BuildDate)
+	version.Version, version.Commit, version.BuildDate = "1.2.3", "abc1234", "2024-05-01T12:00:00Z"
+
+	router := setupRouter()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/version", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string]string{
+		"version":    "1.2.3",
+		"commit":     "abc1234",
+		"build_date": "2024-05-01T12:00:00Z",
+		"go_version": runtime.Version(),
+	}, response)
+}
+
 func TestProcessChart(t *testing.T) {
 	router := setupRouter()
 
@@ -82,6 +114,249 @@ func TestProcessChart(t *testing.T) {
 	}
 }
 
+func TestProcessChartIncludesReadme(t *testing.T) {
+	router := setupRouter()
+	server := newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":          "name: mychart\nversion: 1.0.0\nhome: https://example.com/mychart\nmaintainers:\n  - name: Jane Doe\n    email: jane@example.com\n",
+		"mychart/values.yaml":         "replicaCount: 1\n",
+		"mychart/README.md":           "# My Chart\n\nInstall me.\n",
+		"mychart/templates/NOTES.txt": "Thanks for installing mychart.\n",
+	})
+
+	jsonBody, _ := json.Marshal(models.ChartRequest{URL: server.URL + "/mychart-1.0.0.tgz"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ChartResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Contains(t, response.Readme, "# My Chart")
+	assert.Contains(t, response.Notes, "Thanks for installing mychart.")
+	if assert.NotNil(t, response.Chart) {
+		assert.Equal(t, "mychart", response.Chart.Name)
+		assert.Equal(t, "1.0.0", response.Chart.Version)
+		assert.Equal(t, "https://example.com/mychart", response.Chart.Home)
+		assert.Equal(t, []models.Maintainer{{Name: "Jane Doe", Email: "jane@example.com"}}, response.Chart.Maintainers)
+	}
+
+	// The stored session exposes the same documents
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+response.SessionID, nil)
+	router.ServeHTTP(w, req)
+
+	var stored models.ChartResponse
+	err = json.Unmarshal(w.Body.Bytes(), &stored)
+	assert.NoError(t, err)
+	assert.Equal(t, response.Readme, stored.Readme)
+	assert.Equal(t, response.Notes, stored.Notes)
+	assert.Equal(t, response.Chart, stored.Chart)
+}
+
+func TestProcessChartProfile(t *testing.T) {
+	router := setupRouter()
+	server := newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":  "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml": "replicaCount: 1\nextraArgs: --verbose\npodAnnotations: {}\nconfig:\n  cache:\n    ttl: 60\n",
+	})
+
+	counts := make(map[string]int)
+	for _, profile := range []string{"minimal", "", "exhaustive"} {
+		jsonBody, _ := json.Marshal(models.ChartRequest{URL: server.URL + "/mychart-1.0.0.tgz", Profile: profile})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "profile %q", profile)
+		var response models.ChartResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		counts[profile] = len(response.Questions.Questions)
+	}
+	assert.Less(t, counts["minimal"], counts[""])
+	assert.Less(t, counts[""], counts["exhaustive"])
+
+	jsonBody, _ := json.Marshal(models.ChartRequest{URL: server.URL + "/mychart-1.0.0.tgz", Profile: "verbose"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_parameter")
+	assert.Contains(t, w.Body.String(), "exhaustive")
+}
+
+func TestGetOriginalQuestions(t *testing.T) {
+	router := setupRouter()
+	server := newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":     "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml":    "replicaCount: 1\nservice:\n  type: ClusterIP\n",
+		"mychart/questions.yaml": "questions:\n- variable: service.type\n  label: Exposure\n  type: string\n",
+	})
+
+	jsonBody, _ := json.Marshal(models.ChartRequest{URL: server.URL + "/mychart-1.0.0.tgz"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var merged models.ChartResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &merged))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+merged.SessionID+"/original-questions", http.NoBody)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var original models.Questions
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &original))
+	if assert.Len(t, original.Questions, 1) {
+		assert.Equal(t, models.Question{Variable: "service.type", Label: "Exposure", Type: "string"}, original.Questions[0])
+	}
+	assert.Greater(t, len(merged.Questions.Questions), len(original.Questions))
+	assert.NotEqual(t, original.Questions, merged.Questions.Questions)
+
+	// A chart without questions.yaml has an empty original set
+	sessionID := createTestSession(t, router)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/original-questions", http.NoBody)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"questions": []}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/non-existent/original-questions", http.NoBody)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProcessChartQuestionsSource(t *testing.T) {
+	router := setupRouter()
+	chart := map[string]string{
+		"mychart/Chart.yaml":  "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml": "service:\n  type: ClusterIP\n",
+	}
+	withQuestions := func(questionsYAML string) map[string]string {
+		files := map[string]string{"mychart/questions.yaml": questionsYAML}
+		for name, content := range chart {
+			files[name] = content
+		}
+		return files
+	}
+
+	tests := []struct {
+		name   string
+		files  map[string]string
+		source string
+	}{
+		{"without questions.yaml", chart, "generated"},
+		{
+			name:   "partial questions.yaml",
+			files:  withQuestions("questions:\n- variable: service.type\n  label: Exposure\n"),
+			source: "merged",
+		},
+		{
+			name: "complete questions.yaml",
+			files: withQuestions("questions:\n- variable: name\n  label: Name\n- variable: namespace\n  label: Namespace\n" +
+				"- variable: service.type\n  label: Exposure\n"),
+			source: "chart",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newChartServer(t, tt.files)
+			jsonBody, _ := json.Marshal(models.ChartRequest{URL: server.URL + "/mychart-1.0.0.tgz"})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response models.ChartResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.source, response.QuestionsSource)
+
+			// The source is kept with the session
+			w = httptest.NewRecorder()
+			req, _ = http.NewRequest("GET", "/api/chart/"+response.SessionID, http.NoBody)
+			router.ServeHTTP(w, req)
+			assert.Contains(t, w.Body.String(), `"questions_source":"`+tt.source+`"`)
+		})
+	}
+}
+
+func TestProcessChartQuestionOrdering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ordering.yaml")
+	os.WriteFile(path, []byte("groups:\n  Networking: -10\n  General: 10\nvariables:\n  namespace: -1\n"), 0644)
+	t.Setenv("QUESTION_ORDERING_FILE", path)
+	router := setupRouter()
+
+	server := newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":  "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml": "replicaCount: 1\nservice:\n  type: ClusterIP\n",
+	})
+	jsonBody, _ := json.Marshal(models.ChartRequest{URL: server.URL + "/mychart-1.0.0.tgz"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ChartResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	var variables, groups []string
+	for _, q := range response.Questions.Questions {
+		variables = append(variables, q.Variable)
+		groups = append(groups, q.Group)
+	}
+
+	// Networking comes first and General last; namespace moves before name
+	if assert.NotEmpty(t, groups) {
+		assert.Equal(t, "Networking", groups[0], "questions: %v", variables)
+		assert.Equal(t, "General", groups[len(groups)-1], "questions: %v", variables)
+	}
+	namespace, name := -1, -1
+	for i, variable := range variables {
+		switch variable {
+		case "namespace":
+			namespace = i
+		case "name":
+			name = i
+		}
+	}
+	assert.True(t, namespace >= 0 && namespace < name, "questions: %v", variables)
+
+	// Questions from the chart's questions.yaml keep their place when
+	// generated questions are merged after them
+	server = newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":     "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml":    "replicaCount: 1\nservice:\n  type: ClusterIP\n",
+		"mychart/questions.yaml": "questions:\n- variable: replicaCount\n  group: General\n- variable: service.type\n  group: Networking\n",
+	})
+	jsonBody, _ = json.Marshal(models.ChartRequest{URL: server.URL + "/mychart-1.0.0.tgz"})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response = models.ChartResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, helm.QuestionsSourceMerged, response.QuestionsSource)
+	variables = nil
+	for _, q := range response.Questions.Questions {
+		variables = append(variables, q.Variable)
+	}
+	if assert.GreaterOrEqual(t, len(variables), 4) {
+		assert.Equal(t, []string{"replicaCount", "service.type", "namespace", "name"}, variables[:4])
+	}
+}
+
 func TestAddRepository(t *testing.T) {
 	router := setupRouter()
 
@@ -133,6 +408,26 @@ func TestAddRepository(t *testing.T) {
 			requestBody:    "invalid",
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "unsupported url scheme",
+			requestBody:    models.RepositoryRequest{Name: "ftp-repo", URL: "ftp://charts.example.com"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "name with slash",
+			requestBody:    models.RepositoryRequest{Name: "charts/stable", URL: "https://charts.example.com"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "name with spaces",
+			requestBody:    models.RepositoryRequest{Name: "my charts", URL: "https://charts.example.com"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "name too long",
+			requestBody:    models.RepositoryRequest{Name: strings.Repeat("a", 64), URL: "https://charts.example.com"},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,6 +473,199 @@ func TestListRepositories(t *testing.T) {
 	assert.Greater(t, len(repoList), 0)
 }
 
+func TestAddRepositoryConflict(t *testing.T) {
+	router := setupRouter()
+
+	add := func(query string) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(models.RepositoryRequest{Name: "bitnami", URL: "https://mirror.example.com/bitnami"})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/repositories"+query, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	bitnamiURL := func() string {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/repositories?q=bitnami", nil)
+		router.ServeHTTP(w, req)
+		var response struct {
+			Repositories []models.Repository `json:"repositories"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		for _, repo := range response.Repositories {
+			if repo.Name == "bitnami" {
+				return repo.URL
+			}
+		}
+		return ""
+	}
+
+	// The default bitnami repository is not silently replaced
+	w := add("")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "repository_exists")
+	assert.Equal(t, "https://charts.bitnami.com/bitnami", bitnamiURL())
+
+	w = add("?overwrite=true")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://mirror.example.com/bitnami", bitnamiURL())
+}
+
+func TestGetRepositoryStatus(t *testing.T) {
+	t.Setenv("DEFAULT_REPOSITORIES", `[
+		{"name": "good", "url": "https://charts.example.com"},
+		{"name": "broken", "url": "charts.example.com"}
+	]`)
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/repositories/status", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Repositories []helm.RepositoryStatus `json:"repositories"`
+		Failed       int                     `json:"failed"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Failed)
+	if assert.Len(t, response.Repositories, 2) {
+		assert.True(t, response.Repositories[0].Ready)
+		assert.False(t, response.Repositories[1].Ready)
+		assert.NotEmpty(t, response.Repositories[1].Error)
+	}
+}
+
+func TestListRepositoriesPagination(t *testing.T) {
+	router := setupRouter()
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedNames  []string
+	}{
+		{
+			name:           "first page",
+			query:          "?limit=2",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"bitnami", "ingress-nginx"},
+		},
+		{
+			name:           "second page",
+			query:          "?limit=2&offset=2",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"rancher-partner", "stable"},
+		},
+		{
+			name:           "offset past the end",
+			query:          "?offset=50",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{},
+		},
+		{
+			name:           "invalid limit",
+			query:          "?limit=-1",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/repositories"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Repositories []models.Repository `json:"repositories"`
+				Total        int                 `json:"total"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, 5, response.Total)
+
+			names := []string{}
+			for _, repo := range response.Repositories {
+				names = append(names, repo.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestListRepositoriesFiltering(t *testing.T) {
+	router := setupRouter()
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedNames  []string
+	}{
+		{
+			name:           "filter by type",
+			query:          "?type=oci",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"suse-application-collection"},
+		},
+		{
+			name:           "name substring",
+			query:          "?q=NGINX",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"ingress-nginx"},
+		},
+		{
+			name:           "type and substring sorted descending",
+			query:          "?type=http&q=a&sort=-name",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"stable", "rancher-partner", "bitnami"},
+		},
+		{
+			name:           "invalid type",
+			query:          "?type=ftp",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid sort",
+			query:          "?sort=size",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/repositories"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Repositories []models.Repository `json:"repositories"`
+				Total        int                 `json:"total"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.expectedNames), response.Total)
+
+			names := []string{}
+			for _, repo := range response.Repositories {
+				names = append(names, repo.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
 func TestSearchCharts(t *testing.T) {
 	router := setupRouter()
 
@@ -206,6 +694,12 @@ func TestSearchCharts(t *testing.T) {
 			path:           "/api/charts/search?repository=bitnami",
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "GET search deduplicated",
+			method:         "GET",
+			path:           "/api/charts/search?dedupe=true",
+			expectedStatus: http.StatusOK,
+		},
 		{
 			name:   "POST search",
 			method: "POST",
@@ -280,7 +774,7 @@ func TestProcessChartFromRepository(t *testing.T) {
 				Repository: "non-existent",
 				Chart:      "nginx",
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusNotFound,
 		},
 	}
 
@@ -304,52 +798,162 @@ func TestProcessChartFromRepository(t *testing.T) {
 	}
 }
 
-func TestGetStorageClasses(t *testing.T) {
+func TestProcessChartFromRepositoryErrors(t *testing.T) {
 	router := setupRouter()
 
+	addRepo, _ := json.Marshal(models.RepositoryRequest{Name: "private-oci", URL: "oci://registry.internal.example/charts"})
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/api/storage-classes", nil)
-	router.ServeHTTP(w, req)
-
-	assert.Equal(t, http.StatusOK, w.Code)
-	
-	var response map[string]interface{}
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
-	
-	storageClasses, exists := response["storage_classes"]
-	assert.True(t, exists)
-	assert.NotNil(t, storageClasses)
-	
-	// Should have at least one storage class
-	scList, ok := storageClasses.([]interface{})
-	assert.True(t, ok)
-	assert.Greater(t, len(scList), 0)
-}
-
-func TestRemoveRepository(t *testing.T) {
-	router := setupRouter()
-	
-	// First add a repository
-	addReq := models.RepositoryRequest{
-		Name: "test-remove",
-		URL:  "https://charts.example.com",
-	}
-	jsonBody, _ := json.Marshal(addReq)
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("POST", "/api/repositories", bytes.NewBuffer(jsonBody))
+	req, _ := http.NewRequest("POST", "/api/repositories", bytes.NewBuffer(addRepo))
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	tests := []struct {
 		name           string
-		repoName       string
+		path           string
+		requestBody    models.ChartProcessRequest
 		expectedStatus int
+		expectedCode   string
 	}{
 		{
-			name:           "remove existing repository",
-			repoName:       "test-remove",
+			name:           "unknown repository",
+			path:           "/api/charts/process",
+			requestBody:    models.ChartProcessRequest{Repository: "non-existent", Chart: "nginx"},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "repository_not_found",
+		},
+		{
+			name:           "unknown repository dry run",
+			path:           "/api/charts/process?dry_run=true",
+			requestBody:    models.ChartProcessRequest{Repository: "non-existent", Chart: "nginx"},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "repository_not_found",
+		},
+		{
+			name:           "pull failure",
+			path:           "/api/charts/process",
+			requestBody:    models.ChartProcessRequest{Repository: "private-oci", Chart: "app", Version: "1.0.0.sig"},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "chart_pull_failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBody, _ := json.Marshal(tt.requestBody)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var envelope errorEnvelope
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+			assert.Equal(t, tt.expectedCode, envelope.Error.Code)
+			assert.NotContains(t, w.Body.String(), "registry.internal.example")
+		})
+	}
+}
+
+func TestProcessChartFromRepositoryDryRun(t *testing.T) {
+	router := setupRouter()
+
+	tests := []struct {
+		name           string
+		requestBody    models.ChartProcessRequest
+		expectedStatus int
+		expectedURL    string
+	}{
+		{
+			name: "resolvable chart",
+			requestBody: models.ChartProcessRequest{
+				Repository: "bitnami",
+				Chart:      "nginx",
+				Version:    "15.4.4",
+			},
+			expectedStatus: http.StatusOK,
+			expectedURL:    "https://charts.bitnami.com/bitnami/nginx-15.4.4.tgz",
+		},
+		{
+			name: "unknown repository",
+			requestBody: models.ChartProcessRequest{
+				Repository: "non-existent",
+				Chart:      "nginx",
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBody, _ := json.Marshal(tt.requestBody)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/charts/process?dry_run=true", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response models.ChartResolveResponse
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedURL, response.ChartURL)
+				assert.Equal(t, tt.requestBody.Repository, response.Repository)
+				assert.Equal(t, tt.requestBody.Chart, response.Chart)
+				assert.Equal(t, tt.requestBody.Version, response.Version)
+				assert.NotContains(t, w.Body.String(), "session_id")
+			}
+		})
+	}
+}
+
+func TestGetStorageClasses(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/storage-classes", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	
+	storageClasses, exists := response["storage_classes"]
+	assert.True(t, exists)
+	assert.NotNil(t, storageClasses)
+	
+	// Should have at least one storage class
+	scList, ok := storageClasses.([]interface{})
+	assert.True(t, ok)
+	assert.Greater(t, len(scList), 0)
+}
+
+func TestRemoveRepository(t *testing.T) {
+	router := setupRouter()
+	
+	// First add a repository
+	addReq := models.RepositoryRequest{
+		Name: "test-remove",
+		URL:  "https://charts.example.com",
+	}
+	jsonBody, _ := json.Marshal(addReq)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/repositories", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	tests := []struct {
+		name           string
+		repoName       string
+		expectedStatus int
+	}{
+		{
+			name:           "remove existing repository",
+			repoName:       "test-remove",
 			expectedStatus: http.StatusOK,
 		},
 		{
@@ -429,7 +1033,7 @@ func TestCORSHeaders(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, w.Code)
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
 	assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
-	assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "Content-Type, Authorization, X-API-Key, X-Request-ID", w.Header().Get("Access-Control-Allow-Headers"))
 }
 
 func TestSessionManagement(t *testing.T) {
@@ -499,61 +1103,1242 @@ func TestSessionManagement(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
-// Integration test for full workflow
-func TestFullWorkflow(t *testing.T) {
+func TestClearSessions(t *testing.T) {
 	router := setupRouter()
+	first := createTestSession(t, router)
+	second := createTestSession(t, router)
 
-	// 1. Add a custom repository
-	addRepoReq := models.RepositoryRequest{
-		Name: "workflow-test",
-		URL:  "https://charts.workflow.com",
-	}
-	jsonBody, _ := json.Marshal(addRepoReq)
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("POST", "/api/repositories", bytes.NewBuffer(jsonBody))
-	req.Header.Set("Content-Type", "application/json")
+	req, _ := http.NewRequest("DELETE", "/api/chart", nil)
 	router.ServeHTTP(w, req)
+
 	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Removed int `json:"removed"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Removed)
 
-	// 2. List repositories (should include our new one)
+	for _, sessionID := range []string{first, second} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/chart/"+sessionID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+
+	// With API keys configured the reset requires one
+	t.Setenv("API_KEYS", "admin-key")
+	router = setupRouter()
 	w = httptest.NewRecorder()
-	req, _ = http.NewRequest("GET", "/api/repositories", nil)
+	req, _ = http.NewRequest("DELETE", "/api/chart", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestUpdateChartRejectsShowIfCycle(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	cyclic := models.Questions{
+		Questions: []models.Question{
+			{Variable: "a", Label: "A", Type: "boolean", ShowIf: "b=true"},
+			{Variable: "b", Label: "B", Type: "boolean", ShowIf: "a=true"},
+		},
+	}
+	jsonBody, _ := json.Marshal(cyclic)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "cycle")
+}
+
+func TestUpdateChartRejectsUnknownType(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	jsonBody, _ := json.Marshal(models.Questions{
+		Questions: []models.Question{{Variable: "debug", Label: "Debug", Type: "boolan"}},
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `unknown type \"boolan\"`)
+}
+
+func TestValidateQuestionsEndpoint(t *testing.T) {
+	router := setupRouter()
+
+	validate := func(set models.Questions) (int, map[string]interface{}) {
+		jsonBody, _ := json.Marshal(set)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/questions/validate", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	// Duplicate labels in a group are warnings, not errors
+	code, response := validate(models.Questions{Questions: []models.Question{
+		{Variable: "web.port", Label: "Port", Type: "int", Group: "Networking"},
+		{Variable: "admin.port", Label: "Port", Type: "int", Group: "Networking"},
+		{Variable: "debug", Label: "Debug", Type: "boolan"},
+	}})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, response["valid"])
+	assert.Len(t, response["errors"], 1)
+	assert.Contains(t, response["errors"], `question debug has unknown type "boolan"`)
+	assert.Equal(t, []interface{}{`label "Port" is used by 2 questions in group Networking: web.port, admin.port`}, response["warnings"])
+
+	code, response = validate(models.Questions{Questions: []models.Question{
+		{Variable: "web.port", Label: "Port", Type: "int", Group: "Web"},
+		{Variable: "admin.port", Label: "Port", Type: "int", Group: "Admin"},
+	}})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, response["valid"])
+	assert.Empty(t, response["errors"])
+	assert.Empty(t, response["warnings"])
+
+	// Updates with only warnings are saved and report them
+	sessionID := createTestSession(t, router)
+	jsonBody, _ := json.Marshal(models.Questions{Questions: []models.Question{
+		{Variable: "web.port", Label: "Port", Type: "int", Group: "Networking"},
+		{Variable: "admin.port", Label: "Port", Type: "int", Group: "Networking"},
+	}})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"warnings":["label \"Port\" is used by 2 questions in group Networking`)
+}
 
-	// 3. Search for charts
-	w = httptest.NewRecorder()
-	req, _ = http.NewRequest("GET", "/api/charts/search?query=nginx", nil)
+func TestApplyTemplate(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		template       string
+		expectedStatus int
+	}{
+		{
+			name:           "security template",
+			sessionID:      sessionID,
+			template:       "security",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown template",
+			sessionID:      sessionID,
+			template:       "does-not-exist",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown session",
+			sessionID:      "non-existent",
+			template:       "security",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBody, _ := json.Marshal(models.ApplyTemplateRequest{Template: tt.template})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/chart/"+tt.sessionID+"/apply-template", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// The session now carries the template questions and their show_if chain
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/chart/"+sessionID, nil)
+	router.ServeHTTP(w, req)
+
+	var response models.ChartResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	showIf := make(map[string]string)
+	for _, q := range response.Questions.Questions {
+		showIf[q.Variable] = q.ShowIf
+	}
+	assert.Contains(t, showIf, "name")
+	assert.Contains(t, showIf, "security.enabled")
+	assert.Equal(t, "security.enabled=true", showIf["security.neuvector.enabled"])
+	assert.Equal(t, "security.neuvector.enabled=true", showIf["security.neuvector.password"])
+}
+
+func TestGetFlatValues(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/chart/"+sessionID+"/values/flat", nil)
 	router.ServeHTTP(w, req)
+
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	// 4. Process a chart from repository
-	processReq := models.ChartProcessRequest{
-		Repository: "bitnami",
-		Chart:      "nginx",
-		Version:    "15.4.4",
+	var response struct {
+		Values map[string]interface{} `json:"values"`
 	}
-	jsonBody, _ = json.Marshal(processReq)
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"service.type": "ClusterIP"}, response.Values)
+
 	w = httptest.NewRecorder()
-	req, _ = http.NewRequest("POST", "/api/charts/process", bytes.NewBuffer(jsonBody))
+	req, _ = http.NewRequest("GET", "/api/chart/non-existent/values/flat", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestApplyFlatValues(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"service.type":    "NodePort",
+		"service.port":    "8080",
+		"ingress.enabled": "true",
+		"ingress.hosts":   []string{"example.com"},
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart/"+sessionID+"/values/apply", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
+
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var processResponse models.ChartResponse
-	err := json.Unmarshal(w.Body.Bytes(), &processResponse)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID, nil)
+	router.ServeHTTP(w, req)
+
+	var response models.ChartResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	sessionID := processResponse.SessionID
+	assert.Equal(t, map[string]interface{}{
+		"service": map[string]interface{}{"type": "NodePort", "port": float64(8080)},
+		"ingress": map[string]interface{}{"enabled": true, "hosts": []interface{}{"example.com"}},
+	}, response.Values)
 
-	// 5. Download YAML
 	w = httptest.NewRecorder()
-	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/q", nil)
+	req, _ = http.NewRequest("POST", "/api/chart/non-existent/values/apply", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestApplyFlatValuesValidatesSchema(t *testing.T) {
+	router := setupRouter()
+	server := newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":         "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml":        "replicaCount: 1\n",
+		"mychart/values.schema.json": `{"type": "object", "properties": {"workers": {"type": "integer"}}}`,
+	})
+
+	jsonBody, _ := json.Marshal(models.ChartRequest{URL: server.URL + "/mychart-1.0.0.tgz"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	// 6. Remove the repository
+	var chart models.ChartResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &chart))
+
+	apply := func(values map[string]interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(values)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/chart/"+chart.SessionID+"/values/apply", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = apply(map[string]interface{}{"workers": "many"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var envelope struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details struct {
+				Errors []struct {
+					Path    string `json:"path"`
+					Message string `json:"message"`
+				} `json:"errors"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	assert.Equal(t, "schema_validation_failed", envelope.Error.Code)
+	if assert.Len(t, envelope.Error.Details.Errors, 1) {
+		assert.Equal(t, "workers", envelope.Error.Details.Errors[0].Path)
+		assert.Contains(t, envelope.Error.Details.Errors[0].Message, "integer")
+	}
+
+	w = apply(map[string]interface{}{"workers": 4})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Charts without a schema accept any values
+	sessionID := createTestSession(t, router)
+	jsonBody, _ = json.Marshal(map[string]interface{}{"workers": "many"})
 	w = httptest.NewRecorder()
-	req, _ = http.NewRequest("DELETE", "/api/repositories/workflow-test", nil)
+	req, _ = http.NewRequest("POST", "/api/chart/"+sessionID+"/values/apply", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
-}
\ No newline at end of file
+}
+
+func TestProcessOCIChartWithoutHelm(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	router := setupRouter()
+
+	jsonBody, _ := json.Marshal(models.ChartRequest{URL: "oci://registry.example.com/charts/ollama:1.0.0"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ChartResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.NotNil(t, response.HelmAvailable) {
+		assert.False(t, *response.HelmAvailable)
+	}
+	assert.Contains(t, response.Warning, "install helm")
+}
+
+func TestProcessChartTypeOverrides(t *testing.T) {
+	router := setupRouter()
+	server := newChartServer(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "auth:\n  token: changeme\n",
+	})
+
+	jsonBody, _ := json.Marshal(models.ChartRequest{
+		URL:           server.URL + "/testchart-0.1.0.tgz",
+		TypeOverrides: map[string]string{"auth.token": "password"},
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ChartResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	var found bool
+	for _, q := range response.Questions.Questions {
+		if q.Variable == "auth.token" {
+			found = true
+			assert.Equal(t, "password", q.Type)
+		}
+	}
+	assert.True(t, found, "auth.token question should be generated")
+
+	jsonBody, _ = json.Marshal(models.ChartRequest{
+		URL:           server.URL + "/testchart-0.1.0.tgz",
+		TypeOverrides: map[string]string{"auth.token": "passwrd"},
+	})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "passwrd")
+}
+
+func TestGetQuestionsYAMLInline(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	tests := []struct {
+		query       string
+		disposition string
+	}{
+		{"", "attachment; filename=questions.yaml"},
+		{"?inline=true", "inline; filename=questions.yaml"},
+		{"?inline=false", "attachment; filename=questions.yaml"},
+		{"?format=multidoc&inline=true", "inline; filename=questions.yaml"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/chart/"+sessionID+"/q"+tt.query, http.NoBody)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.query)
+		assert.Equal(t, "application/x-yaml", w.Header().Get("Content-Type"), tt.query)
+		assert.Equal(t, tt.disposition, w.Header().Get("Content-Disposition"), tt.query)
+	}
+}
+
+func TestGetQuestionsYAMLMultidoc(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	updated := models.Questions{Questions: []models.Question{
+		{Variable: "image.tag", Label: "Tag", Type: "string", Group: "Image"},
+		{Variable: "service.type", Label: "Type", Type: "string", Group: "Service"},
+		{Variable: "image.pullPolicy", Label: "Pull Policy", Type: "string", Group: "Image"},
+		{Variable: "ingress.enabled", Label: "Enabled", Type: "boolean", Group: "Ingress"},
+	}}
+	jsonBody, _ := json.Marshal(updated)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/q?format=multidoc", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Equal(t, 2, strings.Count(body, "---\n"))
+
+	docs := strings.Split(body, "---\n")
+	assert.Len(t, docs, 3)
+	expectedGroups := []string{"Image", "Service", "Ingress"}
+	expectedCounts := []int{2, 1, 1}
+	for i, doc := range docs {
+		var parsed models.Questions
+		if assert.NoError(t, yaml.Unmarshal([]byte(doc), &parsed)) {
+			assert.Len(t, parsed.Questions, expectedCounts[i])
+			for _, q := range parsed.Questions {
+				assert.Equal(t, expectedGroups[i], q.Group)
+			}
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/q", nil)
+	router.ServeHTTP(w, req)
+	assert.NotContains(t, w.Body.String(), "---")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/q?format=bogus", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetQuestionsJSON(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	tests := []struct {
+		name   string
+		query  string
+		accept string
+	}{
+		{"format query", "?format=json", ""},
+		{"accept header", "", "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/chart/"+sessionID+"/q"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+			var parsed models.Questions
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &parsed))
+			assert.NotEmpty(t, parsed.Questions)
+		})
+	}
+
+	// YAML stays the default, and an explicit format beats the Accept header
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/chart/"+sessionID+"/q?format=yaml", nil)
+	req.Header.Set("Accept", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "application/x-yaml", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/q", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "application/x-yaml", w.Header().Get("Content-Type"))
+}
+
+func TestProcessChartSource(t *testing.T) {
+	router := setupRouter()
+	server := newChartServer(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "replicaCount: 1\n",
+	})
+
+	addRepo, _ := json.Marshal(models.RepositoryRequest{Name: "local", URL: server.URL})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/repositories", bytes.NewBuffer(addRepo))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	tests := []struct {
+		name           string
+		body           models.ChartSourceRequest
+		expectedStatus int
+	}{
+		{"direct url", models.ChartSourceRequest{URL: server.URL + "/testchart-0.1.0.tgz"}, http.StatusOK},
+		{"repository and chart", models.ChartSourceRequest{Repository: "local", Chart: "testchart", Version: "0.1.0"}, http.StatusOK},
+		{"both forms", models.ChartSourceRequest{URL: server.URL + "/testchart-0.1.0.tgz", Repository: "local", Chart: "testchart"}, http.StatusBadRequest},
+		{"neither form", models.ChartSourceRequest{}, http.StatusBadRequest},
+		{"repository without chart", models.ChartSourceRequest{Repository: "local"}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBody, _ := json.Marshal(tt.body)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/charts/process-url", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.ChartResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.NotEmpty(t, response.SessionID)
+				assert.Equal(t, "testchart", response.Chart.Name)
+			}
+		})
+	}
+}
+
+func TestDiffChartVersions(t *testing.T) {
+	router := setupRouter()
+	archives := map[string][]byte{
+		"/testchart-1.0.0.tgz": buildChartArchive(t, map[string]string{
+			"testchart/Chart.yaml":  "name: testchart\nversion: 1.0.0\n",
+			"testchart/values.yaml": "replicaCount: 1\nimage:\n  tag: \"1.0\"\nlegacy: true\n",
+		}),
+		"/testchart-2.0.0.tgz": buildChartArchive(t, map[string]string{
+			"testchart/Chart.yaml":  "name: testchart\nversion: 2.0.0\n",
+			"testchart/values.yaml": "replicaCount: 1\nimage:\n  tag: \"2.0\"\nmetrics:\n  enabled: false\n",
+		}),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		archive, ok := archives[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	addRepo, _ := json.Marshal(models.RepositoryRequest{Name: "local", URL: server.URL})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/repositories", bytes.NewBuffer(addRepo))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/repositories/local/charts/testchart/diff?from=1.0.0&to=2.0.0", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Values    helm.ValuesDiff `json:"values"`
+		Questions struct {
+			Added   []string `json:"added"`
+			Removed []string `json:"removed"`
+		} `json:"questions"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string]interface{}{"metrics.enabled": false}, response.Values.Added)
+	assert.Equal(t, map[string]interface{}{"legacy": true}, response.Values.Removed)
+	assert.Equal(t, map[string]helm.ValueChange{"image.tag": {From: "1.0", To: "2.0"}}, response.Values.Changed)
+	assert.Equal(t, []string{"metrics.enabled"}, response.Questions.Added)
+	assert.Equal(t, []string{"legacy"}, response.Questions.Removed)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"missing version", "/api/repositories/local/charts/testchart/diff?from=1.0.0", http.StatusBadRequest},
+		{"unknown repository", "/api/repositories/missing/charts/testchart/diff?from=1.0.0&to=2.0.0", http.StatusNotFound},
+		{"unavailable version", "/api/repositories/local/charts/testchart/diff?from=1.0.0&to=9.9.9", http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// Fetch failures are summarised rather than passed through
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/repositories/local/charts/testchart/diff?from=1.0.0&to=9.9.9", nil)
+	router.ServeHTTP(w, req)
+	assert.Contains(t, w.Body.String(), "Could not fetch testchart version 9.9.9: chart or repository not found")
+}
+
+func TestEvaluateVisibility(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	edited := models.Questions{Questions: []models.Question{
+		{Variable: "ingress.enabled", Label: "Ingress", Type: "boolean", Default: false, ShowSubquestionIf: "true",
+			SubQuestions: []models.Question{
+				{Variable: "ingress.host", Label: "Host", Type: "hostname"},
+			}},
+		{Variable: "service.type", Label: "Service Type", Type: "enum", Options: []string{"ClusterIP", "NodePort"}, Default: "ClusterIP"},
+		{Variable: "service.nodePort", Label: "Node Port", Type: "int", ShowIf: "service.type=NodePort&&ingress.enabled!=true"},
+	}}
+	jsonBody, _ := json.Marshal(edited)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	tests := []struct {
+		answers string
+		visible []string
+	}{
+		{`{}`, []string{"ingress.enabled", "service.type"}},
+		{`{"ingress.enabled": true}`, []string{"ingress.enabled", "ingress.host", "service.type"}},
+		{`{"service.type": "NodePort"}`, []string{"ingress.enabled", "service.type", "service.nodePort"}},
+		{`{"service.type": "NodePort", "ingress.enabled": true}`, []string{"ingress.enabled", "ingress.host", "service.type"}},
+	}
+	for _, tt := range tests {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/chart/"+sessionID+"/evaluate", strings.NewReader(tt.answers))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Visible []string `json:"visible"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, tt.visible, response.Visible, "answers %s", tt.answers)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/chart/missing/evaluate", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/chart/"+sessionID+"/evaluate", strings.NewReader(`["not", "a", "map"]`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetGroups(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	edited := models.Questions{Questions: []models.Question{
+		{Variable: "name", Label: "Name", Group: "General"},
+		{Variable: "service.type", Label: "Service Type", Group: "Networking"},
+		{Variable: "namespace", Label: "Namespace", Group: "General"},
+		{Variable: "ingress.host", Label: "Host", Group: "Networking"},
+		{Variable: "persistence.size", Label: "Size", Group: "Storage"},
+		{Variable: "replicaCount", Label: "Replicas", Group: "General"},
+	}}
+	jsonBody, _ := json.Marshal(edited)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/groups", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Groups []questions.GroupCount `json:"groups"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []questions.GroupCount{
+		{Name: "General", Count: 3},
+		{Name: "Networking", Count: 2},
+		{Name: "Storage", Count: 1},
+	}, response.Groups)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/missing/groups", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSetGroupWeights(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	body := `{"groups": [{"name": "Networking", "weight": -10}, {"name": "General", "weight": 10}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID+"/groups", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/q", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var parsed models.Questions
+	assert.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &parsed))
+	assert.Equal(t, []models.GroupMeta{{Name: "Networking", Weight: -10}, {Name: "General", Weight: 10}}, parsed.Groups)
+	if assert.NotEmpty(t, parsed.Questions) {
+		assert.Equal(t, "Networking", parsed.Questions[0].Group)
+		assert.Equal(t, "General", parsed.Questions[len(parsed.Questions)-1].Group)
+	}
+
+	// Weights survive an edit that doesn't mention them
+	edited := models.Questions{Questions: []models.Question{
+		{Variable: "name", Label: "Name", Group: "General"},
+		{Variable: "service.type", Label: "Service Type", Group: "Networking"},
+	}}
+	jsonBody, _ := json.Marshal(edited)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/chart/"+sessionID, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/q?format=multidoc", nil)
+	router.ServeHTTP(w, req)
+	docs := strings.Split(w.Body.String(), "---\n")
+	if assert.Len(t, docs, 2) {
+		assert.Contains(t, docs[0], "service.type")
+		assert.Contains(t, docs[1], "variable: name")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/chart/"+sessionID+"/groups", strings.NewReader(`{"groups": [{"weight": 1}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestApplyTemplateKeepsGroupWeights(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	body := `{"groups": [{"name": "Security Settings", "weight": -10}, {"name": "General", "weight": 10}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID+"/groups", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/chart/"+sessionID+"/apply-template", strings.NewReader(`{"template": "security"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stored models.Session
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+	assert.Equal(t, []models.GroupMeta{{Name: "Security Settings", Weight: -10}, {Name: "General", Weight: 10}}, stored.Questions.Groups)
+	if assert.NotEmpty(t, stored.Questions.Questions) {
+		// The template's questions are ordered by the kept weights
+		assert.Equal(t, "Security Settings", stored.Questions.Questions[0].Group)
+		assert.Equal(t, "General", stored.Questions.Questions[len(stored.Questions.Questions)-1].Group)
+	}
+}
+
+func TestQuestionDefaultsRoundTrip(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	body := `{"questions": [
+		{"variable": "replicas", "label": "Replicas", "type": "int", "default": 3},
+		{"variable": "port", "label": "Port", "type": "int", "default": 80},
+		{"variable": "big", "label": "Big", "type": "int", "default": 9007199254740993},
+		{"variable": "ratio", "label": "Ratio", "type": "float", "default": 0.75},
+		{"variable": "enabled", "label": "Enabled", "type": "boolean", "default": true},
+		{"variable": "tag", "label": "Tag", "type": "string", "default": "80"}
+	]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/q", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "default: 9007199254740993")
+	assert.Contains(t, w.Body.String(), `default: "80"`)
+
+	var parsed models.Questions
+	assert.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &parsed))
+	expected := []interface{}{3, 80, 9007199254740993, 0.75, true, "80"}
+	if assert.Len(t, parsed.Questions, len(expected)) {
+		for i, want := range expected {
+			assert.Equal(t, want, parsed.Questions[i].Default, parsed.Questions[i].Variable)
+		}
+	}
+}
+
+// fakeHelmTemplate is a stand-in for `helm template RELEASE CHART -f VALUES`
+// that prints the chart's templates with .Values.replicaCount substituted,
+// and fails like helm does when replicaCount is negative.
+const fakeHelmTemplate = `#!/bin/sh
+replicas=$(sed -n 's/^replicaCount: *//p' "$5")
+case "$replicas" in
+-*) echo "Error: execution error at ($3/templates/deployment.yaml:3:14): replicaCount must be positive" >&2; exit 1 ;;
+0) echo "Error: open /var/lib/helm/secret-registry.example.com/config.json: no such host" >&2; exit 1 ;;
+esac
+echo "WARNING: Kubernetes configuration file is group-readable" >&2
+for f in "$3"/templates/*.yaml; do
+  echo "---"
+  sed "s/{{ .Values.replicaCount }}/$replicas/" "$f"
+done
+`
+
+func TestRenderChart(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "helm"), []byte(fakeHelmTemplate), 0755); err != nil {
+		t.Fatalf("Failed to write fake helm: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	router := setupRouter()
+	server := newChartServer(t, map[string]string{
+		"webapp/Chart.yaml":                "name: webapp\nversion: 0.1.0\n",
+		"webapp/values.yaml":               "replicaCount: 2\n",
+		"webapp/templates/deployment.yaml": "kind: Deployment\nspec:\n  replicas: {{ .Values.replicaCount }}\n",
+		"webapp/templates/service.yaml":    "kind: Service\n",
+	})
+	jsonBody, _ := json.Marshal(models.ChartRequest{URL: server.URL + "/webapp-0.1.0.tgz"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var chart models.ChartResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &chart))
+
+	render := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/chart/"+chart.SessionID+"/render", http.NoBody)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = render()
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Manifests string `json:"manifests"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Manifests, "kind: Deployment")
+	assert.Contains(t, response.Manifests, "kind: Service")
+	assert.Contains(t, response.Manifests, "replicas: 2")
+	assert.NotContains(t, response.Manifests, "WARNING")
+
+	// Edited values are rendered, and helm errors are reported
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/chart/"+chart.SessionID+"/values/apply", strings.NewReader(`{"replicaCount": "-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = render()
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "replicaCount must be positive")
+	assert.NotContains(t, w.Body.String(), "deployment.yaml")
+
+	// Other helm output is summarised rather than passed through
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/chart/"+chart.SessionID+"/values/apply", strings.NewReader(`{"replicaCount": "0"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = render()
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "could not reach the chart repository or registry")
+	assert.NotContains(t, w.Body.String(), "secret-registry")
+
+	t.Setenv("PATH", t.TempDir())
+	w = render()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "helm_unavailable")
+}
+
+func TestGetValuesSchema(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/chart/"+sessionID+"/values.schema.json", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var schema struct {
+		Type       string   `json:"type"`
+		Required   []string `json:"required"`
+		Properties map[string]struct {
+			Type       string `json:"type"`
+			Properties map[string]struct {
+				Type string   `json:"type"`
+				Enum []string `json:"enum"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &schema))
+	assert.Equal(t, "object", schema.Type)
+	// name and namespace are asked by Rancher, not chart values
+	assert.NotContains(t, schema.Required, "name")
+	assert.NotContains(t, schema.Properties, "namespace")
+	assert.Equal(t, "object", schema.Properties["service"].Type)
+	assert.Equal(t, "string", schema.Properties["service"].Properties["type"].Type)
+	assert.Equal(t, []string{"ClusterIP", "NodePort", "LoadBalancer"}, schema.Properties["service"].Properties["type"].Enum)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/missing/values.schema.json", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// Integration test for full workflow
+func TestFullWorkflow(t *testing.T) {
+	router := setupRouter()
+
+	// 1. Add a custom repository
+	addRepoReq := models.RepositoryRequest{
+		Name: "workflow-test",
+		URL:  "https://charts.workflow.com",
+	}
+	jsonBody, _ := json.Marshal(addRepoReq)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/repositories", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 2. List repositories (should include our new one)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/repositories", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 3. Search for charts
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/charts/search?query=nginx", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 4. Process a chart from repository
+	processReq := models.ChartProcessRequest{
+		Repository: "bitnami",
+		Chart:      "nginx",
+		Version:    "15.4.4",
+	}
+	jsonBody, _ = json.Marshal(processReq)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/charts/process", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var processResponse models.ChartResponse
+	err := json.Unmarshal(w.Body.Bytes(), &processResponse)
+	assert.NoError(t, err)
+	sessionID := processResponse.SessionID
+
+	// 5. Download YAML
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID+"/q", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 6. Remove the repository
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/repositories/workflow-test", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// newChartServer serves a gzipped chart archive built from files on every path.
+func newChartServer(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	archive := buildChartArchive(t, files)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// buildChartArchive packs files into an in-memory .tgz chart archive.
+func buildChartArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write tar content: %v", err)
+		}
+	}
+	tw.Close()
+	gzw.Close()
+	return buf.Bytes()
+}
+
+// createTestSession processes a minimal local chart and returns its session ID.
+func createTestSession(t *testing.T, router *gin.Engine) string {
+	t.Helper()
+	server := newChartServer(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "service:\n  type: ClusterIP\n",
+	})
+
+	jsonBody, _ := json.Marshal(models.ChartRequest{URL: server.URL + "/testchart-0.1.0.tgz"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/chart", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to create test session: %d %s", w.Code, w.Body.String())
+	}
+
+	var response models.ChartResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode chart response: %v", err)
+	}
+	return response.SessionID
+}
+
+func TestSearchChartsDedupe(t *testing.T) {
+	router := setupRouter()
+
+	search := func(path string) []models.Chart {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Charts []models.Chart `json:"charts"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Charts
+	}
+
+	full := search("/api/charts/search")
+	deduped := search("/api/charts/search?dedupe=true")
+
+	names := make(map[string]bool)
+	for _, chart := range deduped {
+		assert.False(t, names[chart.Name], "chart %s listed twice", chart.Name)
+		names[chart.Name] = true
+		assert.Contains(t, chart.Repositories, chart.Repository)
+	}
+	assert.Len(t, names, len(deduped))
+	assert.LessOrEqual(t, len(deduped), len(full))
+	for _, chart := range full {
+		assert.Empty(t, chart.Repositories)
+	}
+}
+
+func TestBulkSetType(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "resources.limits.cpu", Label: "CPU Limit", Type: "int"},
+		{Variable: "resources.requests.memory", Label: "Memory Request", Type: "int"},
+		{Variable: "replicaCount", Label: "Replicas", Type: "int"},
+	}}
+	jsonBody, _ := json.Marshal(set)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	bulkType := func(id string, body models.BulkTypeRequest) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/chart/"+id+"/questions/bulk-type", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = bulkType(sessionID, models.BulkTypeRequest{Pattern: "resources.*", Type: "string"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Changed   []string         `json:"changed"`
+		Questions models.Questions `json:"questions"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"resources.limits.cpu", "resources.requests.memory"}, response.Changed)
+
+	// Only the matching questions changed in the stored session
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/"+sessionID, nil)
+	router.ServeHTTP(w, req)
+	var stored models.ChartResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+	types := make(map[string]string)
+	for _, q := range stored.Questions.Questions {
+		types[q.Variable] = q.Type
+	}
+	assert.Equal(t, map[string]string{
+		"resources.limits.cpu":      "string",
+		"resources.requests.memory": "string",
+		"replicaCount":              "int",
+	}, types)
+
+	w = bulkType(sessionID, models.BulkTypeRequest{Pattern: "resources.*", Type: "bogus"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "validation_failed")
+
+	w = bulkType(sessionID, models.BulkTypeRequest{Pattern: "[", Type: "string"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = bulkType("non-existent", models.BulkTypeRequest{Pattern: "*", Type: "string"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUndoChart(t *testing.T) {
+	router := setupRouter()
+	sessionID := createTestSession(t, router)
+
+	undo := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/chart/"+id+"/undo", http.NoBody)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	variables := func(set models.Questions) []string {
+		var names []string
+		for _, q := range set.Questions {
+			names = append(names, q.Variable)
+		}
+		return names
+	}
+
+	// Nothing to undo until the questions are edited
+	w := undo(sessionID)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "nothing_to_undo")
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/chart/"+sessionID, nil)
+	router.ServeHTTP(w, req)
+	var original models.ChartResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &original))
+
+	edits := [][]string{{"first"}, {"first", "second"}}
+	for _, edit := range edits {
+		set := models.Questions{Questions: []models.Question{}}
+		for _, variable := range edit {
+			set.Questions = append(set.Questions, models.Question{Variable: variable, Label: variable, Type: "string"})
+		}
+		jsonBody, _ := json.Marshal(set)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/chart/"+sessionID, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	for _, want := range [][]string{{"first"}, variables(original.Questions)} {
+		w = undo(sessionID)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var restored models.Questions
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+		assert.Equal(t, want, variables(restored))
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/chart/"+sessionID, nil)
+		router.ServeHTTP(w, req)
+		var stored models.ChartResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+		assert.Equal(t, want, variables(stored.Questions))
+	}
+
+	w = undo(sessionID)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = undo("non-existent")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProcessChartReuse(t *testing.T) {
+	router := setupRouter()
+	server := newChartServer(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "replicaCount: 1\n",
+	})
+	chartURL := server.URL + "/testchart-0.1.0.tgz"
+
+	process := func(path, url string) string {
+		jsonBody, _ := json.Marshal(models.ChartRequest{URL: url})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.ChartResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.NotEmpty(t, response.Questions.Questions)
+		return response.SessionID
+	}
+
+	first := process("/api/chart", chartURL)
+	assert.Equal(t, first, process("/api/chart?reuse=true", chartURL))
+	assert.Equal(t, first, process("/api/chart?reuse=true", " "+chartURL+"/ "))
+
+	// Without reuse every request gets its own session
+	second := process("/api/chart", chartURL)
+	assert.NotEqual(t, first, second)
+
+	// Reuse picks the newest session for the chart
+	assert.Equal(t, second, process("/api/chart?reuse=true", chartURL))
+}
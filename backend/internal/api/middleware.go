@@ -0,0 +1,301 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestIDKey is the gin context key holding the current request's ID.
+const requestIDKey = "request_id"
+
+// requestIDHeader carries the correlation ID in both directions.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs.
+const maxRequestIDLength = 128
+
+// requestID assigns every request a correlation ID: the caller's
+// X-Request-ID when it is well formed, otherwise a new UUID. The ID is echoed
+// in the response header, attached to error responses and logged with the
+// request outcome.
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+
+		start := time.Now()
+		c.Next()
+
+		requestLogger(c).Info("request completed",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"duration", time.Since(start),
+		)
+	}
+}
+
+// validRequestID accepts IDs made of letters, digits, '-', '_' and '.', so
+// client input can't inject content into logs or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDFrom returns the ID assigned by requestID, or "" outside of it.
+func requestIDFrom(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// requestLogger returns a logger that tags entries with the request ID.
+func requestLogger(c *gin.Context) *slog.Logger {
+	return slog.Default().With("request_id", requestIDFrom(c))
+}
+
+// parseAPIKeys splits the comma-separated API_KEYS value, ignoring blanks.
+func parseAPIKeys(raw string) []string {
+	var keys []string
+	for _, key := range strings.Split(raw, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// publicPaths are served without an API key.
+var publicPaths = map[string]bool{
+	"/api/health":       true,
+	"/api/openapi.json": true,
+}
+
+// apiKeyAuth requires a configured API key in the X-API-Key header or as a
+// Bearer token. With no keys configured the API stays open (dev mode). The
+// health check and the OpenAPI spec are always reachable so probes and client
+// generators keep working.
+func apiKeyAuth(keys []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(keys) == 0 || publicPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		provided := c.GetHeader("X-API-Key")
+		if provided == "" {
+			if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+				provided = strings.TrimPrefix(auth, "Bearer ")
+			}
+		}
+
+		if !validAPIKey(keys, provided) {
+			respondError(c, http.StatusUnauthorized, "unauthorized", "Invalid or missing API key")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// validAPIKey compares provided against every key in constant time.
+func validAPIKey(keys []string, provided string) bool {
+	if provided == "" {
+		return false
+	}
+
+	match := 0
+	for _, key := range keys {
+		match |= subtle.ConstantTimeCompare([]byte(key), []byte(provided))
+	}
+	return match == 1
+}
+
+func apiKeysFromEnv() []string {
+	return parseAPIKeys(os.Getenv("API_KEYS"))
+}
+
+// requireJSON rejects POST, PUT and PATCH requests whose body is not
+// declared as application/json with 415 Unsupported Media Type. Requests
+// without a body pass, as do routes listed in exempt (by gin route path),
+// which accept other formats such as YAML imports.
+func requireJSON(exempt ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+		if skip[c.FullPath()] || c.Request.ContentLength == 0 {
+			c.Next()
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			respondError(c, http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/json")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// defaultMaxBodyBytes caps request bodies unless MAX_BODY_BYTES says otherwise.
+const defaultMaxBodyBytes = 5 << 20
+
+// limitBody rejects POST, PUT and PATCH bodies larger than limit with 413
+// Request Entity Too Large. Routes in overrides (keyed by gin route path),
+// such as chart uploads, get their own cap. The body is buffered so the size
+// is known before any handler starts decoding it.
+func limitBody(limit int64, overrides map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+
+		max := limit
+		if override, ok := overrides[c.FullPath()]; ok {
+			max = override
+		}
+
+		if c.Request.ContentLength > max {
+			abortTooLarge(c, max)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, max))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortTooLarge(c, max)
+				return
+			}
+			respondError(c, http.StatusBadRequest, "invalid_request", "Failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		c.Next()
+	}
+}
+
+func abortTooLarge(c *gin.Context, limit int64) {
+	respondError(c, http.StatusRequestEntityTooLarge, "request_too_large",
+		"Request body too large; limit is "+strconv.FormatInt(limit, 10)+" bytes")
+}
+
+// maxBodyBytesFromEnv reads MAX_BODY_BYTES, falling back to the default when
+// it is unset or not a positive integer.
+func maxBodyBytesFromEnv() int64 {
+	if limit, err := strconv.ParseInt(os.Getenv("MAX_BODY_BYTES"), 10, 64); err == nil && limit > 0 {
+		return limit
+	}
+	return defaultMaxBodyBytes
+}
+
+// Defaults for the chart processing limit, overridable with
+// MAX_CONCURRENT_PROCESSING, PROCESSING_QUEUE_SIZE and
+// PROCESSING_QUEUE_TIMEOUT.
+const (
+	defaultMaxConcurrentProcessing = 8
+	defaultProcessingQueueSize     = 32
+	defaultProcessingQueueTimeout  = 30 * time.Second
+)
+
+// limitConcurrency lets at most limit requests run the rest of the chain at
+// once, guarding expensive work such as downloading charts and running helm.
+// Up to queue further requests wait at most wait for a slot; requests beyond
+// that, or that time out waiting, get 503 Service Unavailable. A limit of
+// zero or less disables the guard.
+func limitConcurrency(limit, queue int, wait time.Duration) gin.HandlerFunc {
+	if limit <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if queue < 0 {
+		queue = 0
+	}
+
+	running := make(chan struct{}, limit)
+	admitted := make(chan struct{}, limit+queue)
+	return func(c *gin.Context) {
+		select {
+		case admitted <- struct{}{}:
+			defer func() { <-admitted }()
+		default:
+			abortBusy(c)
+			return
+		}
+
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case running <- struct{}{}:
+			defer func() { <-running }()
+		case <-timer.C:
+			abortBusy(c)
+			return
+		case <-c.Request.Context().Done():
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func abortBusy(c *gin.Context) {
+	c.Header("Retry-After", "5")
+	respondError(c, http.StatusServiceUnavailable, "server_busy", "Too many charts are being processed; try again shortly")
+}
+
+// processingLimitFromEnv builds the chart processing guard from the
+// environment, falling back to the defaults for unset or invalid values.
+// MAX_CONCURRENT_PROCESSING=0 disables it.
+func processingLimitFromEnv() gin.HandlerFunc {
+	limit := defaultMaxConcurrentProcessing
+	if value, err := strconv.Atoi(os.Getenv("MAX_CONCURRENT_PROCESSING")); err == nil && value >= 0 {
+		limit = value
+	}
+	queue := defaultProcessingQueueSize
+	if value, err := strconv.Atoi(os.Getenv("PROCESSING_QUEUE_SIZE")); err == nil && value >= 0 {
+		queue = value
+	}
+	wait := defaultProcessingQueueTimeout
+	if value, err := time.ParseDuration(os.Getenv("PROCESSING_QUEUE_TIMEOUT")); err == nil && value > 0 {
+		wait = value
+	}
+	return limitConcurrency(limit, queue, wait)
+}
@@ -0,0 +1,270 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	t.Setenv("API_KEYS", "key-one, key-two")
+	router := setupRouter()
+
+	tests := []struct {
+		name           string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "valid X-API-Key header",
+			path:           "/api/repositories",
+			headers:        map[string]string{"X-API-Key": "key-two"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "valid bearer token",
+			path:           "/api/repositories",
+			headers:        map[string]string{"Authorization": "Bearer key-one"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid key",
+			path:           "/api/repositories",
+			headers:        map[string]string{"X-API-Key": "wrong"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing key",
+			path:           "/api/repositories",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "health check stays open",
+			path:           "/api/health",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "openapi spec stays open",
+			path:           "/api/openapi.json",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestAPIKeyAuthOpenMode(t *testing.T) {
+	t.Setenv("API_KEYS", "")
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/repositories", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, parseAPIKeys(" a, ,b ,"))
+	assert.Empty(t, parseAPIKeys(""))
+}
+
+func TestRequireJSON(t *testing.T) {
+	router := setupRouter()
+	body := `{"template": "ai"}`
+
+	tests := []struct {
+		name           string
+		contentType    string
+		expectedStatus int
+	}{
+		{"missing content type", "", http.StatusUnsupportedMediaType},
+		{"wrong content type", "text/plain", http.StatusUnsupportedMediaType},
+		{"xml content type", "application/xml", http.StatusUnsupportedMediaType},
+		{"json content type", "application/json", http.StatusNotFound},
+		{"json with charset", "application/json; charset=utf-8", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/chart/non-existent/apply-template", strings.NewReader(body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			router.ServeHTTP(w, req)
+
+			// A JSON request gets past the middleware and fails on the
+			// unknown session instead.
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	t.Setenv("MAX_BODY_BYTES", "1024")
+	router := setupRouter()
+
+	oversized := `{"questions": [{"variable": "` + strings.Repeat("a", 2048) + `"}]}`
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/chart/non-existent", strings.NewReader(oversized))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Without a declared length the limit is enforced while reading
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/chart/non-existent", strings.NewReader(oversized))
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = -1
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/chart/non-existent", strings.NewReader(`{"questions": []}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestLimitBodyOverride(t *testing.T) {
+	router := gin.New()
+	router.Use(limitBody(16, map[string]int64{"/upload": 1024}))
+	router.POST("/upload", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/other", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	body := strings.Repeat("x", 512)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/upload", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/other", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestLimitConcurrency(t *testing.T) {
+	const limit = 3
+	var active, peak int32
+	router := gin.New()
+	router.POST("/process", limitConcurrency(limit, 20, time.Minute), func(c *gin.Context) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		c.Status(http.StatusOK)
+	})
+
+	var wg sync.WaitGroup
+	codes := make([]int, 12)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/process", http.NoBody)
+			router.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	for _, code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(limit))
+}
+
+func TestLimitConcurrencyRejectsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	router := gin.New()
+	router.POST("/process", limitConcurrency(1, 1, 50*time.Millisecond), func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/process", http.NoBody)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// One request runs and holds the only slot
+	first := make(chan int)
+	go func() { first <- serve().Code }()
+	<-started
+
+	// A second request queues, then gives up when the wait expires
+	queued := make(chan *httptest.ResponseRecorder)
+	go func() { queued <- serve() }()
+	time.Sleep(10 * time.Millisecond)
+
+	// With the slot and the queue taken, a third is rejected immediately
+	w := serve()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "server_busy")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	w = <-queued
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-first)
+	assert.Equal(t, http.StatusOK, serve().Code)
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/health", nil)
+	router.ServeHTTP(w, req)
+	generated := w.Header().Get("X-Request-ID")
+	assert.NotEmpty(t, generated)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/chart/non-existent", nil)
+	req.Header.Set("X-Request-ID", "trace-123.abc")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "trace-123.abc", w.Header().Get("X-Request-ID"))
+	assert.Contains(t, w.Body.String(), `"request_id":"trace-123.abc"`)
+
+	// IDs that could inject into logs are replaced
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/health", nil)
+	req.Header.Set("X-Request-ID", "bad id\nwith newline")
+	router.ServeHTTP(w, req)
+	replaced := w.Header().Get("X-Request-ID")
+	assert.NotEmpty(t, replaced)
+	assert.NotContains(t, replaced, " ")
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var ginParam = regexp.MustCompile(`:([^/]+)`)
+
+type openAPIDoc struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components map[string]map[string]json.RawMessage `json:"components"`
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/openapi.json", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	var doc openAPIDoc
+	if !assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc)) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(doc.OpenAPI, "3."), "openapi version %q", doc.OpenAPI)
+	assert.NotEmpty(t, doc.Info.Title)
+	assert.NotEmpty(t, doc.Info.Version)
+
+	for _, path := range []string{"/api/chart", "/api/chart/{session_id}", "/api/chart/{session_id}/q", "/api/repositories", "/api/charts/search"} {
+		assert.Contains(t, doc.Paths, path)
+	}
+	for _, schema := range []string{"Error", "ChartRequest", "ChartResponse", "RepositoryRequest", "Questions"} {
+		assert.Contains(t, doc.Components["schemas"], schema)
+	}
+
+	// Every registered API route must be documented.
+	for _, route := range router.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/") {
+			continue
+		}
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		assert.Contains(t, doc.Paths[path], strings.ToLower(route.Method), "route %s %s is missing from the spec", route.Method, path)
+	}
+
+	// Every $ref must point at a defined component.
+	for _, ref := range regexp.MustCompile(`"\$ref":\s*"#/components/(\w+)/(\w+)"`).FindAllStringSubmatch(w.Body.String(), -1) {
+		assert.Contains(t, doc.Components[ref[1]], ref[2], "dangling $ref %s", ref[0])
+	}
+}
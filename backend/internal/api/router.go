@@ -6,11 +6,13 @@ import (
 
 func SetupRouter() *gin.Engine {
 	router := gin.Default()
+	router.Use(requestID())
 
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
 		
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -20,28 +22,52 @@ func SetupRouter() *gin.Engine {
 		c.Next()
 	})
 
+	router.NoRoute(routeNotFound)
+
 	handlers := NewHandlers()
+	// Routes that download charts or run helm share a concurrency limit
+	processing := processingLimitFromEnv()
 
 	api := router.Group("/api")
+	api.Use(apiKeyAuth(apiKeysFromEnv()), limitBody(maxBodyBytesFromEnv(), nil), requireJSON())
 	{
 		api.GET("/health", handlers.HealthCheck)
+		api.GET("/openapi.json", handlers.OpenAPISpec)
+		api.GET("/version", handlers.Version)
 		
 		// Legacy chart processing (direct URL)
-		api.POST("/chart", handlers.ProcessChart)
+		api.POST("/chart", processing, handlers.ProcessChart)
+		api.DELETE("/chart", handlers.ClearSessions)
 		api.GET("/chart/:session_id", handlers.GetChart)
 		api.PUT("/chart/:session_id", handlers.UpdateChart)
 		api.GET("/chart/:session_id/q", handlers.GetQuestionsYAML)
+		api.GET("/chart/:session_id/original-questions", handlers.GetOriginalQuestions)
+		api.GET("/chart/:session_id/groups", handlers.GetGroups)
+		api.PUT("/chart/:session_id/groups", handlers.SetGroupWeights)
+		api.POST("/chart/:session_id/evaluate", handlers.EvaluateVisibility)
+		api.GET("/chart/:session_id/values.schema.json", handlers.GetValuesSchema)
+		api.POST("/chart/:session_id/apply-template", handlers.ApplyTemplate)
+		api.POST("/chart/:session_id/questions/bulk-type", handlers.BulkSetType)
+		api.POST("/chart/:session_id/undo", handlers.UndoChart)
+		api.POST("/chart/:session_id/render", processing, handlers.RenderChart)
+		api.GET("/chart/:session_id/values/flat", handlers.GetFlatValues)
+		api.POST("/chart/:session_id/values/apply", handlers.ApplyFlatValues)
+		api.POST("/questions/validate", handlers.ValidateQuestions)
 		
 		// Repository management
 		api.POST("/repositories", handlers.AddRepository)
 		api.GET("/repositories", handlers.ListRepositories)
+		api.GET("/repositories/status", handlers.GetRepositoryStatus)
+		api.POST("/repositories/warm", processing, handlers.WarmRepositories)
 		api.DELETE("/repositories/:name", handlers.RemoveRepository)
 		
 		// Chart search and processing from repositories
 		api.GET("/charts/search", handlers.SearchCharts)
 		api.POST("/charts/search", handlers.SearchCharts)
-		api.POST("/charts/process", handlers.ProcessChartFromRepository)
+		api.POST("/charts/process", processing, handlers.ProcessChartFromRepository)
+		api.POST("/charts/process-url", processing, handlers.ProcessChartSource)
 		api.GET("/repositories/:repository/charts", handlers.GetRepositoryCharts)
+		api.GET("/repositories/:repository/charts/:chart/diff", processing, handlers.DiffChartVersions)
 		
 		// System information
 		api.GET("/storage-classes", handlers.GetStorageClasses)
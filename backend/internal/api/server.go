@@ -0,0 +1,44 @@
+package api
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TLSFiles returns the certificate and key files from TLS_CERT_FILE and
+// TLS_KEY_FILE. Both empty means serving plain HTTP; setting only one of
+// them is a configuration error.
+func TLSFiles() (certFile, keyFile string, err error) {
+	certFile, keyFile = os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE")
+	if (certFile == "") != (keyFile == "") {
+		return "", "", errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	return certFile, keyFile, nil
+}
+
+// NewServer returns a server for handler on addr. Its TLS configuration,
+// used only when serving HTTPS, refuses anything older than TLS 1.2; the
+// certificate is selected through SNI by the standard library.
+func NewServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+}
+
+// Serve accepts connections on listener, over HTTPS when certFile and
+// keyFile are set and plain HTTP otherwise.
+func Serve(server *http.Server, listener net.Listener, certFile, keyFile string) error {
+	if certFile != "" && keyFile != "" {
+		return server.ServeTLS(listener, certFile, keyFile)
+	}
+	return server.Serve(listener)
+}
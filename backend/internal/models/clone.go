@@ -0,0 +1,106 @@
+package models
+
+// Clone returns a deep copy of the session so it can be read or modified
+// without affecting the stored original.
+func (s *Session) Clone() *Session {
+	if s == nil {
+		return nil
+	}
+
+	clone := *s
+	clone.Values = CloneValues(s.Values)
+	clone.Questions = s.Questions.Clone()
+	clone.OriginalQuestions = s.OriginalQuestions.Clone()
+	if s.Revisions != nil {
+		clone.Revisions = make([]Questions, len(s.Revisions))
+		for i, revision := range s.Revisions {
+			clone.Revisions[i] = revision.Clone()
+		}
+	}
+	if s.Chart != nil {
+		chart := *s.Chart
+		chart.Keywords = cloneStrings(s.Chart.Keywords)
+		chart.Sources = cloneStrings(s.Chart.Sources)
+		if s.Chart.Maintainers != nil {
+			chart.Maintainers = append([]Maintainer(nil), s.Chart.Maintainers...)
+		}
+		clone.Chart = &chart
+	}
+	if s.HelmAvailable != nil {
+		available := *s.HelmAvailable
+		clone.HelmAvailable = &available
+	}
+	return &clone
+}
+
+// Clone returns a deep copy of the questions, including subquestions.
+func (q Questions) Clone() Questions {
+	clone := Questions{Questions: cloneQuestionList(q.Questions)}
+	if q.Groups != nil {
+		clone.Groups = append([]GroupMeta(nil), q.Groups...)
+	}
+	return clone
+}
+
+func cloneQuestionList(questions []Question) []Question {
+	if questions == nil {
+		return nil
+	}
+	clone := make([]Question, len(questions))
+	for i, question := range questions {
+		clone[i] = question
+		clone[i].Default = cloneValue(question.Default)
+		clone[i].Options = cloneStrings(question.Options)
+		clone[i].Min = cloneInt(question.Min)
+		clone[i].Max = cloneInt(question.Max)
+		clone[i].MinLength = cloneInt(question.MinLength)
+		clone[i].MaxLength = cloneInt(question.MaxLength)
+		clone[i].SubQuestions = cloneQuestionList(question.SubQuestions)
+	}
+	return clone
+}
+
+// CloneValues returns a deep copy of a values tree as decoded from YAML or
+// JSON, copying nested maps and slices.
+func CloneValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		clone[key] = cloneValue(value)
+	}
+	return clone
+}
+
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return CloneValues(v)
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = cloneValue(item)
+		}
+		return clone
+	case []string:
+		return cloneStrings(v)
+	default:
+		return v
+	}
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string(nil), values...)
+}
+
+func cloneInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	v := *value
+	return &v
+}
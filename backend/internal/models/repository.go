@@ -14,19 +14,26 @@ type Repository struct {
 type Authentication struct {
 	Username   string `json:"username,omitempty"`
 	Password   string `json:"password,omitempty"`
+	Token      string `json:"token,omitempty"` // Bearer token, used instead of username/password
 	SecretName string `json:"secret_name,omitempty"`
 	BaseURL    string `json:"base_url,omitempty"` // For credential reuse (e.g., dp.apps.rancher.io)
 }
 
 type Chart struct {
-	Name        string   `json:"name"`
-	Version     string   `json:"version"`
-	Versions    []string `json:"versions,omitempty"`
-	AppVersion  string   `json:"app_version,omitempty"`
-	Description string   `json:"description,omitempty"`
-	Repository  string   `json:"repository"`
-	Keywords    []string `json:"keywords,omitempty"`
-	Icon        string   `json:"icon,omitempty"`
+	Name        string       `json:"name"`
+	Version     string       `json:"version"`
+	Versions    []string     `json:"versions,omitempty"`
+	AppVersion  string       `json:"app_version,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Repository  string       `json:"repository"`
+	Keywords    []string     `json:"keywords,omitempty"`
+	Icon        string       `json:"icon,omitempty"`
+	Home        string       `json:"home,omitempty"`
+	Sources     []string     `json:"sources,omitempty"`
+	Maintainers []Maintainer `json:"maintainers,omitempty"`
+	// Repositories lists every repository providing the chart when search
+	// results are deduplicated.
+	Repositories []string `json:"repositories,omitempty"`
 }
 
 type RepositoryRequest struct {
@@ -45,6 +52,32 @@ type ChartProcessRequest struct {
 	Repository string `json:"repository" binding:"required"`
 	Chart      string `json:"chart" binding:"required"`
 	Version    string `json:"version,omitempty"`
+	// TypeOverrides forces the type of generated questions, keyed by variable.
+	TypeOverrides map[string]string `json:"typeOverrides,omitempty"`
+	// Profile selects the question generation profile: minimal, standard
+	// (the default) or exhaustive.
+	Profile string `json:"profile,omitempty"`
+}
+
+// ChartSourceRequest identifies a chart either by URL or by repository and
+// chart name; exactly one form must be given.
+type ChartSourceRequest struct {
+	URL        string `json:"url,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Chart      string `json:"chart,omitempty"`
+	Version    string `json:"version,omitempty"`
+	// TypeOverrides forces the type of generated questions, keyed by variable.
+	TypeOverrides map[string]string `json:"typeOverrides,omitempty"`
+	// Profile selects the question generation profile: minimal, standard
+	// (the default) or exhaustive.
+	Profile string `json:"profile,omitempty"`
+}
+
+type ChartResolveResponse struct {
+	ChartURL   string `json:"chart_url"`
+	Repository string `json:"repository"`
+	Chart      string `json:"chart"`
+	Version    string `json:"version,omitempty"`
 }
 
 type Project struct {
@@ -4,36 +4,130 @@ import "time"
 
 type ChartRequest struct {
 	URL string `json:"url" binding:"required"`
+	// TypeOverrides forces the type of generated questions, keyed by variable.
+	TypeOverrides map[string]string `json:"typeOverrides,omitempty"`
+	// Profile selects the question generation profile: minimal, standard
+	// (the default) or exhaustive.
+	Profile string `json:"profile,omitempty"`
 }
 
 type Session struct {
-	ID          string                 `json:"id"`
-	ChartURL    string                 `json:"chart_url"`
-	Values      map[string]interface{} `json:"values"`
-	Questions   Questions              `json:"questions"`
-	CreatedAt   time.Time              `json:"created_at"`
-	UpdatedAt   time.Time              `json:"updated_at"`
+	ID        string                 `json:"id"`
+	ChartURL  string                 `json:"chart_url"`
+	Values    map[string]interface{} `json:"values"`
+	Questions Questions              `json:"questions"`
+	Chart     *ChartMeta             `json:"chart,omitempty"`
+	Readme    string                 `json:"readme,omitempty"`
+	Notes     string                 `json:"notes,omitempty"`
+	// ValuesSchema is the chart's values.schema.json, used to validate
+	// submitted values.
+	ValuesSchema string `json:"values_schema,omitempty"`
+	// OriginalQuestions is the chart's questions.yaml as shipped, before it
+	// was merged with generated questions.
+	OriginalQuestions Questions `json:"original_questions"`
+	// QuestionsSource is "chart", "generated" or "merged", telling whether
+	// the questions came from the chart, from its values or from both.
+	QuestionsSource string `json:"questions_source,omitempty"`
+	// HelmAvailable is only set for OCI charts; false means example data.
+	HelmAvailable *bool `json:"helm_available,omitempty"`
+	// QuestionsTruncated is set when generation hit the question cap.
+	QuestionsTruncated bool `json:"questions_truncated,omitempty"`
+	// Revisions holds earlier question sets, oldest first, for undo.
+	Revisions []Questions `json:"revisions,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// ChartMeta is the subset of a chart's Chart.yaml surfaced to clients.
+type ChartMeta struct {
+	Name        string       `yaml:"name" json:"name"`
+	Version     string       `yaml:"version" json:"version"`
+	AppVersion  string       `yaml:"appVersion,omitempty" json:"app_version,omitempty"`
+	Description string       `yaml:"description,omitempty" json:"description,omitempty"`
+	Icon        string       `yaml:"icon,omitempty" json:"icon,omitempty"`
+	Keywords    []string     `yaml:"keywords,omitempty" json:"keywords,omitempty"`
+	Home        string       `yaml:"home,omitempty" json:"home,omitempty"`
+	Sources     []string     `yaml:"sources,omitempty" json:"sources,omitempty"`
+	Maintainers []Maintainer `yaml:"maintainers,omitempty" json:"maintainers,omitempty"`
+}
+
+// Maintainer is a chart maintainer as listed in Chart.yaml or index.yaml.
+type Maintainer struct {
+	Name  string `yaml:"name" json:"name"`
+	Email string `yaml:"email,omitempty" json:"email,omitempty"`
+	URL   string `yaml:"url,omitempty" json:"url,omitempty"`
 }
 
 type Questions struct {
 	Questions []Question `yaml:"questions" json:"questions"`
+	// Groups holds ordering weights for question groups. Rancher renders
+	// groups in order of first appearance, so the weights take effect by
+	// ordering Questions; they are kept here so later edits preserve them.
+	Groups []GroupMeta `yaml:"groups,omitempty" json:"groups,omitempty"`
+}
+
+// GroupMeta sets the weight of a question group. Groups with lower weights
+// are shown first; groups without an entry have weight 0.
+type GroupMeta struct {
+	Name   string `yaml:"name" json:"name" binding:"required"`
+	Weight int    `yaml:"weight" json:"weight"`
+}
+
+// GroupWeightsRequest replaces the group weights of a session.
+type GroupWeightsRequest struct {
+	Groups []GroupMeta `json:"groups" binding:"required,dive"`
 }
 
 type Question struct {
-	Variable     string      `yaml:"variable" json:"variable"`
-	Label        string      `yaml:"label" json:"label"`
-	Description  string      `yaml:"description,omitempty" json:"description,omitempty"`
-	Type         string      `yaml:"type,omitempty" json:"type,omitempty"`
-	Required     bool        `yaml:"required,omitempty" json:"required,omitempty"`
-	Default      interface{} `yaml:"default,omitempty" json:"default,omitempty"`
-	Group        string      `yaml:"group,omitempty" json:"group,omitempty"`
-	Options      []string    `yaml:"options,omitempty" json:"options,omitempty"`
-	ShowIf       string      `yaml:"show_if,omitempty" json:"show_if,omitempty"`
-	SubQuestions []Question  `yaml:"subquestions,omitempty" json:"subquestions,omitempty"`
+	Variable          string      `yaml:"variable" json:"variable"`
+	Label             string      `yaml:"label" json:"label"`
+	Description       string      `yaml:"description,omitempty" json:"description,omitempty"`
+	Type              string      `yaml:"type,omitempty" json:"type,omitempty"`
+	Required          bool        `yaml:"required,omitempty" json:"required,omitempty"`
+	Default           interface{} `yaml:"default,omitempty" json:"default,omitempty"`
+	Group             string      `yaml:"group,omitempty" json:"group,omitempty"`
+	Options           []string    `yaml:"options,omitempty" json:"options,omitempty"`
+	ValidChars        string      `yaml:"valid_chars,omitempty" json:"valid_chars,omitempty"`
+	Min               *int        `yaml:"min,omitempty" json:"min,omitempty"`
+	Max               *int        `yaml:"max,omitempty" json:"max,omitempty"`
+	MinLength         *int        `yaml:"min_length,omitempty" json:"min_length,omitempty"`
+	MaxLength         *int        `yaml:"max_length,omitempty" json:"max_length,omitempty"`
+	ShowIf            string      `yaml:"show_if,omitempty" json:"show_if,omitempty"`
+	ShowSubquestionIf string      `yaml:"show_subquestion_if,omitempty" json:"show_subquestion_if,omitempty"`
+	SubQuestions      []Question  `yaml:"subquestions,omitempty" json:"subquestions,omitempty"`
+	// Namespace hints which namespace a secret question's secret lives in.
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	// RequiredIf makes the question required only while the show_if style
+	// expression holds, e.g. a password required when auth.enabled=true.
+	RequiredIf string `yaml:"required_if,omitempty" json:"required_if,omitempty"`
 }
 
 type ChartResponse struct {
 	SessionID string                 `json:"session_id"`
 	Values    map[string]interface{} `json:"values"`
 	Questions Questions              `json:"questions"`
-}
\ No newline at end of file
+	Chart     *ChartMeta             `json:"chart,omitempty"`
+	Readme    string                 `json:"readme,omitempty"`
+	Notes     string                 `json:"notes,omitempty"`
+	// HelmAvailable is only set for OCI charts. When false, Warning explains
+	// that the values shown are example data.
+	HelmAvailable *bool  `json:"helm_available,omitempty"`
+	Warning       string `json:"warning,omitempty"`
+	// QuestionsTruncated reports that some generated questions were dropped
+	// because the chart exceeded the question cap.
+	QuestionsTruncated bool `json:"questions_truncated,omitempty"`
+	// QuestionsSource is "chart", "generated" or "merged", telling whether
+	// the questions came from the chart, from its values or from both.
+	QuestionsSource string `json:"questions_source,omitempty"`
+}
+
+type ApplyTemplateRequest struct {
+	Template string `json:"template" binding:"required"`
+}
+
+// BulkTypeRequest sets Type on every question whose variable matches the
+// glob Pattern.
+type BulkTypeRequest struct {
+	Pattern string `json:"pattern" binding:"required"`
+	Type    string `json:"type" binding:"required"`
+}
@@ -0,0 +1,31 @@
+// Package version holds the build version of the application.
+package version
+
+import "runtime"
+
+// Build information. Release builds set these with -ldflags, e.g.
+// -X rancher-questions-generator/internal/version.Version=1.2.3; Makefile
+// and the Dockerfile pass the git commit and build date the same way.
+var (
+	Version   = "dev"
+	Commit    = "dev"
+	BuildDate = "dev"
+)
+
+// Info describes the running build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the running build's information.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -0,0 +1,114 @@
+package helm
+
+import (
+	"path"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// advancedToggle is the boolean that reveals advanced questions, following
+// the convention used by the built-in templates.
+const advancedToggle = "advancedConfig"
+
+// advancedGroup collects questions forced behind the advanced toggle by
+// Processor.AdvancedPatterns.
+const advancedGroup = "Advanced"
+
+// forceAdvanced moves the questions whose variable matches one of patterns
+// into advancedGroup and shows them only while the advanced toggle is on,
+// keeping any condition they already have. Scheduling questions keep their
+// own group.
+func forceAdvanced(questions []models.Question, patterns []string) {
+	if len(patterns) == 0 {
+		return
+	}
+	for i := range questions {
+		q := &questions[i]
+		if q.Variable == advancedToggle || !matchesAdvancedPattern(q.Variable, patterns) {
+			continue
+		}
+		if q.Group != schedulingGroup {
+			q.Group = advancedGroup
+		}
+		switch {
+		case q.ShowIf == "":
+			q.ShowIf = advancedToggle + "=true"
+		case !conditionReferences(q.ShowIf, advancedToggle):
+			q.ShowIf += "&&" + advancedToggle + "=true"
+		}
+	}
+}
+
+// matchesAdvancedPattern reports whether variable matches one of the glob
+// patterns, ignoring case. "*" matches any run of characters, dots
+// included, and a leading "*." also matches top-level keys, so "*.debug"
+// matches both debug and worker.debug.
+func matchesAdvancedPattern(variable string, patterns []string) bool {
+	variable = strings.ToLower(variable)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if ok, _ := path.Match(pattern, variable); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, "."+variable); ok && strings.HasPrefix(pattern, "*.") {
+			return true
+		}
+	}
+	return false
+}
+
+// withAdvancedToggle prepends the advanced toggle, a boolean defaulting to
+// false, when any question or subquestion is shown only through it and
+// questions doesn't define it already.
+func withAdvancedToggle(questions []models.Question) []models.Question {
+	if findQuestionByVariable(questions, advancedToggle) || !usesAdvancedToggle(questions) {
+		return questions
+	}
+
+	toggle := models.Question{
+		Variable:    advancedToggle,
+		Label:       "Enable Advanced",
+		Description: "Show advanced settings such as pod scheduling",
+		Type:        "boolean",
+		Default:     false,
+		Group:       "General",
+	}
+	return append([]models.Question{toggle}, questions...)
+}
+
+// usesAdvancedToggle reports whether any question or subquestion has a
+// show_if condition on the advanced toggle.
+func usesAdvancedToggle(questions []models.Question) bool {
+	for _, q := range questions {
+		if conditionReferences(q.ShowIf, advancedToggle) || usesAdvancedToggle(q.SubQuestions) {
+			return true
+		}
+	}
+	return false
+}
+
+// findQuestionByVariable reports whether a question or subquestion has the
+// given variable.
+func findQuestionByVariable(questions []models.Question, variable string) bool {
+	for _, q := range questions {
+		if q.Variable == variable || findQuestionByVariable(q.SubQuestions, variable) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionReferences reports whether a show_if expression such as
+// "a=true&&b!=x||c=1" compares variable.
+func conditionReferences(expression, variable string) bool {
+	for _, clause := range strings.Split(expression, "||") {
+		for _, term := range strings.Split(clause, "&&") {
+			name, _, found := strings.Cut(term, "=")
+			if found && strings.TrimSpace(strings.TrimSuffix(name, "!")) == variable {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package helm
+
+import (
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestWithAdvancedToggle(t *testing.T) {
+	questions := []models.Question{
+		{Variable: "replicaCount", Type: "int"},
+		{Variable: "debug", Type: "boolean", ShowIf: "advancedConfig=true"},
+		{Variable: "tolerations", Type: "multiline", ShowIf: "advancedConfig=true"},
+		{
+			Variable:          "metrics.enabled",
+			Type:              "boolean",
+			ShowSubquestionIf: "true",
+			SubQuestions: []models.Question{
+				{Variable: "metrics.interval", Type: "string", ShowIf: "metrics.enabled=true&&advancedConfig=true"},
+			},
+		},
+	}
+
+	result := withAdvancedToggle(questions)
+	toggles := 0
+	for _, q := range result {
+		if q.Variable == advancedToggle {
+			toggles++
+		}
+	}
+	if toggles != 1 {
+		t.Fatalf("Expected the toggle to be inserted exactly once, got %d", toggles)
+	}
+	toggle := result[0]
+	if toggle.Variable != advancedToggle || toggle.Type != "boolean" || toggle.Default != false || toggle.Group != "General" {
+		t.Errorf("Expected a boolean advancedConfig toggle first, got %+v", toggle)
+	}
+	if len(result) != len(questions)+1 {
+		t.Errorf("Expected %d questions, got %d", len(questions)+1, len(result))
+	}
+
+	// Applying it again doesn't add a second toggle
+	if again := withAdvancedToggle(result); len(again) != len(result) {
+		t.Errorf("Expected no second toggle, got %d questions", len(again))
+	}
+
+	tests := []struct {
+		name      string
+		questions []models.Question
+		want      bool
+	}{
+		{"no conditions", []models.Question{{Variable: "replicaCount"}}, false},
+		{"other condition", []models.Question{{Variable: "a", ShowIf: "advancedConfigMode=true"}}, false},
+		{"subquestion", []models.Question{{Variable: "a", SubQuestions: []models.Question{{Variable: "b", ShowIf: "advancedConfig=true"}}}}, true},
+		{"alternative", []models.Question{{Variable: "a", ShowIf: "mode=custom||advancedConfig=true"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := len(withAdvancedToggle(tt.questions)) > len(tt.questions)
+			if got != tt.want {
+				t.Errorf("Expected toggle inserted %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAdvancedPatterns(t *testing.T) {
+	processor := NewProcessor()
+	processor.AdvancedPatterns = []string{"*.debug", "*.experimental*"}
+	values := map[string]interface{}{
+		"debug":        false,
+		"replicaCount": 1,
+		"worker": map[string]interface{}{
+			"enabled":  true,
+			"debug":    true,
+			"logLevel": "info",
+		},
+		"features": map[string]interface{}{
+			"experimentalApi": false,
+		},
+		"debugger": map[string]interface{}{
+			"port": 5005,
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	tests := []struct {
+		variable string
+		showIf   string
+	}{
+		{"debug", "advancedConfig=true"},
+		{"worker.debug", "worker.enabled=true&&advancedConfig=true"},
+		{"features.experimentalApi", "advancedConfig=true"},
+	}
+	for _, tt := range tests {
+		q := findQuestion(questions, tt.variable)
+		if q == nil {
+			t.Fatalf("Expected question for %s", tt.variable)
+		}
+		if q.Group != advancedGroup || q.ShowIf != tt.showIf {
+			t.Errorf("Expected %s in %s with show_if %q, got group %q and show_if %q", tt.variable, advancedGroup, tt.showIf, q.Group, q.ShowIf)
+		}
+	}
+
+	for _, variable := range []string{"replicaCount", "worker.logLevel", "debugger.port"} {
+		q := findQuestion(questions, variable)
+		if q == nil {
+			t.Fatalf("Expected question for %s", variable)
+		}
+		if q.Group == advancedGroup || conditionReferences(q.ShowIf, advancedToggle) {
+			t.Errorf("Expected %s not to be gated, got group %q and show_if %q", variable, q.Group, q.ShowIf)
+		}
+	}
+
+	if questions[0].Variable != advancedToggle {
+		t.Errorf("Expected the advanced toggle first, got %s", questions[0].Variable)
+	}
+
+	// Without patterns nothing is forced behind the toggle
+	processor.AdvancedPatterns = nil
+	questions = processor.generateDefaultQuestions(values, ProfileStandard).Questions
+	if q := findQuestion(questions, "debug"); q == nil || q.ShowIf != "" {
+		t.Errorf("Expected debug to be ungated without patterns, got %+v", q)
+	}
+	if findQuestion(questions, advancedToggle) != nil {
+		t.Error("Expected no advanced toggle without gated questions")
+	}
+}
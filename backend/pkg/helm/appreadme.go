@@ -0,0 +1,91 @@
+package helm
+
+import (
+	"os"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// appReadmeHint is one entry of the questions list in the YAML front matter
+// of a chart's app-readme.md, e.g.
+//
+//	---
+//	questions:
+//	  - variable: ingress.host
+//	    description: Public hostname of the dashboard
+//	    group: Networking
+//	---
+type appReadmeHint struct {
+	Variable    string `yaml:"variable"`
+	Label       string `yaml:"label"`
+	Description string `yaml:"description"`
+	Group       string `yaml:"group"`
+}
+
+// parseAppReadmeHints returns the question hints from the front matter of the
+// chart's app-readme.md, keyed by variable. Like value comments they are
+// best effort: a missing file or malformed front matter yields no hints.
+func (p *Processor) parseAppReadmeHints(chartDir string) map[string]appReadmeHint {
+	path := p.findFile(chartDir, "app-readme.md")
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return appReadmeHints(data)
+}
+
+// appReadmeHints parses the hints from a YAML front matter block, delimited
+// by "---" lines, at the very start of data.
+func appReadmeHints(data []byte) map[string]appReadmeHint {
+	text := strings.ReplaceAll(strings.TrimPrefix(string(data), "\ufeff"), "\r\n", "\n")
+	body, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return nil
+	}
+	end := strings.Index(body, "\n---")
+	if end < 0 {
+		return nil
+	}
+
+	var frontMatter struct {
+		Questions []appReadmeHint `yaml:"questions"`
+	}
+	if err := yaml.Unmarshal([]byte(body[:end]), &frontMatter); err != nil {
+		return nil
+	}
+
+	hints := make(map[string]appReadmeHint, len(frontMatter.Questions))
+	for _, hint := range frontMatter.Questions {
+		if hint.Variable != "" {
+			hints[hint.Variable] = hint
+		}
+	}
+	return hints
+}
+
+// applyAppReadmeHints sets the label, description and group of questions,
+// including subquestions, from the hint for their variable. The chart author
+// wrote the hints, so they replace generated text; empty hint fields leave
+// the question unchanged.
+func applyAppReadmeHints(questions []models.Question, hints map[string]appReadmeHint) {
+	for i := range questions {
+		if hint, ok := hints[questions[i].Variable]; ok {
+			if hint.Label != "" {
+				questions[i].Label = hint.Label
+			}
+			if hint.Description != "" {
+				questions[i].Description = hint.Description
+			}
+			if hint.Group != "" {
+				questions[i].Group = hint.Group
+			}
+		}
+		applyAppReadmeHints(questions[i].SubQuestions, hints)
+	}
+}
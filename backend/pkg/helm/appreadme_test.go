@@ -0,0 +1,82 @@
+package helm
+
+import "testing"
+
+func TestAppReadmeHints(t *testing.T) {
+	tests := []struct {
+		name   string
+		readme string
+		want   map[string]string
+	}{
+		{
+			name:   "front matter",
+			readme: "---\nquestions:\n  - variable: ingress.host\n    description: Public hostname\n    group: Networking\n  - description: no variable\n---\n# Dashboard\n",
+			want:   map[string]string{"ingress.host": "Public hostname"},
+		},
+		{
+			name:   "windows line endings",
+			readme: "---\r\nquestions:\r\n  - variable: replicaCount\r\n    description: Pods to run\r\n---\r\n",
+			want:   map[string]string{"replicaCount": "Pods to run"},
+		},
+		{name: "no front matter", readme: "# Dashboard\n\nquestions: []\n"},
+		{name: "unterminated", readme: "---\nquestions:\n  - variable: a\n"},
+		{name: "malformed", readme: "---\nquestions: [\n---\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := appReadmeHints([]byte(tt.readme))
+			if len(hints) != len(tt.want) {
+				t.Fatalf("Expected %d hints, got %v", len(tt.want), hints)
+			}
+			for variable, description := range tt.want {
+				if hints[variable].Description != description {
+					t.Errorf("hints[%q].Description = %q, want %q", variable, hints[variable].Description, description)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessChartAppReadmeHints(t *testing.T) {
+	server := newChartServer(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": commentedValues,
+		"testchart/app-readme.md": `---
+questions:
+  - variable: service.port
+    description: Port the dashboard listens on
+    group: Networking
+  - variable: replicaCount
+    label: Dashboard Replicas
+---
+# Test chart
+
+Deploys a dashboard.
+`,
+	})
+
+	result, err := NewProcessor().ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+
+	questions := result.Questions.Questions
+	if q := findQuestion(questions, "service.port"); q == nil || q.Description != "Port the dashboard listens on" || q.Group != "Networking" {
+		t.Errorf("Unexpected service.port question: %+v", q)
+	}
+	// Fields the hint leaves out keep their generated values
+	if q := findQuestion(questions, "replicaCount"); q == nil || q.Label != "Dashboard Replicas" || q.Description != "Number of pods to run" {
+		t.Errorf("Unexpected replicaCount question: %+v", q)
+	}
+
+	processor := NewProcessor()
+	processor.AppReadmeHints = false
+	result, err = processor.ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+	if q := findQuestion(result.Questions.Questions, "service.port"); q == nil || q.Description == "Port the dashboard listens on" {
+		t.Errorf("Expected hints to be ignored when disabled: %+v", q)
+	}
+}
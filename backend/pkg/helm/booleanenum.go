@@ -0,0 +1,158 @@
+package helm
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// defaultBooleanLabels render a boolean enum with the literal values, which
+// Helm reads back as booleans.
+var defaultBooleanLabels = []string{"true", "false"}
+
+// parseBooleanLabels parses a comma-separated pair of labels for true and
+// false, such as "Yes,No". An empty string disables boolean enums.
+func parseBooleanLabels(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	labels := strings.Split(s, ",")
+	for i := range labels {
+		labels[i] = strings.TrimSpace(labels[i])
+	}
+	if len(labels) != 2 || labels[0] == "" || labels[1] == "" || labels[0] == labels[1] {
+		return nil, fmt.Errorf("boolean labels must be two distinct values for true and false, got %q", s)
+	}
+	return labels, nil
+}
+
+// literalLabels reports whether labels are the literal "true" and "false",
+// which Helm reads back as booleans.
+func literalLabels(labels []string) bool {
+	return len(labels) == 2 && labels[0] == "true" && labels[1] == "false"
+}
+
+// booleanLabelsFromEnv reads BOOLEAN_ENUM_LABELS; "true" selects the literal
+// labels. Invalid settings are reported and disable the option. Any other
+// labels are written to values as strings, which templates treat as truthy
+// even for "No", so they are refused unless BOOLEAN_ENUM_STRING_LABELS is
+// also set.
+func booleanLabelsFromEnv() []string {
+	value := os.Getenv("BOOLEAN_ENUM_LABELS")
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		if enabled {
+			return defaultBooleanLabels
+		}
+		return nil
+	}
+
+	labels, err := parseBooleanLabels(value)
+	if err != nil {
+		slog.Warn("ignoring BOOLEAN_ENUM_LABELS", "error", err)
+		return nil
+	}
+	if literalLabels(labels) {
+		return labels
+	}
+	if !envBool("BOOLEAN_ENUM_STRING_LABELS", false) {
+		slog.Warn("ignoring BOOLEAN_ENUM_LABELS: custom labels are written to values as strings; set BOOLEAN_ENUM_STRING_LABELS=true to use them anyway", "labels", value)
+		return nil
+	}
+	slog.Warn("BOOLEAN_ENUM_LABELS are written to values as strings, not booleans", "labels", value)
+	return labels
+}
+
+// booleanEnumQuestions turns boolean questions, including subquestions, into
+// enums offering labels, true first, and rewrites the conditions that test
+// them so they keep matching the new answers.
+func booleanEnumQuestions(questions []models.Question, labels []string) {
+	converted := make(map[string]bool)
+	convertBooleans(questions, labels, converted)
+	relabelConditions(questions, converted, labels)
+}
+
+func convertBooleans(questions []models.Question, labels []string, converted map[string]bool) {
+	for i := range questions {
+		q := &questions[i]
+		convertBooleans(q.SubQuestions, labels, converted)
+		if q.Type != "boolean" {
+			continue
+		}
+		q.Type = "enum"
+		q.Options = append([]string(nil), labels...)
+		if value, ok := booleanValue(q.Default); ok {
+			q.Default = booleanLabel(value, labels)
+		} else {
+			q.Default = nil
+		}
+		converted[q.Variable] = true
+	}
+}
+
+// relabelConditions rewrites show_if, required_if and show_subquestion_if
+// throughout questions. A bare show_subquestion_if value tests the parent.
+func relabelConditions(questions []models.Question, converted map[string]bool, labels []string) {
+	for i := range questions {
+		q := &questions[i]
+		q.ShowIf = relabelCondition(q.ShowIf, converted, labels)
+		q.RequiredIf = relabelCondition(q.RequiredIf, converted, labels)
+		if strings.Contains(q.ShowSubquestionIf, "=") {
+			q.ShowSubquestionIf = relabelCondition(q.ShowSubquestionIf, converted, labels)
+		} else if b, err := strconv.ParseBool(strings.TrimSpace(q.ShowSubquestionIf)); err == nil && converted[q.Variable] {
+			q.ShowSubquestionIf = booleanLabel(b, labels)
+		}
+		relabelConditions(q.SubQuestions, converted, labels)
+	}
+}
+
+// booleanValue reads a boolean default, accepting strings like "true".
+func booleanValue(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	}
+	return false, false
+}
+
+func booleanLabel(value bool, labels []string) string {
+	if value {
+		return labels[0]
+	}
+	return labels[1]
+}
+
+// relabelCondition replaces "true" and "false" in the terms of expression
+// that test a converted variable with the matching label.
+func relabelCondition(expression string, converted map[string]bool, labels []string) string {
+	if expression == "" {
+		return expression
+	}
+
+	clauses := strings.Split(expression, "||")
+	for i, clause := range clauses {
+		terms := strings.Split(clause, "&&")
+		for j, term := range terms {
+			operator := "="
+			if strings.Contains(term, "!=") {
+				operator = "!="
+			}
+			variable, value, found := strings.Cut(term, operator)
+			if !found || !converted[strings.TrimSpace(variable)] {
+				continue
+			}
+			if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+				terms[j] = variable + operator + booleanLabel(b, labels)
+			}
+		}
+		clauses[i] = strings.Join(terms, "&&")
+	}
+	return strings.Join(clauses, "||")
+}
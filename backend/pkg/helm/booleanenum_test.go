@@ -0,0 +1,139 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+	"rancher-questions-generator/pkg/questions"
+)
+
+func booleanValues() map[string]interface{} {
+	return map[string]interface{}{
+		"debug": true,
+		"metrics": map[string]interface{}{
+			"enabled": false,
+			"port":    9090,
+		},
+	}
+}
+
+func TestBooleanEnumQuestions(t *testing.T) {
+	processor := NewProcessor()
+
+	// Off by default: booleans stay booleans
+	generated := processor.generateDefaultQuestions(booleanValues(), ProfileStandard)
+	if q := findQuestion(generated.Questions, "debug"); q == nil || q.Type != "boolean" || q.Default != true {
+		t.Errorf("Expected debug to be a boolean defaulting to true, got %+v", q)
+	}
+
+	tests := []struct {
+		name       string
+		labels     []string
+		wantDebug  string
+		wantToggle string
+		wantShowIf string
+	}{
+		{"literal labels", []string{"true", "false"}, "true", "false", "metrics.enabled=true"},
+		{"custom labels", []string{"Yes", "No"}, "Yes", "No", "metrics.enabled=Yes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor.BooleanEnumLabels = tt.labels
+			generated := processor.generateDefaultQuestions(booleanValues(), ProfileStandard)
+
+			debug := findQuestion(generated.Questions, "debug")
+			if debug == nil || debug.Type != "enum" || !reflect.DeepEqual(debug.Options, tt.labels) || debug.Default != tt.wantDebug {
+				t.Errorf("Expected debug to be an enum of %v defaulting to %s, got %+v", tt.labels, tt.wantDebug, debug)
+			}
+			toggle := findQuestion(generated.Questions, "metrics.enabled")
+			if toggle == nil || toggle.Type != "enum" || toggle.Default != tt.wantToggle {
+				t.Errorf("Expected metrics.enabled to be an enum defaulting to %s, got %+v", tt.wantToggle, toggle)
+			}
+			if port := findQuestion(generated.Questions, "metrics.port"); port == nil || port.ShowIf != tt.wantShowIf {
+				t.Errorf("Expected metrics.port to be shown if %s, got %+v", tt.wantShowIf, port)
+			}
+
+			if _, err := questions.ValidateQuestions(generated); err != nil {
+				t.Errorf("Expected generated questions to validate, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBooleanEnumSubquestions(t *testing.T) {
+	questions := []models.Question{
+		{
+			Variable:          "ingress.enabled",
+			Type:              "boolean",
+			Default:           false,
+			ShowSubquestionIf: "true",
+			SubQuestions: []models.Question{
+				{Variable: "ingress.tls", Type: "boolean", Default: "true"},
+				{Variable: "ingress.secret", Type: "string", ShowIf: "ingress.tls=true&&ingress.enabled=true"},
+			},
+		},
+	}
+
+	booleanEnumQuestions(questions, []string{"Yes", "No"})
+
+	parent := questions[0]
+	if parent.Type != "enum" || parent.Default != "No" || parent.ShowSubquestionIf != "Yes" {
+		t.Errorf("Expected ingress.enabled to be an enum shown if Yes, got %+v", parent)
+	}
+	if tls := parent.SubQuestions[0]; tls.Type != "enum" || tls.Default != "Yes" || !reflect.DeepEqual(tls.Options, []string{"Yes", "No"}) {
+		t.Errorf("Expected ingress.tls to be an enum defaulting to Yes, got %+v", tls)
+	}
+	if secret := parent.SubQuestions[1]; secret.ShowIf != "ingress.tls=Yes&&ingress.enabled=Yes" {
+		t.Errorf("Expected ingress.secret conditions to be relabeled, got %q", secret.ShowIf)
+	}
+}
+
+func TestParseBooleanLabels(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"true,false", []string{"true", "false"}, false},
+		{" Yes , No ", []string{"Yes", "No"}, false},
+		{"Yes", nil, true},
+		{"Yes,No,Maybe", nil, true},
+		{"Yes,Yes", nil, true},
+		{"Yes,", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseBooleanLabels(tt.input)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseBooleanLabels(%q) = %v, %v; want %v, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBooleanLabelsFromEnv(t *testing.T) {
+	tests := []struct {
+		value        string
+		stringLabels string
+		want         []string
+	}{
+		{"", "", nil},
+		{"false", "", nil},
+		{"true", "", []string{"true", "false"}},
+		{"true,false", "", []string{"true", "false"}},
+		// Custom labels are written as strings, so they need an opt-in
+		{"On,Off", "", nil},
+		{"On,Off", "true", []string{"On", "Off"}},
+		{"On", "true", nil},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("BOOLEAN_ENUM_LABELS", tt.value)
+		t.Setenv("BOOLEAN_ENUM_STRING_LABELS", tt.stringLabels)
+		if got := booleanLabelsFromEnv(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BOOLEAN_ENUM_LABELS=%q BOOLEAN_ENUM_STRING_LABELS=%q gave %v, want %v", tt.value, tt.stringLabels, got, tt.want)
+		}
+	}
+}
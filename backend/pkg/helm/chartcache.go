@@ -0,0 +1,134 @@
+package helm
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// defaultChartCacheTTL is how long a repository's chart list is served from
+// memory before its index is fetched again.
+const defaultChartCacheTTL = 10 * time.Minute
+
+// defaultWarmConcurrency bounds how many repositories WarmCache fetches at
+// once.
+const defaultWarmConcurrency = 4
+
+// chartCacheEntry is a repository's chart list and when it was fetched.
+type chartCacheEntry struct {
+	charts    []*models.Chart
+	fetchedAt time.Time
+}
+
+// WarmResult reports how fetching one repository's charts went during
+// WarmCache.
+type WarmResult struct {
+	Repository string `json:"repository"`
+	Charts     int    `json:"charts"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// cachedCharts returns copies of the cached charts of a repository while they
+// are younger than chartCacheTTL, so callers can't change the cached ones.
+func (rm *RepositoryManager) cachedCharts(name string) ([]*models.Chart, bool) {
+	rm.cacheMutex.RLock()
+	entry, ok := rm.chartCache[name]
+	rm.cacheMutex.RUnlock()
+	if !ok || time.Since(entry.fetchedAt) > rm.chartCacheTTL {
+		return nil, false
+	}
+
+	charts := make([]*models.Chart, len(entry.charts))
+	for i, chart := range entry.charts {
+		copied := *chart
+		charts[i] = &copied
+	}
+	return charts, true
+}
+
+// storeCharts caches the charts of a repository. Nothing is cached when
+// chartCacheTTL is zero or less.
+func (rm *RepositoryManager) storeCharts(name string, charts []*models.Chart) {
+	if rm.chartCacheTTL <= 0 {
+		return
+	}
+	stored := make([]*models.Chart, len(charts))
+	for i, chart := range charts {
+		copied := *chart
+		stored[i] = &copied
+	}
+
+	rm.cacheMutex.Lock()
+	defer rm.cacheMutex.Unlock()
+	if rm.chartCache == nil {
+		rm.chartCache = make(map[string]chartCacheEntry)
+	}
+	rm.chartCache[name] = chartCacheEntry{charts: stored, fetchedAt: time.Now()}
+}
+
+// forgetCharts drops the cached charts of a repository, e.g. when it is
+// removed or replaced.
+func (rm *RepositoryManager) forgetCharts(name string) {
+	rm.cacheMutex.Lock()
+	defer rm.cacheMutex.Unlock()
+	delete(rm.chartCache, name)
+}
+
+// WarmCache fetches the charts of every repository into the chart cache, at
+// most concurrency repositories at a time (defaultWarmConcurrency when zero
+// or less), so the first searches are fast. Results are sorted by
+// repository name.
+func (rm *RepositoryManager) WarmCache(concurrency int) []WarmResult {
+	if concurrency <= 0 {
+		concurrency = defaultWarmConcurrency
+	}
+
+	// Fetch without holding the lock, so adding or removing repositories
+	// and the searches queued behind them don't wait for slow indexes
+	rm.mutex.RLock()
+	repos := make([]*models.Repository, 0, len(rm.repositories))
+	for _, repo := range rm.repositories {
+		repos = append(repos, repo)
+	}
+	rm.mutex.RUnlock()
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Name < repos[j].Name })
+
+	results := make([]WarmResult, len(repos))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo *models.Repository) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// Refetch even when cached so warming refreshes stale lists
+			start := time.Now()
+			charts, err := rm.fetchChartsUncached(repo)
+			result := WarmResult{Repository: repo.Name, Charts: len(charts)}
+			if err == nil {
+				if rm.isRegistered(repo) {
+					rm.storeCharts(repo.Name, charts)
+				}
+			} else {
+				result.Error = err.Error()
+			}
+			result.DurationMS = time.Since(start).Milliseconds()
+			results[i] = result
+		}(i, repo)
+	}
+	wg.Wait()
+	return results
+}
+
+// isRegistered reports whether repo is still the registered repository of
+// its name, i.e. it was neither removed nor replaced meanwhile.
+func (rm *RepositoryManager) isRegistered(repo *models.Repository) bool {
+	rm.mutex.RLock()
+	defer rm.mutex.RUnlock()
+	return rm.repositories[repo.Name] == repo
+}
@@ -0,0 +1,155 @@
+package helm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestWarmCache(t *testing.T) {
+	var fetches int32
+	index := func(body string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&fetches, 1)
+			w.Write([]byte(body))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	first := index(sampleIndex)
+	second := index("apiVersion: v1\nentries:\n  redis:\n  - name: redis\n    version: 18.0.0\n")
+	broken := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(broken.Close)
+
+	rm := &RepositoryManager{
+		repositories: map[string]*models.Repository{
+			"first":  {Name: "first", URL: first.URL, Type: "http"},
+			"second": {Name: "second", URL: second.URL, Type: "http"},
+			"broken": {Name: "broken", URL: broken.URL, Type: "http"},
+		},
+		helmHome:      t.TempDir(),
+		chartCacheTTL: time.Minute,
+	}
+	rm.runHelm = func(args ...string) ([]byte, error) { return nil, ErrHelmUnavailable }
+
+	results := rm.WarmCache(2)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	want := []struct {
+		repository string
+		charts     int
+		failed     bool
+	}{
+		{"broken", 0, true},
+		{"first", 2, false},
+		{"second", 1, false},
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.Repository != w.repository || got.Charts != w.charts || (got.Error != "") != w.failed {
+			t.Errorf("results[%d] = %+v, want %s with %d charts (failed %v)", i, got, w.repository, w.charts, w.failed)
+		}
+		if got.DurationMS < 0 {
+			t.Errorf("results[%d] has negative duration %d", i, got.DurationMS)
+		}
+	}
+
+	// Searches are now served from the cache without fetching the indexes
+	fetched := atomic.LoadInt32(&fetches)
+	for name, count := range map[string]int{"first": 2, "second": 1} {
+		charts, err := rm.GetRepositoryCharts(name)
+		if err != nil {
+			t.Fatalf("GetRepositoryCharts(%s) failed: %v", name, err)
+		}
+		if len(charts) != count {
+			t.Errorf("Expected %d charts for %s, got %d", count, name, len(charts))
+		}
+	}
+	if got := atomic.LoadInt32(&fetches); got != fetched {
+		t.Errorf("Expected searches to use the cache, but %d indexes were fetched", got-fetched)
+	}
+
+	// Changes made by callers don't leak into the cache
+	charts, _ := rm.GetRepositoryCharts("second")
+	charts[0].Name = "changed"
+	if charts, _ := rm.GetRepositoryCharts("second"); charts[0].Name != "redis" {
+		t.Errorf("Expected cached chart to be unchanged, got %s", charts[0].Name)
+	}
+
+	// Removing a repository drops its cached charts
+	if err := rm.RemoveRepository("first"); err != nil {
+		t.Fatalf("RemoveRepository failed: %v", err)
+	}
+	if _, ok := rm.cachedCharts("first"); ok {
+		t.Error("Expected cached charts of a removed repository to be dropped")
+	}
+}
+
+func TestWarmCacheDoesNotBlockChanges(t *testing.T) {
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-release
+		w.Write([]byte(sampleIndex))
+	}))
+	t.Cleanup(server.Close)
+
+	rm := &RepositoryManager{
+		repositories: map[string]*models.Repository{
+			"slow": {Name: "slow", URL: server.URL, Type: "http"},
+		},
+		helmHome:      t.TempDir(),
+		chartCacheTTL: time.Minute,
+	}
+	rm.runHelm = func(args ...string) ([]byte, error) { return nil, ErrHelmUnavailable }
+
+	warmed := make(chan []WarmResult)
+	go func() { warmed <- rm.WarmCache(1) }()
+	<-requested
+
+	// Removing a repository doesn't wait for its index to download
+	removed := make(chan error)
+	go func() { removed <- rm.RemoveRepository("slow") }()
+	select {
+	case err := <-removed:
+		if err != nil {
+			t.Fatalf("RemoveRepository failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		close(release)
+		t.Fatal("RemoveRepository blocked while the cache was warming")
+	}
+	close(release)
+
+	if results := <-warmed; len(results) != 1 || results[0].Error != "" {
+		t.Fatalf("Unexpected warm results: %+v", results)
+	}
+	if _, ok := rm.cachedCharts("slow"); ok {
+		t.Error("Expected charts of a repository removed while warming not to be cached")
+	}
+}
+
+func TestChartCacheExpires(t *testing.T) {
+	rm := &RepositoryManager{chartCacheTTL: 10 * time.Millisecond}
+	rm.storeCharts("repo", []*models.Chart{{Name: "nginx"}})
+	if _, ok := rm.cachedCharts("repo"); !ok {
+		t.Fatal("Expected fresh charts to be cached")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := rm.cachedCharts("repo"); ok {
+		t.Error("Expected charts to expire after the TTL")
+	}
+
+	rm = &RepositoryManager{}
+	rm.storeCharts("repo", []*models.Chart{{Name: "nginx"}})
+	if _, ok := rm.cachedCharts("repo"); ok {
+		t.Error("Expected no caching with a zero TTL")
+	}
+}
@@ -0,0 +1,87 @@
+package helm
+
+import (
+	"os"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseValueComments returns the comments documenting each key of the
+// chart's values.yaml, keyed by dotted path. Comments are best effort: a
+// missing or unparsable file yields no descriptions rather than an error.
+func (p *Processor) parseValueComments(chartDir string) map[string]string {
+	valuesPath := p.findFile(chartDir, "values.yaml")
+	if valuesPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(valuesPath)
+	if err != nil {
+		return nil
+	}
+	return valueComments(data)
+}
+
+// valueComments extracts the head and line comments attached to each mapping
+// key in a YAML document.
+func valueComments(data []byte) map[string]string {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+
+	comments := make(map[string]string)
+	collectComments(comments, doc.Content[0], "")
+	return comments
+}
+
+func collectComments(comments map[string]string, node *yaml.Node, prefix string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+
+		lineComment := key.LineComment
+		if lineComment == "" && value.Kind == yaml.ScalarNode {
+			lineComment = value.LineComment
+		}
+		if text := joinComments(key.HeadComment, lineComment); text != "" {
+			comments[path] = text
+		}
+		collectComments(comments, value, path)
+	}
+}
+
+// joinComments strips comment markers from the given comment blocks and joins
+// their lines with spaces. The "--" marker used by helm-docs is dropped too.
+func joinComments(blocks ...string) string {
+	var parts []string
+	for _, block := range blocks {
+		for _, line := range strings.Split(block, "\n") {
+			line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+			line = strings.TrimSpace(strings.TrimPrefix(line, "--"))
+			if line != "" {
+				parts = append(parts, line)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// applyValueComments fills in empty descriptions, including on subquestions,
+// from the values.yaml comments for each question's variable.
+func applyValueComments(questions []models.Question, comments map[string]string) {
+	for i := range questions {
+		if questions[i].Description == "" {
+			questions[i].Description = comments[questions[i].Variable]
+		}
+		applyValueComments(questions[i].SubQuestions, comments)
+	}
+}
@@ -0,0 +1,61 @@
+package helm
+
+import "testing"
+
+const commentedValues = `# Number of pods to run
+replicaCount: 1
+
+image:
+  # Container image repository.
+  # Override to use a mirror.
+  repository: nginx
+  tag: "1.25" # Image tag, defaults to appVersion
+  ## -- Pull policy for the image
+  pullPolicy: IfNotPresent
+
+service:
+  port: 80
+`
+
+func TestValueComments(t *testing.T) {
+	comments := valueComments([]byte(commentedValues))
+
+	expected := map[string]string{
+		"replicaCount":     "Number of pods to run",
+		"image.repository": "Container image repository. Override to use a mirror.",
+		"image.tag":        "Image tag, defaults to appVersion",
+		"image.pullPolicy": "Pull policy for the image",
+	}
+	for path, want := range expected {
+		if got := comments[path]; got != want {
+			t.Errorf("comments[%q] = %q, want %q", path, got, want)
+		}
+	}
+	if got, ok := comments["service.port"]; ok {
+		t.Errorf("Expected no comment for service.port, got %q", got)
+	}
+}
+
+func TestProcessChartDescriptionsFromComments(t *testing.T) {
+	server := newChartServer(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": commentedValues,
+	})
+
+	result, err := NewProcessor().ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+
+	questions := result.Questions.Questions
+	if q := findQuestion(questions, "image.repository"); q == nil || q.Description != "Container image repository. Override to use a mirror." {
+		t.Errorf("Unexpected image.repository question: %+v", q)
+	}
+	if q := findQuestion(questions, "replicaCount"); q == nil || q.Description != "Number of pods to run" {
+		t.Errorf("Unexpected replicaCount question: %+v", q)
+	}
+	// Hand-written descriptions are kept
+	if q := findQuestion(questions, "namespace"); q == nil || q.Description != "Kubernetes namespace for the application" {
+		t.Errorf("Unexpected namespace question: %+v", q)
+	}
+}
@@ -0,0 +1,53 @@
+package helm
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envBool reads a boolean environment variable, returning fallback when it is
+// unset or unparsable.
+func envBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// envInt reads an integer environment variable, returning fallback when it is
+// unset or unparsable.
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// envDuration reads a duration environment variable such as "30m", returning
+// fallback when it is unset or unparsable.
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// envList reads a comma-separated environment variable, trimming spaces and
+// dropping empty entries, and returns fallback when it is unset or empty.
+func envList(key string, fallback []string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return fallback
+	}
+	return list
+}
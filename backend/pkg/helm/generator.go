@@ -0,0 +1,598 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// requiredWhenEmptyKeys are key names that almost always need a value before
+// a chart can be installed. Matching is case-insensitive on the last path
+// segment; the list is deliberately short to keep false positives low.
+var requiredWhenEmptyKeys = map[string]bool{
+	"host":         true,
+	"hostname":     true,
+	"domain":       true,
+	"password":     true,
+	"licensekey":   true,
+	"license":      true,
+	"apikey":       true,
+	"apitoken":     true,
+	"token":        true,
+	"accesskey":    true,
+	"secretkey":    true,
+	"clientsecret": true,
+}
+
+const (
+	// cronPattern accepts five-field cron expressions and the @-macros.
+	cronPattern = `^(@(yearly|annually|monthly|weekly|daily|hourly)|([0-9*,/?LW#A-Za-z-]+\s+){4}[0-9*,/?LW#A-Za-z-]+)$`
+	// hostnamePattern accepts DNS-1123 hostnames with an optional wildcard label.
+	hostnamePattern = `^(\*\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// quantityPattern accepts Kubernetes resource quantities such as 500m or 2Gi.
+	quantityPattern = `^\d+(\.\d+)?(m|Mi|Gi|Ki|Ti|Pi|Ei|k|M|G|T|P|E)?$`
+)
+
+// knownEnum describes a Kubernetes field with a fixed set of values.
+type knownEnum struct {
+	options      []string
+	defaultValue string
+}
+
+// knownEnums maps lower-cased key names to the Kubernetes enumerations they
+// conventionally hold, so e.g. `image.pullPolicy` becomes a dropdown.
+var knownEnums = map[string]knownEnum{
+	"pullpolicy":      {options: []string{"Always", "IfNotPresent", "Never"}, defaultValue: "IfNotPresent"},
+	"imagepullpolicy": {options: []string{"Always", "IfNotPresent", "Never"}, defaultValue: "IfNotPresent"},
+	"dnspolicy":       {options: []string{"ClusterFirst", "ClusterFirstWithHostNet", "Default", "None"}, defaultValue: "ClusterFirst"},
+	"restartpolicy":   {options: []string{"Always", "OnFailure", "Never"}, defaultValue: "Always"},
+	"protocol":        {options: []string{"TCP", "UDP", "SCTP"}, defaultValue: "TCP"},
+}
+
+// numericRange describes a numeric field with natural bounds.
+type numericRange struct {
+	questionType string
+	min, max     int
+}
+
+var (
+	// percentRange bounds utilization targets, where 0% would never scale.
+	percentRange = numericRange{questionType: "int", min: 1, max: 100}
+	// ratioRange bounds sampling rates and other fractions.
+	ratioRange = numericRange{questionType: "float", min: 0, max: 1}
+)
+
+// knownNumericRanges maps lower-cased key names to their bounds, so e.g.
+// `autoscaling.targetCPUUtilizationPercentage` can't be set to 500.
+var knownNumericRanges = map[string]numericRange{
+	"targetcpuutilizationpercentage":    percentRange,
+	"targetmemoryutilizationpercentage": percentRange,
+	"samplerate":                        ratioRange,
+	"samplingrate":                      ratioRange,
+	"samplingratio":                     ratioRange,
+	"tracessamplerate":                  ratioRange,
+}
+
+var (
+	cronKeys     = map[string]bool{"schedule": true, "cron": true, "cronschedule": true, "cronjob": true}
+	hostnameKeys = map[string]bool{"host": true, "hostname": true, "domain": true, "fqdn": true}
+)
+
+// valueQuestions walks values recursively and emits a question for every
+// scalar leaf and array, keyed by its dotted path.
+func (p *Processor) valueQuestions(values map[string]interface{}, path []string) []models.Question {
+	var questions []models.Question
+
+	for _, key := range sortedKeys(values) {
+		value := values[key]
+		keyPath := append(append([]string(nil), path...), key)
+
+		if isSchedulingValue(key, value) {
+			questions = append(questions, schedulingQuestion(keyPath, value))
+			continue
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			var block []models.Question
+			group := humanizePath(keyPath)
+			if isImageBlock(v) {
+				block = append(block, imageQuestions(keyPath, v)...)
+				v = withoutKeys(v, imageKeys...)
+			}
+			if probeKeys[strings.ToLower(key)] {
+				block = append(block, probeQuestions(p.valueQuestions(v, keyPath), v)...)
+				group = healthChecksGroup
+			} else {
+				block = append(block, p.valueQuestions(v, keyPath)...)
+			}
+			if isFeatureBlock(v) {
+				block = featureQuestions(keyPath, group, block)
+			}
+			questions = append(questions, block...)
+		case []interface{}:
+			questions = append(questions, arrayQuestion(keyPath, v))
+		default:
+			question := leafQuestion(keyPath, v)
+			if question.Type == "secret" {
+				question.Namespace = secretNamespace(values, key)
+			}
+			questions = append(questions, question)
+		}
+	}
+
+	return questions
+}
+
+// leafQuestion builds the question for a single scalar value.
+func leafQuestion(path []string, value interface{}) models.Question {
+	key := path[len(path)-1]
+
+	question := pathQuestion(path)
+	question.Type = inferQuestionType(key, value)
+	question.Required = isRequiredWhenEmpty(key, value)
+	if value != nil && value != "" {
+		question.Default = value
+	}
+	if enum, ok := lookupKnownEnum(key, value); ok {
+		question.Type = "enum"
+		question.Options = enum.options
+		question.Default = enum.defaultFor(value)
+	}
+	if bounds, ok := lookupNumericRange(key, value); ok {
+		// A fractional percentage stays a float rather than being truncated
+		if question.Type != "float" {
+			question.Type = bounds.questionType
+		}
+		question.Min = &bounds.min
+		question.Max = &bounds.max
+	}
+	question.ValidChars = inferValidChars(question.Type)
+
+	if isResourceQuantity(path) {
+		// Quantities like `cpu: 2` parse as numbers but are strings to Kubernetes
+		question.Type = "string"
+		question.Group = "Resources"
+		question.ValidChars = quantityPattern
+		if question.Default != nil {
+			question.Default = fmt.Sprint(question.Default)
+		}
+	}
+
+	return question
+}
+
+// imageKeys are the keys of a conventional container image block, in the
+// order their questions are emitted.
+var imageKeys = []string{"repository", "tag", "pullPolicy"}
+
+// isImageBlock reports whether values looks like a container image block: a
+// repository alongside a tag or pull policy.
+func isImageBlock(values map[string]interface{}) bool {
+	if _, ok := values["repository"].(string); !ok {
+		return false
+	}
+	_, hasTag := values["tag"]
+	_, hasPolicy := values["pullPolicy"]
+	return hasTag || hasPolicy
+}
+
+// imageQuestions builds the repository, tag and pull policy questions for the
+// image block at path as one group, named after the block's full path so
+// charts with several images get a group per image. Descriptions are left to
+// the chart's own comments.
+func imageQuestions(path []string, image map[string]interface{}) []models.Question {
+	group := humanizePath(path)
+	variable := strings.Join(path, ".")
+
+	repository := models.Question{
+		Variable: variable + ".repository",
+		Label:    "Image Repository",
+		Type:     "string",
+		Required: true,
+		Group:    group,
+	}
+	if image["repository"] != "" {
+		repository.Default = image["repository"]
+	}
+
+	// Tags such as 1.25 parse as numbers but must stay strings
+	tag := models.Question{
+		Variable: variable + ".tag",
+		Label:    "Image Tag",
+		Type:     "string",
+		Group:    group,
+	}
+	if value, ok := image["tag"]; ok && value != nil && value != "" {
+		tag.Default = fmt.Sprint(value)
+	}
+
+	enum := knownEnums["pullpolicy"]
+	pullPolicy := models.Question{
+		Variable: variable + ".pullPolicy",
+		Label:    "Image Pull Policy",
+		Type:     "enum",
+		Options:  enum.options,
+		Default:  enum.defaultFor(image["pullPolicy"]),
+		Group:    group,
+	}
+
+	return []models.Question{repository, tag, pullPolicy}
+}
+
+// isFeatureBlock reports whether values is a feature gated by a boolean
+// `enabled` key, with other settings beside it.
+func isFeatureBlock(values map[string]interface{}) bool {
+	_, ok := values["enabled"].(bool)
+	return ok && len(values) > 1
+}
+
+// featureQuestions turns the questions generated for a feature block into a
+// toggle followed by the feature's settings, all in group except scheduling
+// settings, with the settings shown only while the toggle is on. Settings of
+// nested features and scheduling settings keep their own condition as well.
+func featureQuestions(path []string, group string, questions []models.Question) []models.Question {
+	toggleVariable := strings.Join(append(append([]string(nil), path...), "enabled"), ".")
+	condition := toggleVariable + "=true"
+
+	feature := make([]models.Question, 0, len(questions))
+	var settings []models.Question
+	for _, q := range questions {
+		// Scheduling settings stay with the other advanced questions
+		if q.Group != schedulingGroup {
+			q.Group = group
+		}
+		if q.Variable == toggleVariable {
+			q.Type = "boolean"
+			q.Label = "Enable " + humanizePath(path)
+			feature = append(feature, q)
+			continue
+		}
+		if q.ShowIf == "" {
+			q.ShowIf = condition
+		} else {
+			q.ShowIf = condition + "&&" + q.ShowIf
+		}
+		// Credentials are only needed while the feature using them is on
+		if q.Required && (q.Type == "password" || q.Type == "secret") {
+			q.Required = false
+			q.RequiredIf = condition
+		} else if q.RequiredIf != "" {
+			q.RequiredIf = condition + "&&" + q.RequiredIf
+		}
+		settings = append(settings, q)
+	}
+	return append(feature, settings...)
+}
+
+// healthChecksGroup collects the questions of all probe blocks.
+const healthChecksGroup = "Health Checks"
+
+// probeKeys are the lower-cased names of container probe blocks.
+var probeKeys = map[string]bool{"livenessprobe": true, "readinessprobe": true, "startupprobe": true}
+
+// probeTimingMins maps probe timing fields to the smallest value Kubernetes
+// accepts for them.
+var probeTimingMins = map[string]int{
+	"initialDelaySeconds":           0,
+	"periodSeconds":                 1,
+	"timeoutSeconds":                1,
+	"successThreshold":              1,
+	"failureThreshold":              1,
+	"terminationGracePeriodSeconds": 0,
+}
+
+// probeQuestions gathers the questions generated for a probe block under a
+// single healthChecksGroup, labelled with the probe's full path so
+// liveness and readiness settings stay distinguishable. Timing fields become
+// ints with their Kubernetes minimum.
+func probeQuestions(questions []models.Question, probe map[string]interface{}) []models.Question {
+	for i := range questions {
+		q := &questions[i]
+		path := strings.Split(q.Variable, ".")
+		q.Group = healthChecksGroup
+		q.Label = humanizePath(path)
+
+		field := path[len(path)-1]
+		min, timing := probeTimingMins[field]
+		if !timing || (q.Type != "int" && probe[field] != nil) {
+			continue
+		}
+		q.Type = "int"
+		q.Min = &min
+	}
+	return questions
+}
+
+// humanizePath joins the humanized segments of path, e.g. "Ollama Image".
+func humanizePath(path []string) string {
+	labels := make([]string, len(path))
+	for i, segment := range path {
+		labels[i] = humanizeKey(segment)
+	}
+	return strings.Join(labels, " ")
+}
+
+// seedImageTag defaults an empty image.tag question to the chart's
+// appVersion, which is the tag most charts fall back to in their templates.
+// Only the top-level image block is seeded; nested images belong to other
+// components with their own versions.
+func seedImageTag(questions []models.Question, appVersion string) {
+	if appVersion == "" {
+		return
+	}
+	for i := range questions {
+		if questions[i].Variable == "image.tag" && (questions[i].Default == nil || questions[i].Default == "") {
+			questions[i].Default = appVersion
+		}
+	}
+}
+
+// withoutKeys returns a copy of values without the given keys.
+func withoutKeys(values map[string]interface{}, keys ...string) map[string]interface{} {
+	rest := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		rest[key] = value
+	}
+	for _, key := range keys {
+		delete(rest, key)
+	}
+	return rest
+}
+
+// pathQuestion returns a question with the variable, label and group derived
+// from path: the first segment names the group, the rest form the label.
+func pathQuestion(path []string) models.Question {
+	group := "General"
+	labelPath := path
+	if len(path) > 1 {
+		group = humanizeKey(path[0])
+		labelPath = path[1:]
+	}
+	labels := make([]string, len(labelPath))
+	for i, segment := range labelPath {
+		labels[i] = humanizeKey(segment)
+	}
+
+	return models.Question{
+		Variable: strings.Join(path, "."),
+		Label:    strings.Join(labels, " "),
+		Group:    group,
+	}
+}
+
+// arrayQuestion builds the question for a list value. Lists of scalars become
+// a comma-separated string; lists containing maps or nested lists can't be
+// expressed that way, so they are offered as a multiline YAML block instead
+// of being dropped.
+func arrayQuestion(path []string, items []interface{}) models.Question {
+	question := pathQuestion(path)
+
+	if !isScalarList(items) {
+		question.Type = "multiline"
+		question.Description = fmt.Sprintf("List of %d item(s), edited as YAML", len(items))
+		if out, err := yaml.Marshal(items); err == nil {
+			question.Default = strings.TrimSuffix(string(out), "\n")
+		}
+		return question
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = fmt.Sprint(item)
+	}
+	question.Type = "string"
+	question.Description = "Comma-separated list"
+	if len(parts) > 0 {
+		question.Default = strings.Join(parts, ",")
+	}
+	return question
+}
+
+// isScalarList reports whether items holds no maps or nested lists.
+func isScalarList(items []interface{}) bool {
+	for _, item := range items {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+// inferQuestionType picks the Rancher question type for a values key based on
+// its Go value and, for strings, its name.
+func inferQuestionType(key string, value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "int"
+	case float64:
+		if v == float64(int64(v)) {
+			return "int"
+		}
+		return "float"
+	}
+
+	lower := strings.ToLower(key)
+	switch {
+	case strings.HasSuffix(lower, "secretname"):
+		return "secret"
+	case strings.Contains(lower, "password") || strings.HasSuffix(lower, "secret"):
+		return "password"
+	case lower == "storageclass" || lower == "storageclassname":
+		return "storageclass"
+	case cronKeys[lower]:
+		return "cron"
+	case hostnameKeys[lower]:
+		return "hostname"
+	}
+
+	return "string"
+}
+
+// secretNamespace returns the namespace set beside a secret name key, e.g.
+// tls.secretNamespace for tls.secretName, or "" when there is none.
+func secretNamespace(values map[string]interface{}, key string) string {
+	namespace, _ := values[key[:len(key)-len("Name")]+"Namespace"].(string)
+	return namespace
+}
+
+// isResourceQuantity reports whether path names a container resource request
+// or limit, e.g. resources.limits.memory or ollama.resources.requests.cpu.
+func isResourceQuantity(path []string) bool {
+	if len(path) < 3 {
+		return false
+	}
+	parent := strings.ToLower(path[len(path)-2])
+	return strings.EqualFold(path[len(path)-3], "resources") && (parent == "requests" || parent == "limits")
+}
+
+// lookupKnownEnum returns the Kubernetes enumeration for key when the value
+// is a string (or unset) and so could plausibly hold one of its options.
+func lookupKnownEnum(key string, value interface{}) (knownEnum, bool) {
+	switch value.(type) {
+	case string, nil:
+	default:
+		return knownEnum{}, false
+	}
+	enum, ok := knownEnums[strings.ToLower(key)]
+	return enum, ok
+}
+
+// lookupNumericRange returns the bounds for key when the value is a number
+// (or unset). Besides the known keys, names ending in "Percentage" or
+// "Percent" are 0-100 and names ending in "Ratio" are 0-1.
+func lookupNumericRange(key string, value interface{}) (numericRange, bool) {
+	switch value.(type) {
+	case int, int64, uint64, float64, nil:
+	default:
+		return numericRange{}, false
+	}
+
+	lower := strings.ToLower(key)
+	if bounds, ok := knownNumericRanges[lower]; ok {
+		return bounds, true
+	}
+	switch {
+	case strings.HasSuffix(lower, "percentage") || strings.HasSuffix(lower, "percent"):
+		return numericRange{questionType: "int", min: 0, max: 100}, true
+	case strings.HasSuffix(lower, "ratio"):
+		return ratioRange, true
+	}
+	return numericRange{}, false
+}
+
+// defaultFor returns the option matching value, ignoring case, or the
+// enumeration's own default when value is empty or not a valid option.
+func (e knownEnum) defaultFor(value interface{}) string {
+	if s, ok := value.(string); ok {
+		for _, option := range e.options {
+			if strings.EqualFold(option, s) {
+				return option
+			}
+		}
+	}
+	return e.defaultValue
+}
+
+// inferValidChars returns the validation regex Rancher should apply for a
+// question type, for types whose format we can check client-side.
+func inferValidChars(questionType string) string {
+	switch questionType {
+	case "cron":
+		return cronPattern
+	case "hostname":
+		return hostnamePattern
+	}
+	return ""
+}
+
+// isRequiredWhenEmpty reports whether an empty value for key must be filled in
+// before install, e.g. `ingress.host: ""` or `auth.password: ""`.
+func isRequiredWhenEmpty(key string, value interface{}) bool {
+	if value != nil && value != "" {
+		return false
+	}
+
+	lower := strings.ToLower(key)
+	return requiredWhenEmptyKeys[lower] || strings.HasSuffix(lower, "password")
+}
+
+// commonTopLevelKeys and commonLeafKeys name the values users most often
+// change; questions under them survive truncation first.
+var (
+	commonTopLevelKeys = map[string]bool{
+		"image": true, "replicacount": true, "service": true, "ingress": true,
+		"persistence": true, "resources": true, "auth": true,
+	}
+	commonLeafKeys = map[string]bool{
+		"enabled": true, "repository": true, "tag": true, "pullpolicy": true, "type": true,
+		"port": true, "host": true, "hostname": true, "storageclass": true, "size": true,
+		"password": true, "existingsecret": true,
+	}
+)
+
+// limitQuestions keeps at most max questions, preferring commonly configured
+// keys and then shallower paths, and reports whether any were dropped. The
+// kept questions stay in their original order. A max of zero or less keeps
+// everything.
+func limitQuestions(questions []models.Question, max int) ([]models.Question, bool) {
+	if max <= 0 || len(questions) <= max {
+		return questions, false
+	}
+
+	ranked := make([]int, len(questions))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return questionPriority(questions[ranked[a]]) < questionPriority(questions[ranked[b]])
+	})
+
+	keep := make([]bool, len(questions))
+	for _, idx := range ranked[:max] {
+		keep[idx] = true
+	}
+
+	limited := make([]models.Question, 0, max)
+	for i, q := range questions {
+		if keep[i] {
+			limited = append(limited, q)
+		}
+	}
+	return limited, true
+}
+
+// questionPriority ranks a question for truncation; lower values are kept
+// first. Depth dominates, and common keys move up one level.
+func questionPriority(q models.Question) int {
+	segments := strings.Split(strings.ToLower(q.Variable), ".")
+	priority := 2 * len(segments)
+	if commonTopLevelKeys[segments[0]] || commonLeafKeys[segments[len(segments)-1]] {
+		priority -= 3
+	}
+	return priority
+}
+
+// appendMissingQuestions appends the questions from extra whose variables are
+// not already present in questions.
+func appendMissingQuestions(questions []models.Question, extra []models.Question) []models.Question {
+	seen := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		seen[q.Variable] = true
+	}
+
+	for _, q := range extra {
+		if !seen[q.Variable] {
+			seen[q.Variable] = true
+			questions = append(questions, q)
+		}
+	}
+	return questions
+}
@@ -0,0 +1,608 @@
+package helm
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+func findQuestion(questions []models.Question, variable string) *models.Question {
+	for i := range questions {
+		if questions[i].Variable == variable {
+			return &questions[i]
+		}
+	}
+	return nil
+}
+
+func TestValueQuestionsWalksNestedValues(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"replicaCount": 3,
+		"ollama": map[string]interface{}{
+			"gpu": map[string]interface{}{
+				"enabled": false,
+			},
+			"models": []interface{}{"llama2"},
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{
+					"cpu": "2",
+				},
+			},
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	tests := []struct {
+		variable string
+		qType    string
+		group    string
+	}{
+		{"replicaCount", "int", "General"},
+		{"ollama.gpu.enabled", "boolean", "Ollama"},
+		{"ollama.resources.requests.cpu", "string", "Resources"},
+	}
+	for _, tt := range tests {
+		q := findQuestion(questions, tt.variable)
+		if q == nil {
+			t.Errorf("Expected question for %s", tt.variable)
+			continue
+		}
+		if q.Type != tt.qType {
+			t.Errorf("Expected %s to be %s, got %s", tt.variable, tt.qType, q.Type)
+		}
+		if q.Group != tt.group {
+			t.Errorf("Expected %s in group %s, got %s", tt.variable, tt.group, q.Group)
+		}
+	}
+
+	if q := findQuestion(questions, "ollama.gpu.enabled"); q != nil && q.Label != "Gpu Enabled" {
+		t.Errorf("Unexpected label %q", q.Label)
+	}
+}
+
+func TestRequiredWhenEmpty(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"ingress": map[string]interface{}{
+			"host": "",
+		},
+		"auth": map[string]interface{}{
+			"password":      "",
+			"adminPassword": nil,
+		},
+		"image": map[string]interface{}{
+			"tag": "",
+		},
+		"server": map[string]interface{}{
+			"host": "example.com",
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	expected := map[string]bool{
+		"ingress.host":       true,
+		"auth.password":      true,
+		"auth.adminPassword": true,
+		"image.tag":          false,
+		"server.host":        false,
+	}
+	for variable, required := range expected {
+		q := findQuestion(questions, variable)
+		if q == nil {
+			t.Errorf("Expected question for %s", variable)
+			continue
+		}
+		if q.Required != required {
+			t.Errorf("Expected %s required=%v, got %v", variable, required, q.Required)
+		}
+	}
+}
+
+func TestCronAndHostnameQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"backup": map[string]interface{}{
+			"schedule": "0 2 * * *",
+		},
+		"ingress": map[string]interface{}{
+			"hostname": "app.example.com",
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	schedule := findQuestion(questions, "backup.schedule")
+	if schedule == nil || schedule.Type != "cron" {
+		t.Fatalf("Expected backup.schedule to be a cron question, got %+v", schedule)
+	}
+	hostname := findQuestion(questions, "ingress.hostname")
+	if hostname == nil || hostname.Type != "hostname" {
+		t.Fatalf("Expected ingress.hostname to be a hostname question, got %+v", hostname)
+	}
+
+	cron := regexp.MustCompile(schedule.ValidChars)
+	for _, valid := range []string{"0 2 * * *", "*/15 * * * 1-5", "@daily"} {
+		if !cron.MatchString(valid) {
+			t.Errorf("Expected cron pattern to accept %q", valid)
+		}
+	}
+	for _, invalid := range []string{"every day", "0 2 * *"} {
+		if cron.MatchString(invalid) {
+			t.Errorf("Expected cron pattern to reject %q", invalid)
+		}
+	}
+
+	dns := regexp.MustCompile(hostname.ValidChars)
+	for _, valid := range []string{"app.example.com", "*.example.com", "localhost"} {
+		if !dns.MatchString(valid) {
+			t.Errorf("Expected hostname pattern to accept %q", valid)
+		}
+	}
+	for _, invalid := range []string{"-bad.example.com", "under_score.com", "has space"} {
+		if dns.MatchString(invalid) {
+			t.Errorf("Expected hostname pattern to reject %q", invalid)
+		}
+	}
+
+	if plain := findQuestion(questions, "name"); plain != nil && plain.ValidChars != "" {
+		t.Errorf("Expected no validation on plain strings, got %q", plain.ValidChars)
+	}
+}
+
+func TestKnownKubernetesEnums(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"image": map[string]interface{}{
+			"pullPolicy": "always",
+		},
+		"pod": map[string]interface{}{
+			"dnsPolicy":     "",
+			"restartPolicy": "Sometimes",
+		},
+		"ports": map[string]interface{}{
+			"protocol": "UDP",
+		},
+		"metrics": map[string]interface{}{
+			"protocol": 9090,
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	tests := []struct {
+		variable     string
+		options      []string
+		defaultValue string
+	}{
+		{"image.pullPolicy", []string{"Always", "IfNotPresent", "Never"}, "Always"},
+		{"pod.dnsPolicy", []string{"ClusterFirst", "ClusterFirstWithHostNet", "Default", "None"}, "ClusterFirst"},
+		{"pod.restartPolicy", []string{"Always", "OnFailure", "Never"}, "Always"},
+		{"ports.protocol", []string{"TCP", "UDP", "SCTP"}, "UDP"},
+	}
+
+	for _, tt := range tests {
+		q := findQuestion(questions, tt.variable)
+		if q == nil {
+			t.Fatalf("Expected question for %s", tt.variable)
+		}
+		if q.Type != "enum" {
+			t.Errorf("Expected %s to be an enum, got %q", tt.variable, q.Type)
+		}
+		if strings.Join(q.Options, ",") != strings.Join(tt.options, ",") {
+			t.Errorf("Expected %s options %v, got %v", tt.variable, tt.options, q.Options)
+		}
+		if q.Default != tt.defaultValue {
+			t.Errorf("Expected %s default %q, got %v", tt.variable, tt.defaultValue, q.Default)
+		}
+	}
+
+	if q := findQuestion(questions, "metrics.protocol"); q == nil || q.Type != "int" {
+		t.Errorf("Expected non-string protocol to stay an int question, got %+v", q)
+	}
+}
+
+func TestNumericRangeQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"autoscaling": map[string]interface{}{
+			"enabled":                           false,
+			"targetCPUUtilizationPercentage":    80,
+			"targetMemoryUtilizationPercentage": nil,
+		},
+		"tracing": map[string]interface{}{
+			"sampleRate": 1,
+		},
+		"cache": map[string]interface{}{
+			"evictionPercent": 12.5,
+			"hitRatio":        0.9,
+			"fillPercentage":  "50%",
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	tests := []struct {
+		variable     string
+		questionType string
+		min, max     int
+	}{
+		{"autoscaling.targetCPUUtilizationPercentage", "int", 1, 100},
+		{"autoscaling.targetMemoryUtilizationPercentage", "int", 1, 100},
+		{"tracing.sampleRate", "float", 0, 1},
+		{"cache.evictionPercent", "float", 0, 100},
+		{"cache.hitRatio", "float", 0, 1},
+	}
+	for _, tt := range tests {
+		q := findQuestion(questions, tt.variable)
+		if q == nil {
+			t.Fatalf("Expected question for %s", tt.variable)
+		}
+		if q.Type != tt.questionType {
+			t.Errorf("Expected %s to be %s, got %q", tt.variable, tt.questionType, q.Type)
+		}
+		if q.Min == nil || q.Max == nil || *q.Min != tt.min || *q.Max != tt.max {
+			t.Errorf("Expected %s bounds %d-%d, got min %v max %v", tt.variable, tt.min, tt.max, q.Min, q.Max)
+		}
+	}
+
+	// Strings such as "50%" aren't numbers and get no bounds
+	if q := findQuestion(questions, "cache.fillPercentage"); q == nil || q.Type != "string" || q.Min != nil || q.Max != nil {
+		t.Errorf("Expected a string percentage to stay unbounded, got %+v", q)
+	}
+}
+
+func TestArrayQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"ollama": map[string]interface{}{
+			"models": []interface{}{"llama2", "mistral"},
+		},
+		"ingress": map[string]interface{}{
+			"hosts": []interface{}{
+				map[string]interface{}{"host": "chart.local", "paths": []interface{}{"/"}},
+			},
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	list := findQuestion(questions, "ollama.models")
+	if list == nil {
+		t.Fatal("Expected a question for ollama.models")
+	}
+	if list.Type != "string" || list.Default != "llama2,mistral" {
+		t.Errorf("Expected comma-separated string question, got type %q default %v", list.Type, list.Default)
+	}
+	if !strings.Contains(list.Description, "Comma-separated") {
+		t.Errorf("Expected description to document the format, got %q", list.Description)
+	}
+
+	hosts := findQuestion(questions, "ingress.hosts")
+	if hosts == nil {
+		t.Fatal("Expected a question for ingress.hosts")
+	}
+	if hosts.Type != "multiline" || hosts.Group != "Ingress" {
+		t.Errorf("Expected multiline question in Ingress group, got type %q group %q", hosts.Type, hosts.Group)
+	}
+	if def, _ := hosts.Default.(string); !strings.Contains(def, "host: chart.local") {
+		t.Errorf("Expected YAML default for ingress.hosts, got %v", hosts.Default)
+	}
+}
+
+func TestResourceQuantityQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"limits":   map[string]interface{}{"memory": "512Mi", "cpu": 2},
+			"requests": map[string]interface{}{"cpu": "250m"},
+		},
+		"image": map[string]interface{}{"repository": "nginx"},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	memory := findQuestion(questions, "resources.limits.memory")
+	if memory == nil || memory.Type != "string" || memory.Group != "Resources" || memory.ValidChars != quantityPattern {
+		t.Fatalf("Unexpected resources.limits.memory question: %+v", memory)
+	}
+	if cpu := findQuestion(questions, "resources.limits.cpu"); cpu == nil || cpu.Type != "string" || cpu.Default != "2" {
+		t.Errorf("Expected numeric cpu limit to become a string question, got %+v", cpu)
+	}
+
+	quantity := regexp.MustCompile(memory.ValidChars)
+	for _, valid := range []string{"512Mi", "250m", "2", "1.5Gi", "100k"} {
+		if !quantity.MatchString(valid) {
+			t.Errorf("Expected quantity pattern to accept %q", valid)
+		}
+	}
+	for _, invalid := range []string{"512MB", "-1", "lots", "1.Gi"} {
+		if quantity.MatchString(invalid) {
+			t.Errorf("Expected quantity pattern to reject %q", invalid)
+		}
+	}
+
+	if repo := findQuestion(questions, "image.repository"); repo == nil || repo.ValidChars != "" {
+		t.Errorf("Expected no quantity validation on image.repository, got %+v", repo)
+	}
+}
+
+func TestImageQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        1.25,
+			"pullPolicy": "always",
+			"registry":   "docker.io",
+		},
+		"ollama": map[string]interface{}{
+			"image": map[string]interface{}{
+				"repository": "ollama/ollama",
+				"tag":        "",
+			},
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	tests := []struct {
+		variable     string
+		qType        string
+		group        string
+		defaultValue interface{}
+	}{
+		{"image.repository", "string", "Image", "nginx"},
+		{"image.tag", "string", "Image", "1.25"},
+		{"image.pullPolicy", "enum", "Image", "Always"},
+		{"image.registry", "string", "Image", "docker.io"},
+		{"ollama.image.repository", "string", "Ollama Image", "ollama/ollama"},
+		{"ollama.image.tag", "string", "Ollama Image", nil},
+		{"ollama.image.pullPolicy", "enum", "Ollama Image", "IfNotPresent"},
+	}
+	for _, tt := range tests {
+		q := findQuestion(questions, tt.variable)
+		if q == nil {
+			t.Errorf("Expected question for %s", tt.variable)
+			continue
+		}
+		if q.Type != tt.qType || q.Group != tt.group || q.Default != tt.defaultValue {
+			t.Errorf("Expected %s to be %s in %s with default %v, got %+v", tt.variable, tt.qType, tt.group, tt.defaultValue, q)
+		}
+	}
+
+	// The triplet is emitted together, ahead of the rest of the block
+	var order []string
+	for _, q := range questions {
+		if strings.HasPrefix(q.Variable, "image.") {
+			order = append(order, q.Variable)
+		}
+	}
+	if strings.Join(order, ",") != "image.repository,image.tag,image.pullPolicy,image.registry" {
+		t.Errorf("Unexpected image question order: %v", order)
+	}
+}
+
+func TestProbeQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"livenessProbe": map[string]interface{}{
+			"httpGet": map[string]interface{}{
+				"path": "/healthz",
+				"port": "http",
+			},
+			"initialDelaySeconds": 30,
+			"periodSeconds":       10,
+			"failureThreshold":    nil,
+		},
+		"server": map[string]interface{}{
+			"readinessProbe": map[string]interface{}{
+				"enabled":        true,
+				"timeoutSeconds": 5,
+			},
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	tests := []struct {
+		variable string
+		qType    string
+		label    string
+		min      int
+	}{
+		{"livenessProbe.initialDelaySeconds", "int", "Liveness Probe Initial Delay Seconds", 0},
+		{"livenessProbe.periodSeconds", "int", "Liveness Probe Period Seconds", 1},
+		{"livenessProbe.failureThreshold", "int", "Liveness Probe Failure Threshold", 1},
+		{"server.readinessProbe.timeoutSeconds", "int", "Server Readiness Probe Timeout Seconds", 1},
+	}
+	for _, tt := range tests {
+		q := findQuestion(questions, tt.variable)
+		if q == nil {
+			t.Errorf("Expected question for %s", tt.variable)
+			continue
+		}
+		if q.Type != tt.qType || q.Group != "Health Checks" || q.Label != tt.label {
+			t.Errorf("Expected %s to be a %s in Health Checks labelled %q, got %+v", tt.variable, tt.qType, tt.label, q)
+		}
+		if q.Min == nil || *q.Min != tt.min {
+			t.Errorf("Expected %s min %d, got %v", tt.variable, tt.min, q.Min)
+		}
+	}
+
+	// The rest of the block is grouped too, keeping its inferred types
+	for variable, qType := range map[string]string{
+		"livenessProbe.httpGet.path":    "string",
+		"server.readinessProbe.enabled": "boolean",
+	} {
+		q := findQuestion(questions, variable)
+		if q == nil || q.Group != "Health Checks" || q.Type != qType || q.Min != nil {
+			t.Errorf("Expected %s to be a %s in Health Checks, got %+v", variable, qType, q)
+		}
+	}
+}
+
+func TestFeatureToggleQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"ingress": map[string]interface{}{
+			"enabled":   false,
+			"className": "nginx",
+			"hostname":  "app.local",
+		},
+		"metrics": map[string]interface{}{
+			"enabled": true,
+			"port":    9090,
+			"serviceMonitor": map[string]interface{}{
+				"enabled":  false,
+				"interval": "30s",
+			},
+		},
+		"debug": map[string]interface{}{
+			"enabled": false,
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	tests := []struct {
+		variable string
+		qType    string
+		group    string
+		showIf   string
+	}{
+		{"ingress.enabled", "boolean", "Ingress", ""},
+		{"ingress.className", "string", "Ingress", "ingress.enabled=true"},
+		{"ingress.hostname", "hostname", "Ingress", "ingress.enabled=true"},
+		{"metrics.enabled", "boolean", "Metrics", ""},
+		{"metrics.port", "int", "Metrics", "metrics.enabled=true"},
+		{"metrics.serviceMonitor.enabled", "boolean", "Metrics", "metrics.enabled=true"},
+		{"metrics.serviceMonitor.interval", "string", "Metrics", "metrics.enabled=true&&metrics.serviceMonitor.enabled=true"},
+	}
+	for _, tt := range tests {
+		q := findQuestion(questions, tt.variable)
+		if q == nil {
+			t.Errorf("Expected question for %s", tt.variable)
+			continue
+		}
+		if q.Type != tt.qType || q.Group != tt.group || q.ShowIf != tt.showIf {
+			t.Errorf("Expected %s to be a %s in %s shown if %q, got %+v", tt.variable, tt.qType, tt.group, tt.showIf, q)
+		}
+	}
+
+	if q := findQuestion(questions, "ingress.enabled"); q == nil || q.Label != "Enable Ingress" {
+		t.Errorf("Expected the ingress toggle to be labelled Enable Ingress, got %+v", q)
+	}
+
+	// The toggle comes before the settings it gates
+	position := make(map[string]int)
+	for i, q := range questions {
+		position[q.Variable] = i
+	}
+	if position["ingress.enabled"] > position["ingress.className"] {
+		t.Error("Expected ingress.enabled before ingress.className")
+	}
+
+	// A lone enabled flag is not a feature block
+	if q := findQuestion(questions, "debug.enabled"); q == nil || q.ShowIf != "" || q.Label == "Enable Debug" {
+		t.Errorf("Expected debug.enabled to stay a plain question, got %+v", q)
+	}
+}
+
+func TestSecretQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"ingress": map[string]interface{}{
+			"tls": map[string]interface{}{
+				"secretName":      "web-tls",
+				"secretNamespace": "cert-manager",
+			},
+		},
+		"auth": map[string]interface{}{
+			"existingSecretName": "",
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	tls := findQuestion(questions, "ingress.tls.secretName")
+	if tls == nil {
+		t.Fatal("Expected question for ingress.tls.secretName")
+	}
+	if tls.Type != "secret" || tls.Namespace != "cert-manager" || tls.Default != "web-tls" {
+		t.Errorf("Unexpected TLS secret question: %+v", *tls)
+	}
+
+	existing := findQuestion(questions, "auth.existingSecretName")
+	if existing == nil {
+		t.Fatal("Expected question for auth.existingSecretName")
+	}
+	if existing.Type != "secret" || existing.Namespace != "" {
+		t.Errorf("Unexpected existing secret question: %+v", *existing)
+	}
+
+	data, err := yaml.Marshal(models.Questions{Questions: []models.Question{*tls, *existing}})
+	if err != nil {
+		t.Fatalf("Failed to marshal questions: %v", err)
+	}
+	var rendered struct {
+		Questions []map[string]interface{} `yaml:"questions"`
+	}
+	if err := yaml.Unmarshal(data, &rendered); err != nil {
+		t.Fatalf("Failed to parse rendered questions: %v", err)
+	}
+	if rendered.Questions[0]["type"] != "secret" || rendered.Questions[0]["namespace"] != "cert-manager" {
+		t.Errorf("Expected secret type and namespace in YAML, got:\n%s", data)
+	}
+	if _, ok := rendered.Questions[1]["namespace"]; ok || rendered.Questions[1]["type"] != "secret" {
+		t.Errorf("Expected secret type without namespace in YAML, got:\n%s", data)
+	}
+}
+
+func TestRequiredIfQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"enabled":  false,
+			"username": "admin",
+			"password": "",
+		},
+		"rootPassword": "",
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	password := findQuestion(questions, "auth.password")
+	if password == nil {
+		t.Fatal("Expected question for auth.password")
+	}
+	if password.Required || password.RequiredIf != "auth.enabled=true" {
+		t.Errorf("Expected auth.password to be required only when auth is enabled, got %+v", *password)
+	}
+
+	// A password outside any feature stays unconditionally required
+	if q := findQuestion(questions, "rootPassword"); q == nil || !q.Required || q.RequiredIf != "" {
+		t.Errorf("Expected rootPassword to be required, got %+v", q)
+	}
+
+	data, err := yaml.Marshal(password)
+	if err != nil {
+		t.Fatalf("Failed to marshal question: %v", err)
+	}
+	var rendered map[string]interface{}
+	if err := yaml.Unmarshal(data, &rendered); err != nil {
+		t.Fatalf("Failed to parse rendered question: %v", err)
+	}
+	if rendered["required_if"] != "auth.enabled=true" {
+		t.Errorf("Expected required_if in YAML, got:\n%s", data)
+	}
+	if _, ok := rendered["required"]; ok {
+		t.Errorf("Expected no required flag in YAML, got:\n%s", data)
+	}
+}
@@ -0,0 +1,45 @@
+package helm
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// helmErrorPatterns map markers in helm's combined output, matched
+// case-insensitively and in order, to messages safe to show users.
+var helmErrorPatterns = []struct {
+	markers []string
+	message string
+}{
+	{[]string{"x509:", "tls:", "certificate"}, "TLS verification with the chart repository or registry failed"},
+	{[]string{"no such host", "connection refused", "connection reset", "i/o timeout", "network is unreachable", "deadline exceeded", "timeout"}, "could not reach the chart repository or registry"},
+	{[]string{"not found", "404", "manifest unknown", "no chart version found", "no chart name found"}, "chart or repository not found"},
+	{[]string{"already exists"}, "repository already exists"},
+}
+
+// ErrorSummary describes err in a message safe to show users, like
+// parseHelmError does for helm output. Errors from fetching or processing
+// charts can carry repository URLs, helm output and local paths.
+func ErrorSummary(err error) string {
+	return parseHelmError([]byte(err.Error()))
+}
+
+// parseHelmError turns helm's combined output into a short message for
+// users. The raw output can contain registry URLs and local paths, so it is
+// only logged at debug level.
+func parseHelmError(output []byte) string {
+	slog.Debug("helm command failed", "output", string(output))
+
+	if isAuthFailure(output) {
+		return "authentication with the chart repository or registry failed"
+	}
+	text := strings.ToLower(string(output))
+	for _, pattern := range helmErrorPatterns {
+		for _, marker := range pattern.markers {
+			if strings.Contains(text, marker) {
+				return pattern.message
+			}
+		}
+	}
+	return "helm command failed"
+}
@@ -0,0 +1,206 @@
+package helm
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rancher-questions-generator/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// indexClient fetches repository index files. Indexes of large repositories
+// run to several megabytes, so the timeout is generous.
+var indexClient = &http.Client{Timeout: 60 * time.Second, Transport: newTransport()}
+
+// repositoryIndex is the subset of a Helm repository index.yaml we use.
+type repositoryIndex struct {
+	Entries map[string][]indexEntry `yaml:"entries"`
+}
+
+type indexEntry struct {
+	Name        string              `yaml:"name"`
+	Version     string              `yaml:"version"`
+	AppVersion  string              `yaml:"appVersion"`
+	Description string              `yaml:"description"`
+	Keywords    []string            `yaml:"keywords"`
+	Icon        string              `yaml:"icon"`
+	Home        string              `yaml:"home"`
+	Sources     []string            `yaml:"sources"`
+	Maintainers []models.Maintainer `yaml:"maintainers"`
+	Deprecated  bool                `yaml:"deprecated"`
+}
+
+// fetchIndexCharts lists the charts of an HTTP repository by downloading its
+// index.yaml directly, so no helm CLI is needed.
+func (rm *RepositoryManager) fetchIndexCharts(repo *models.Repository) ([]*models.Chart, error) {
+	indexURL := strings.TrimSuffix(repo.URL, "/") + "/index.yaml"
+	req, err := http.NewRequest(http.MethodGet, indexURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	setAuthHeader(req, repo.Auth)
+
+	resp, err := indexClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", indexURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", indexURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", indexURL, err)
+	}
+
+	return parseRepositoryIndex(data, repo.Name)
+}
+
+// cachedIndexCharts reads the index helm cached for a repository during
+// "helm repo update" and returns its charts keyed by name. A missing or
+// unreadable cache yields an empty map.
+func (rm *RepositoryManager) cachedIndexCharts(repoName string) map[string]*models.Chart {
+	byName := make(map[string]*models.Chart)
+
+	data, err := os.ReadFile(filepath.Join(rm.helmHome, "cache", "repository", repoName+"-index.yaml"))
+	if err != nil {
+		return byName
+	}
+
+	charts, err := parseRepositoryIndex(data, repoName)
+	if err != nil {
+		fmt.Printf("Warning: Failed to parse cached index for repo %s: %v\n", repoName, err)
+		return byName
+	}
+	for _, chart := range charts {
+		byName[chart.Name] = chart
+	}
+	return byName
+}
+
+// parseRepositoryIndex converts index.yaml contents into charts, one per
+// chart name, describing the newest stable version and listing all versions
+// newest first.
+func parseRepositoryIndex(data []byte, repoName string) ([]*models.Chart, error) {
+	var index repositoryIndex
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse repository index: %w", err)
+	}
+
+	charts := make([]*models.Chart, 0, len(index.Entries))
+	for name, entries := range index.Entries {
+		if len(entries) == 0 {
+			continue
+		}
+
+		sort.SliceStable(entries, func(i, j int) bool {
+			return compareVersions(entries[i].Version, entries[j].Version) > 0
+		})
+
+		versions := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			versions = append(versions, entry.Version)
+		}
+
+		// Like helm search, prefer the newest stable release over
+		// pre-releases when describing the chart.
+		latest := entries[0]
+		for _, entry := range entries {
+			if _, prerelease := splitVersion(entry.Version); prerelease == "" {
+				latest = entry
+				break
+			}
+		}
+		keywords := latest.Keywords
+		if keywords == nil {
+			keywords = []string{}
+		}
+		charts = append(charts, &models.Chart{
+			Name:        name,
+			Version:     latest.Version,
+			Versions:    versions,
+			AppVersion:  latest.AppVersion,
+			Description: latest.Description,
+			Repository:  repoName,
+			Keywords:    keywords,
+			Icon:        latest.Icon,
+			Home:        latest.Home,
+			Sources:     latest.Sources,
+			Maintainers: latest.Maintainers,
+		})
+	}
+
+	sort.Slice(charts, func(i, j int) bool {
+		return charts[i].Name < charts[j].Name
+	})
+	return charts, nil
+}
+
+// compareVersions orders semantic versions, returning a positive number when
+// a is newer than b. A leading "v" is ignored, numeric segments are compared
+// numerically and a pre-release sorts before its release.
+func compareVersions(a, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+
+	aParts := strings.Split(aCore, ".")
+	bParts := strings.Split(bCore, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aPart, bPart string
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+		if c := compareVersionSegment(aPart, bPart); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return strings.Compare(aPre, bPre)
+}
+
+func splitVersion(version string) (core, prerelease string) {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexByte(version, '+'); i >= 0 {
+		version = version[:i]
+	}
+	if i := strings.IndexByte(version, '-'); i >= 0 {
+		return version[:i], version[i+1:]
+	}
+	return version, ""
+}
+
+func compareVersionSegment(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	if a == "" {
+		aNum, aErr = 0, nil
+	}
+	if b == "" {
+		bNum, bErr = 0, nil
+	}
+	if aErr == nil && bErr == nil {
+		return aNum - bNum
+	}
+	return strings.Compare(a, b)
+}
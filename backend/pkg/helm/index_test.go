@@ -0,0 +1,180 @@
+package helm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+const sampleIndex = `apiVersion: v1
+entries:
+  nginx:
+  - name: nginx
+    version: 15.4.3
+    appVersion: 1.25.2
+    description: Older NGINX release
+    keywords: [nginx]
+  - name: nginx
+    version: 15.4.10
+    appVersion: 1.25.3
+    description: NGINX Open Source web server
+    keywords: [nginx, http, web]
+    icon: https://example.com/nginx.png
+    home: https://nginx.org
+    sources: [https://github.com/example/nginx]
+    maintainers:
+    - name: Web Team
+      email: web@example.com
+  - name: nginx
+    version: 15.5.0-rc.1
+    appVersion: 1.26.0
+    description: NGINX release candidate
+  grafana:
+  - name: grafana
+    version: 7.0.17
+    appVersion: 10.2.2
+    description: Visualization for metrics
+generated: "2024-01-01T00:00:00Z"
+`
+
+func TestFetchIndexCharts(t *testing.T) {
+	var gotUser, gotPassword string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/charts/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		gotUser, gotPassword, _ = r.BasicAuth()
+		w.Write([]byte(sampleIndex))
+	}))
+	defer server.Close()
+
+	rm := NewRepositoryManager()
+	charts, err := rm.fetchIndexCharts(&models.Repository{
+		Name: "test",
+		URL:  server.URL + "/charts/",
+		Auth: &models.Authentication{Username: "user", Password: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("fetchIndexCharts() error = %v", err)
+	}
+
+	if gotUser != "user" || gotPassword != "secret" {
+		t.Errorf("Expected basic auth credentials, got %q/%q", gotUser, gotPassword)
+	}
+
+	if len(charts) != 2 {
+		t.Fatalf("Expected 2 charts, got %d", len(charts))
+	}
+
+	grafana, nginx := charts[0], charts[1]
+	if grafana.Name != "grafana" || grafana.Repository != "test" {
+		t.Errorf("Unexpected first chart: %+v", grafana)
+	}
+
+	expected := &models.Chart{
+		Name:        "nginx",
+		Version:     "15.4.10",
+		Versions:    []string{"15.5.0-rc.1", "15.4.10", "15.4.3"},
+		AppVersion:  "1.25.3",
+		Description: "NGINX Open Source web server",
+		Repository:  "test",
+		Keywords:    []string{"nginx", "http", "web"},
+		Icon:        "https://example.com/nginx.png",
+		Home:        "https://nginx.org",
+		Sources:     []string{"https://github.com/example/nginx"},
+		Maintainers: []models.Maintainer{{Name: "Web Team", Email: "web@example.com"}},
+	}
+	// The release candidate is newest but a stable release should be the
+	// default version.
+	if !reflect.DeepEqual(nginx, expected) {
+		t.Errorf("nginx chart = %+v, expected %+v", nginx, expected)
+	}
+}
+
+func TestFetchIndexChartsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken/index.yaml" {
+			w.Write([]byte("entries: [not, a, map"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	rm := NewRepositoryManager()
+	for _, path := range []string{"/missing", "/broken"} {
+		if _, err := rm.fetchIndexCharts(&models.Repository{Name: "test", URL: server.URL + path}); err == nil {
+			t.Errorf("Expected error for %s", path)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0", "1.0.0", 0},
+		{"v2.0.0", "1.99.99", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0", "1.0.1", -1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		got := compareVersions(tt.a, tt.b)
+		if (got > 0) != (tt.expected > 0) || (got < 0) != (tt.expected < 0) {
+			t.Errorf("compareVersions(%q, %q) = %d, expected sign of %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestSearchHelmChartsAddsKeywords(t *testing.T) {
+	rm := NewRepositoryManager()
+	rm.helmHome = t.TempDir()
+
+	cacheDir := filepath.Join(rm.helmHome, "cache", "repository")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, "test-index.yaml"), []byte(sampleIndex), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var commands int
+	rm.runHelm = func(args ...string) ([]byte, error) {
+		commands++
+		return []byte(`[
+			{"name": "test/nginx", "version": "15.4.10", "app_version": "1.25.3", "description": "NGINX"},
+			{"name": "test/grafana", "version": "7.0.17", "app_version": "10.2.2", "description": "Grafana"},
+			{"name": "test/unindexed", "version": "1.0.0", "description": "Not in the cached index"}
+		]`), nil
+	}
+
+	charts, err := rm.searchHelmCharts("test")
+	if err != nil {
+		t.Fatalf("searchHelmCharts() error = %v", err)
+	}
+	if commands != 1 {
+		t.Errorf("Expected a single helm invocation, got %d", commands)
+	}
+
+	if !reflect.DeepEqual(charts[0].Keywords, []string{"nginx", "http", "web"}) {
+		t.Errorf("Expected nginx keywords from the index, got %v", charts[0].Keywords)
+	}
+	if charts[2].Keywords == nil || len(charts[2].Keywords) != 0 {
+		t.Errorf("Expected empty keywords for unindexed chart, got %v", charts[2].Keywords)
+	}
+
+	filtered := rm.filterCharts(charts, "web")
+	if len(filtered) != 1 || filtered[0].Name != "nginx" {
+		t.Errorf("Expected keyword search to find nginx, got %v", filtered)
+	}
+}
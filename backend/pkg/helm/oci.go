@@ -0,0 +1,115 @@
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// digestPattern matches OCI content digests such as sha256:<hex>.
+var digestPattern = regexp.MustCompile(`^[a-z0-9]+([+._-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+
+// signatureTagPattern matches the tags cosign stores signatures, attestations
+// and SBOMs under: the digest of the signed manifest with ":" replaced by "-"
+// and a suffix, e.g. sha256-<hex>.sig.
+var signatureTagPattern = regexp.MustCompile(`^[a-z0-9]+-[a-fA-F0-9]{32,}\.[a-z]+$`)
+
+// signatureTagSuffixes mark tags of signature and attestation artifacts
+// published beside charts rather than chart versions.
+var signatureTagSuffixes = []string{".sig", ".att", ".sbom"}
+
+// isChartVersionTag reports whether a registry tag names a chart version
+// rather than a signature, attestation or SBOM artifact.
+func isChartVersionTag(tag string) bool {
+	if signatureTagPattern.MatchString(tag) {
+		return false
+	}
+	for _, suffix := range signatureTagSuffixes {
+		if strings.HasSuffix(tag, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// chartVersionTags returns the tags that name chart versions, in their
+// original order, dropping signature and attestation tags.
+func chartVersionTags(tags []string) []string {
+	versions := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if isChartVersionTag(tag) {
+			versions = append(versions, tag)
+		}
+	}
+	return versions
+}
+
+// parseOCIReference splits oci://host[:port]/path/to/chart[:tag|@digest]
+// into its parts. repo is the path between the registry and the chart and
+// may be empty or span several segments; tag is empty when the reference has
+// neither a tag nor a digest, and holds the full digest (e.g. "sha256:...")
+// for digest references. Tags of cosign signatures and attestations are
+// rejected since they don't hold a chart.
+func parseOCIReference(url string) (registry, repo, chart, tag string, err error) {
+	if !strings.HasPrefix(url, "oci://") {
+		return "", "", "", "", fmt.Errorf("invalid OCI reference %q: missing oci:// prefix", url)
+	}
+	rest := strings.TrimPrefix(url, "oci://")
+
+	if idx := strings.Index(rest, "@"); idx >= 0 {
+		tag = rest[idx+1:]
+		rest = rest[:idx]
+		if !digestPattern.MatchString(tag) {
+			return "", "", "", "", fmt.Errorf("invalid OCI reference %q: malformed digest %q", url, tag)
+		}
+	} else if slash, colon := strings.LastIndex(rest, "/"), strings.LastIndex(rest, ":"); colon > slash {
+		// A colon before the last slash belongs to the registry port
+		tag = rest[colon+1:]
+		rest = rest[:colon]
+		if tag == "" {
+			return "", "", "", "", fmt.Errorf("invalid OCI reference %q: empty tag", url)
+		}
+		if !isChartVersionTag(tag) {
+			return "", "", "", "", fmt.Errorf("invalid OCI reference %q: %q is a signature or attestation, not a chart version", url, tag)
+		}
+	}
+
+	segments := strings.Split(rest, "/")
+	if len(segments) < 2 || segments[0] == "" || segments[len(segments)-1] == "" {
+		return "", "", "", "", fmt.Errorf("invalid OCI reference %q: expected oci://registry/chart", url)
+	}
+	for _, segment := range segments[1 : len(segments)-1] {
+		if segment == "" {
+			return "", "", "", "", fmt.Errorf("invalid OCI reference %q: empty path segment", url)
+		}
+	}
+
+	registry = segments[0]
+	repo = strings.Join(segments[1:len(segments)-1], "/")
+	chart = segments[len(segments)-1]
+	return registry, repo, chart, tag, nil
+}
+
+// ociPullArgs returns the helm pull arguments for an OCI reference. helm
+// takes the chart version as --version rather than as a tag in the URL, and
+// picks the newest version when none is given, so "latest" is dropped.
+// Digest references are passed through unchanged.
+func ociPullArgs(url string) ([]string, error) {
+	registry, repo, chart, tag, err := parseOCIReference(url)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(tag, ":") {
+		return []string{url}, nil
+	}
+
+	path := registry
+	if repo != "" {
+		path += "/" + repo
+	}
+	args := []string{"oci://" + path + "/" + chart}
+	if tag != "" && tag != "latest" {
+		args = append(args, "--version", tag)
+	}
+	return args, nil
+}
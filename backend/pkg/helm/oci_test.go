@@ -0,0 +1,97 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOCIReference(t *testing.T) {
+	digest := "sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name     string
+		url      string
+		registry string
+		repo     string
+		chart    string
+		tag      string
+		wantErr  bool
+	}{
+		{"tagged", "oci://dp.apps.rancher.io/charts/ollama:1.16.0", "dp.apps.rancher.io", "charts", "ollama", "1.16.0", false},
+		{"untagged", "oci://dp.apps.rancher.io/charts/ollama", "dp.apps.rancher.io", "charts", "ollama", "", false},
+		{"digest", "oci://ghcr.io/org/charts/app@" + digest, "ghcr.io", "org/charts", "app", digest, false},
+		{"multi-segment path", "oci://registry.example.com/a/b/c/app:2.0.0-rc.1", "registry.example.com", "a/b/c", "app", "2.0.0-rc.1", false},
+		{"registry port", "oci://localhost:5000/app", "localhost:5000", "", "app", "", false},
+		{"registry port with tag", "oci://localhost:5000/charts/app:1.0.0", "localhost:5000", "charts", "app", "1.0.0", false},
+		{"missing prefix", "https://example.com/charts/app", "", "", "", "", true},
+		{"registry only", "oci://registry.example.com", "", "", "", "", true},
+		{"empty tag", "oci://registry.example.com/app:", "", "", "", "", true},
+		{"malformed digest", "oci://registry.example.com/app@sha256:xyz", "", "", "", "", true},
+		{"empty segment", "oci://registry.example.com//app", "", "", "", "", true},
+		{"signature tag", "oci://registry.example.com/app:sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef.sig", "", "", "", "", true},
+		{"attestation tag", "oci://registry.example.com/app:1.0.0.att", "", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, repo, chart, tag, err := parseOCIReference(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOCIReference(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if registry != tt.registry || repo != tt.repo || chart != tt.chart || tag != tt.tag {
+				t.Errorf("parseOCIReference(%q) = (%q, %q, %q, %q), want (%q, %q, %q, %q)",
+					tt.url, registry, repo, chart, tag, tt.registry, tt.repo, tt.chart, tt.tag)
+			}
+		})
+	}
+}
+
+func TestOCIPullArgs(t *testing.T) {
+	digestRef := "oci://ghcr.io/org/app@sha256:0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"oci://registry.example.com/charts/app:1.0.0", []string{"oci://registry.example.com/charts/app", "--version", "1.0.0"}},
+		{"oci://registry.example.com/charts/app:latest", []string{"oci://registry.example.com/charts/app"}},
+		{"oci://registry.example.com/app", []string{"oci://registry.example.com/app"}},
+		{digestRef, []string{digestRef}},
+	}
+
+	for _, tt := range tests {
+		got, err := ociPullArgs(tt.url)
+		if err != nil {
+			t.Fatalf("ociPullArgs(%q) error = %v", tt.url, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ociPullArgs(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestChartVersionTags(t *testing.T) {
+	hex := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tags := []string{
+		"1.16.0",
+		"sha256-" + hex + ".sig",
+		"1.15.0",
+		"sha256-" + hex + ".att",
+		"sha256-" + hex + ".sbom",
+		"1.15.0-rc.1",
+		"sha512-" + hex + hex + ".sig",
+		"latest",
+		"1.14.0.sig",
+	}
+
+	want := []string{"1.16.0", "1.15.0", "1.15.0-rc.1", "latest"}
+	if got := chartVersionTags(tags); !reflect.DeepEqual(got, want) {
+		t.Errorf("chartVersionTags() = %v, want %v", got, want)
+	}
+	if got := chartVersionTags(nil); got == nil || len(got) != 0 {
+		t.Errorf("chartVersionTags(nil) = %#v, want an empty list", got)
+	}
+}
@@ -0,0 +1,146 @@
+package helm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// presetSelectorKeys are sibling keys commonly used to pick one entry out of a
+// map of presets, e.g. `preset: small` next to `presets: {small: ..., large: ...}`.
+var presetSelectorKeys = []string{"preset", "size", "profile", "tier", "flavor"}
+
+// presetQuestions walks values looking for maps of uniform sub-maps that sit
+// next to a string selector key, and emits an enum question for each selector
+// with the preset names as options. This is a heuristic and only runs when
+// InferPresets is enabled.
+func (p *Processor) presetQuestions(values map[string]interface{}, prefix string) []models.Question {
+	var questions []models.Question
+
+	keys := sortedKeys(values)
+	for _, key := range keys {
+		nested, ok := values[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if presetNames := uniformPresetNames(nested); presetNames != nil {
+			if selector, ok := presetSelector(values, key); ok {
+				question := models.Question{
+					Variable:    prefix + selector,
+					Label:       humanizeKey(selector),
+					Description: fmt.Sprintf("Select one of the presets defined in %s", prefix+key),
+					Type:        "enum",
+					Options:     presetNames,
+					Group:       "Presets",
+				}
+				if current, ok := values[selector].(string); ok && containsString(presetNames, current) {
+					question.Default = current
+				}
+				questions = append(questions, question)
+				continue
+			}
+		}
+
+		questions = append(questions, p.presetQuestions(nested, prefix+key+".")...)
+	}
+
+	return questions
+}
+
+// uniformPresetNames returns the sorted keys of m when it holds at least two
+// sub-maps that all share the same set of keys, and nil otherwise.
+func uniformPresetNames(m map[string]interface{}) []string {
+	if len(m) < 2 {
+		return nil
+	}
+
+	var shape []string
+	for _, name := range sortedKeys(m) {
+		preset, ok := m[name].(map[string]interface{})
+		if !ok || len(preset) == 0 {
+			return nil
+		}
+		keys := sortedKeys(preset)
+		if shape == nil {
+			shape = keys
+		} else if !reflect.DeepEqual(shape, keys) {
+			return nil
+		}
+	}
+
+	return sortedKeys(m)
+}
+
+// presetSelector finds the string sibling of presetsKey that selects a preset.
+func presetSelector(values map[string]interface{}, presetsKey string) (string, bool) {
+	candidates := presetSelectorKeys
+	if singular := strings.TrimSuffix(presetsKey, "s"); singular != presetsKey {
+		candidates = append([]string{singular}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		if _, ok := values[candidate].(string); ok {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// humanizeKey turns a values key such as "storageClass" or "max_replicas"
+// into a label like "Storage Class" or "Max Replicas".
+func humanizeKey(key string) string {
+	var words []string
+	var current []rune
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == '.' || r == ' ':
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(current) > 0:
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				words = append(words, string(current))
+				current = nil
+			}
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+
+	for i, word := range words {
+		wordRunes := []rune(word)
+		wordRunes[0] = unicode.ToUpper(wordRunes[0])
+		words[i] = string(wordRunes)
+	}
+	return strings.Join(words, " ")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package helm
+
+import (
+	"testing"
+)
+
+func presetValues() map[string]interface{} {
+	return map[string]interface{}{
+		"config": map[string]interface{}{
+			"preset": "small",
+			"presets": map[string]interface{}{
+				"small": map[string]interface{}{"cpu": "500m", "memory": "1Gi"},
+				"large": map[string]interface{}{"cpu": "4", "memory": "16Gi"},
+			},
+		},
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": "100m"},
+			"limits":   map[string]interface{}{"memory": "1Gi"},
+		},
+	}
+}
+
+func TestPresetQuestionsDisabledByDefault(t *testing.T) {
+	processor := NewProcessor()
+	processor.InferPresets = false
+
+	questions := processor.generateDefaultQuestions(presetValues(), ProfileStandard)
+	for _, q := range questions.Questions {
+		if q.Variable == "config.preset" && q.Type == "enum" {
+			t.Fatal("Expected no preset enum when InferPresets is disabled")
+		}
+	}
+}
+
+func TestPresetQuestionsEnabled(t *testing.T) {
+	processor := NewProcessor()
+	processor.InferPresets = true
+
+	questions := processor.generateDefaultQuestions(presetValues(), ProfileStandard)
+
+	var found bool
+	for _, q := range questions.Questions {
+		switch q.Variable {
+		case "config.preset":
+			found = true
+			if q.Type != "enum" {
+				t.Errorf("Expected enum type, got %s", q.Type)
+			}
+			if len(q.Options) != 2 || q.Options[0] != "large" || q.Options[1] != "small" {
+				t.Errorf("Expected options [large small], got %v", q.Options)
+			}
+			if q.Default != "small" {
+				t.Errorf("Expected default small, got %v", q.Default)
+			}
+		case "resources.preset", "resources.requests", "resources.limits":
+			// requests/limits have different shapes and no selector sibling
+			t.Errorf("Unexpected preset question %s", q.Variable)
+		}
+	}
+	if !found {
+		t.Error("Expected a config.preset enum question")
+	}
+}
+
+func TestUniformPresetNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    map[string]interface{}
+		expected int
+	}{
+		{
+			name: "uniform sub-maps",
+			input: map[string]interface{}{
+				"a": map[string]interface{}{"x": 1},
+				"b": map[string]interface{}{"x": 2},
+			},
+			expected: 2,
+		},
+		{
+			name: "mismatched sub-maps",
+			input: map[string]interface{}{
+				"a": map[string]interface{}{"x": 1},
+				"b": map[string]interface{}{"y": 2},
+			},
+			expected: 0,
+		},
+		{
+			name: "scalar entry",
+			input: map[string]interface{}{
+				"a": map[string]interface{}{"x": 1},
+				"b": "scalar",
+			},
+			expected: 0,
+		},
+		{
+			name: "single entry",
+			input: map[string]interface{}{
+				"a": map[string]interface{}{"x": 1},
+			},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniformPresetNames(tt.input); len(got) != tt.expected {
+				t.Errorf("Expected %d preset names, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHumanizeKey(t *testing.T) {
+	tests := map[string]string{
+		"storageClass":                   "Storage Class",
+		"max_replicas":                   "Max Replicas",
+		"preset":                         "Preset",
+		"targetCPUUtilizationPercentage": "Target CPU Utilization Percentage",
+	}
+
+	for input, expected := range tests {
+		if got := humanizeKey(input); got != expected {
+			t.Errorf("humanizeKey(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
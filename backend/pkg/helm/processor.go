@@ -2,102 +2,502 @@ package helm
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"rancher-questions-generator/internal/models"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Processor downloads charts and generates questions from them. A single
+// Processor is shared by all request handlers, so ProcessChart must be safe
+// for concurrent use: its fields are configuration set before first use and
+// never modified afterwards, and every call works in its own uniquely named
+// directories under tempDir.
 type Processor struct {
 	tempDir string
+
+	// InferPresets enables heuristic enum questions for map-of-maps presets
+	// (see presetQuestions). Defaults to the INFER_PRESETS environment variable.
+	InferPresets bool
+
+	// VerifyCharts enables provenance checks: when a chart has a .prov file
+	// and Keyring points to a public keyring, the chart must be signed by one
+	// of its keys. Defaults to the VERIFY_CHARTS and CHART_KEYRING
+	// environment variables.
+	VerifyCharts bool
+	Keyring      string
+
+	// MaxQuestions caps how many questions are generated from values; zero or
+	// less disables the cap. Defaults to the MAX_QUESTIONS environment
+	// variable, or defaultMaxQuestions.
+	MaxQuestions int
+
+	// BooleanEnumLabels, when set, renders generated boolean questions as
+	// enums offering these labels for true and false. The labels are
+	// written to values as-is, so anything but "true" and "false" reaches
+	// templates as a string and "No" is truthy. Defaults to the
+	// BOOLEAN_ENUM_LABELS environment variable; "true" selects the literal
+	// labels, and custom labels such as "Yes,No" are only accepted when
+	// BOOLEAN_ENUM_STRING_LABELS is also true.
+	BooleanEnumLabels []string
+
+	// QuestionsFiles are the chart files searched for existing questions,
+	// in order. Bare names match anywhere in the chart; paths such as
+	// rancher/questions.yaml match that subpath. Defaults to the
+	// QUESTIONS_FILES environment variable, or defaultQuestionsFiles.
+	QuestionsFiles []string
+
+	// AppReadmeHints applies the labels, descriptions and groups listed in
+	// the YAML front matter of the chart's app-readme.md to the generated
+	// questions. Defaults to the APP_README_HINTS environment variable, or
+	// true.
+	AppReadmeHints bool
+
+	// AdvancedPatterns are glob patterns, such as "*.debug" or
+	// "*.experimental*", of variables whose generated questions always go
+	// behind the advanced toggle (see forceAdvanced). Defaults to the
+	// ALWAYS_ADVANCED_KEYS environment variable.
+	AdvancedPatterns []string
+
+	// DownloadAttempts bounds how often an HTTP chart download is tried when
+	// it fails transiently; RetryBackoff is the wait before the first retry
+	// and doubles after each one.
+	DownloadAttempts int
+	RetryBackoff     time.Duration
+
+	// StreamDownloads extracts HTTP chart downloads as they arrive instead
+	// of saving the archive to a temporary file first, so large charts
+	// don't take twice their size on disk. Charts that must be verified
+	// still go through a temporary file, as do retries after a transient
+	// failure. Defaults to the STREAM_DOWNLOADS environment variable.
+	StreamDownloads bool
+
+	// MaxChartSize bounds the total size in bytes of the files extracted
+	// from a chart archive; zero or less disables the limit. Defaults to the
+	// MAX_CHART_SIZE environment variable, or defaultMaxChartSize.
+	MaxChartSize int64
+
+	// Credentials, when set, returns the credentials to send with an HTTP
+	// chart download, typically RepositoryManager.AuthForURL.
+	Credentials func(chartURL string) *models.Authentication
+}
+
+// defaultMaxQuestions keeps huge charts from flooding the UI.
+const defaultMaxQuestions = 500
+
+// defaultMaxChartSize is the default extracted size limit for charts.
+const defaultMaxChartSize = 100 << 20
+
+// ErrChartTooLarge is returned when a chart archive extracts to more than
+// Processor.MaxChartSize bytes.
+var ErrChartTooLarge = errors.New("chart exceeds the maximum extracted size")
+
+// defaultQuestionsFiles are the conventional locations of a chart's
+// questions, in search order.
+var defaultQuestionsFiles = []string{
+	"questions.yaml",
+	"questions.yml",
+	"rancher/questions.yaml",
+	"rancher/questions.yml",
 }
 
 func NewProcessor() *Processor {
 	return &Processor{
-		tempDir: "/tmp/helm-charts",
+		tempDir:      "/tmp/helm-charts",
+		InferPresets: envBool("INFER_PRESETS", false),
+		VerifyCharts: envBool("VERIFY_CHARTS", false),
+		Keyring:      os.Getenv("CHART_KEYRING"),
+		MaxQuestions: envInt("MAX_QUESTIONS", defaultMaxQuestions),
+
+		BooleanEnumLabels: booleanLabelsFromEnv(),
+		QuestionsFiles:    envList("QUESTIONS_FILES", defaultQuestionsFiles),
+		AppReadmeHints:    envBool("APP_README_HINTS", true),
+		AdvancedPatterns:  envList("ALWAYS_ADVANCED_KEYS", nil),
+
+		DownloadAttempts: 3,
+		RetryBackoff:     500 * time.Millisecond,
+
+		StreamDownloads: envBool("STREAM_DOWNLOADS", false),
+		MaxChartSize:    int64(envInt("MAX_CHART_SIZE", defaultMaxChartSize)),
 	}
 }
 
-func (p *Processor) ProcessChart(chartURL string) (map[string]interface{}, models.Questions, error) {
-	chartDir, err := p.downloadAndExtract(chartURL)
+// maxReadmeSize bounds how much of a chart's README is returned to clients.
+const maxReadmeSize = 64 * 1024
+
+// ChartResult holds everything extracted from a processed chart.
+type ChartResult struct {
+	Values    map[string]interface{}
+	Questions models.Questions
+	Chart     *models.ChartMeta
+	Readme    string
+	Notes     string
+	// ValuesSchema is the chart's values.schema.json, if it ships one.
+	ValuesSchema string
+	// OriginalQuestions is the chart's own questions.yaml before merging,
+	// empty when the chart ships none.
+	OriginalQuestions models.Questions
+	// QuestionsSource tells where Questions came from, one of the
+	// QuestionsSource constants.
+	QuestionsSource string
+
+	// HelmAvailable is set for OCI charts only. When false, the helm CLI was
+	// missing and Values/Questions come from built-in example data.
+	HelmAvailable *bool
+
+	// QuestionsTruncated reports that generation hit MaxQuestions and the
+	// least important generated questions were dropped.
+	QuestionsTruncated bool
+}
+
+// Values of ChartResult.QuestionsSource.
+const (
+	// QuestionsSourceChart means the questions are the chart's own
+	// questions.yaml, which already covered every generated question.
+	QuestionsSourceChart = "chart"
+	// QuestionsSourceGenerated means the chart has no questions.yaml.
+	QuestionsSourceGenerated = "generated"
+	// QuestionsSourceMerged means generated questions were added to the
+	// chart's questions.yaml.
+	QuestionsSourceMerged = "merged"
+)
+
+// ProcessChart is ProcessChartContext without cancellation.
+func (p *Processor) ProcessChart(chartURL string) (*ChartResult, error) {
+	return p.ProcessChartContext(context.Background(), chartURL)
+}
+
+// ProcessChartContext is ProcessChartProfile with ProfileStandard.
+func (p *Processor) ProcessChartContext(ctx context.Context, chartURL string) (*ChartResult, error) {
+	return p.ProcessChartProfile(ctx, chartURL, ProfileStandard)
+}
+
+// ProcessChartProfile downloads the chart at chartURL and builds its values,
+// metadata and questions, generating default questions with profile.
+// Cancelling ctx aborts the download, including any helm subprocess, and the
+// error then wraps ctx.Err().
+func (p *Processor) ProcessChartProfile(ctx context.Context, chartURL string, profile Profile) (*ChartResult, error) {
+	var helmAvailable *bool
+	chartDir, err := p.downloadAndExtract(ctx, chartURL)
+	if strings.HasPrefix(chartURL, "oci://") {
+		available := !errors.Is(err, ErrHelmUnavailable)
+		helmAvailable = &available
+		if !available {
+			// Keep the UI usable without helm, but report that the data is
+			// an example rather than the real chart.
+			chartDir, err = p.createMockOCIChart(chartURL)
+		}
+	}
 	if err != nil {
-		return nil, models.Questions{}, fmt.Errorf("failed to download chart: %w", err)
+		return nil, fmt.Errorf("failed to download chart: %w", err)
 	}
 	defer os.RemoveAll(chartDir)
 
 	values, err := p.parseValues(chartDir)
 	if err != nil {
-		return nil, models.Questions{}, fmt.Errorf("failed to parse values.yaml: %w", err)
+		return nil, fmt.Errorf("failed to parse values.yaml: %w", err)
+	}
+
+	chartMeta, err := p.parseChartMeta(chartDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Chart.yaml: %w", err)
 	}
 
+	defaultQuestions, truncated := p.generateQuestions(values, profile)
+	if chartMeta != nil {
+		seedImageTag(defaultQuestions.Questions, chartMeta.AppVersion)
+	}
+	applyValueComments(defaultQuestions.Questions, p.parseValueComments(chartDir))
+	if p.AppReadmeHints {
+		applyAppReadmeHints(defaultQuestions.Questions, p.parseAppReadmeHints(chartDir))
+	}
+	var original models.Questions
+	source := QuestionsSourceGenerated
 	questions, err := p.parseQuestions(chartDir)
 	if err != nil {
 		// No questions.yaml found, generate default questions
-		questions = p.generateDefaultQuestions(values)
+		questions = defaultQuestions
 	} else {
 		// Existing questions.yaml found, merge with default questions
-		defaultQuestions := p.generateDefaultQuestions(values)
+		original = questions.Clone()
 		questions = p.mergeQuestions(questions, defaultQuestions)
+		source = QuestionsSourceMerged
+		if len(questions.Questions) == len(original.Questions) {
+			source = QuestionsSourceChart
+		}
+	}
+
+	questions = p.dedupeQuestions(questions)
+
+	return &ChartResult{
+		Values:    values,
+		Questions: questions,
+		Chart:     chartMeta,
+		Readme:    truncateText(p.readRootChartFile(chartDir, "README.md"), maxReadmeSize),
+		Notes:     p.readRootChartFile(chartDir, "templates/NOTES.txt"),
+
+		ValuesSchema:      p.readRootChartFile(chartDir, "values.schema.json"),
+		OriginalQuestions: original,
+		QuestionsSource:   source,
+
+		HelmAvailable:      helmAvailable,
+		QuestionsTruncated: truncated,
+	}, nil
+}
+
+// readRootChartFile returns the contents of the file at name, relative to the
+// directory of the chart's own Chart.yaml, or an empty string when the chart
+// doesn't ship it. A subchart's copy under charts/ is never returned.
+func (p *Processor) readRootChartFile(chartDir, name string) string {
+	chartFile := p.findFile(chartDir, "Chart.yaml")
+	if chartFile == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(chartFile), filepath.FromSlash(name)))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// truncateText shortens text to at most limit bytes on a rune boundary,
+// appending a marker so clients know content was cut.
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
 	}
 
-	return values, questions, nil
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "\n\n... (truncated)"
 }
 
-func (p *Processor) downloadAndExtract(chartURL string) (string, error) {
+func (p *Processor) downloadAndExtract(ctx context.Context, chartURL string) (string, error) {
 	os.MkdirAll(p.tempDir, 0755)
 	
 	if strings.HasPrefix(chartURL, "oci://") {
-		return p.downloadFromOCI(chartURL)
+		return p.downloadFromOCI(ctx, chartURL)
+	}
+
+	// Verification needs the whole archive before extracting it
+	if p.StreamDownloads && !(p.VerifyCharts && p.Keyring != "") {
+		extractDir, err := p.downloadAndExtractStream(ctx, chartURL)
+		var dlErr *downloadError
+		if err == nil || ctx.Err() != nil || p.DownloadAttempts <= 1 || !errors.As(err, &dlErr) || !dlErr.retryable {
+			return extractDir, err
+		}
+		fmt.Printf("Streaming chart download failed, retrying through a temporary file: %v\n", err)
 	}
 	
-	resp, err := http.Get(chartURL)
+	tempFile, err := os.CreateTemp(p.tempDir, "chart-*.tgz")
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
+	defer os.Remove(tempFile.Name())
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download chart: %s", resp.Status)
+	digest, err := p.downloadWithRetry(ctx, chartURL, tempFile)
+	tempFile.Close()
+	if err != nil {
+		return "", err
 	}
 
-	tempFile, err := os.CreateTemp(p.tempDir, "chart-*.tgz")
+	if p.VerifyCharts && p.Keyring != "" {
+		if err := p.verifyDownloadedChart(ctx, chartURL, digest); err != nil {
+			return "", err
+		}
+	}
+
+	extractDir, err := os.MkdirTemp(p.tempDir, "extracted-*")
 	if err != nil {
 		return "", err
 	}
-	defer os.Remove(tempFile.Name())
-
-	_, err = io.Copy(tempFile, resp.Body)
+	err = p.extractTarGz(tempFile.Name(), extractDir)
 	if err != nil {
 		return "", err
 	}
-	tempFile.Close()
 
-	extractDir := filepath.Join(p.tempDir, fmt.Sprintf("extracted-%d", time.Now().UnixNano()))
-	err = p.extractTarGz(tempFile.Name(), extractDir)
+	return extractDir, nil
+}
+
+// downloadError marks whether a failed download is worth retrying.
+type downloadError struct {
+	err       error
+	retryable bool
+}
+
+func (e *downloadError) Error() string { return e.err.Error() }
+func (e *downloadError) Unwrap() error { return e.err }
+
+// downloadWithRetry downloads chartURL into dest, returning the SHA-256 of
+// the archive. Network errors and 502/503/504 responses are retried with
+// exponential backoff up to DownloadAttempts; other failures, such as 4xx
+// responses, are returned immediately, as is cancellation of ctx.
+func (p *Processor) downloadWithRetry(ctx context.Context, chartURL string, dest *os.File) ([]byte, error) {
+	backoff := p.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		digest, err := p.downloadOnce(ctx, chartURL, dest)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var dlErr *downloadError
+		if err == nil || attempt >= p.DownloadAttempts || !errors.As(err, &dlErr) || !dlErr.retryable {
+			return digest, err
+		}
+
+		fmt.Printf("Chart download attempt %d failed, retrying in %s: %v\n", attempt, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// downloadOnce makes a single download attempt, replacing dest's contents.
+func (p *Processor) downloadOnce(ctx context.Context, chartURL string, dest *os.File) ([]byte, error) {
+	if err := dest.Truncate(0); err != nil {
+		return nil, err
+	}
+	if _, err := dest.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	resp, err := p.requestChart(ctx, chartURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(dest, digest), resp.Body); err != nil {
+		return nil, &downloadError{err: err, retryable: true}
+	}
+	return digest.Sum(nil), nil
+}
+
+// requestChart requests the chart archive at chartURL, returning the
+// response when it is 200 OK. Failures worth retrying are *downloadError.
+func (p *Processor) requestChart(ctx context.Context, chartURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, chartURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.Credentials != nil {
+		setAuthHeader(req, p.Credentials(chartURL))
+	}
+
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return nil, &downloadError{err: err, retryable: true}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return nil, &downloadError{err: fmt.Errorf("failed to download chart: %s", resp.Status), retryable: true}
+		}
+		return nil, fmt.Errorf("failed to download chart: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// downloadAndExtractStream makes a single download attempt, extracting the
+// archive while it arrives. Nothing is left behind on failure, and errors
+// reading the response are *downloadError so the caller can retry.
+func (p *Processor) downloadAndExtractStream(ctx context.Context, chartURL string) (string, error) {
+	resp, err := p.requestChart(ctx, chartURL)
 	if err != nil {
 		return "", err
 	}
+	defer resp.Body.Close()
 
+	extractDir, err := os.MkdirTemp(p.tempDir, "extracted-*")
+	if err != nil {
+		return "", err
+	}
+	body := &trackingReader{r: resp.Body}
+	if err := p.extractArchive(body, extractDir); err != nil {
+		os.RemoveAll(extractDir)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if body.err != nil {
+			return "", &downloadError{err: err, retryable: true}
+		}
+		return "", err
+	}
 	return extractDir, nil
 }
 
-func (p *Processor) downloadFromOCI(ociURL string) (string, error) {
-	// Try to use helm CLI if available
-	if p.isHelmAvailable() {
-		return p.downloadFromOCIWithHelm(ociURL)
+// trackingReader remembers the first error other than io.EOF returned by r,
+// telling network failures apart from malformed archives.
+type trackingReader struct {
+	r   io.Reader
+	err error
+}
+
+func (t *trackingReader) Read(b []byte) (int, error) {
+	n, err := t.r.Read(b)
+	if err != nil && err != io.EOF && t.err == nil {
+		t.err = err
 	}
-	
-	// Fallback: Create a mock chart directory with example values for OCI charts
-	return p.createMockOCIChart(ociURL)
+	return n, err
+}
+
+// downloadClient never forwards credentials to another host on redirect, so
+// a repository can't leak them by redirecting to a CDN or third party.
+var downloadClient = &http.Client{
+	Transport: newTransport(),
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if req.URL.Host != via[0].URL.Host {
+			req.Header.Del("Authorization")
+		}
+		return nil
+	},
+}
+
+// setAuthHeader attaches auth to req as a bearer token or basic auth.
+func setAuthHeader(req *http.Request, auth *models.Authentication) {
+	switch {
+	case auth == nil:
+	case auth.Token != "":
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	case auth.Username != "":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+}
+
+// downloadFromOCI pulls an OCI chart with the helm CLI. It returns
+// ErrHelmUnavailable when helm is not installed so ProcessChart can fall back
+// to example data explicitly.
+func (p *Processor) downloadFromOCI(ctx context.Context, ociURL string) (string, error) {
+	if !p.isHelmAvailable() {
+		return "", ErrHelmUnavailable
+	}
+	return p.downloadFromOCIWithHelm(ctx, ociURL)
 }
 
 func (p *Processor) isHelmAvailable() bool {
@@ -105,22 +505,26 @@ func (p *Processor) isHelmAvailable() bool {
 	return err == nil
 }
 
-func (p *Processor) downloadFromOCIWithHelm(ociURL string) (string, error) {
-	extractDir := filepath.Join(p.tempDir, fmt.Sprintf("oci-extracted-%d", time.Now().UnixNano()))
-	os.MkdirAll(extractDir, 0755)
-	
-	tempFile := filepath.Join(p.tempDir, fmt.Sprintf("oci-chart-%d.tgz", time.Now().UnixNano()))
-	
-	cmd := fmt.Sprintf("helm pull %s --destination %s --untar --untardir %s", 
-		ociURL, 
-		filepath.Dir(tempFile), 
-		extractDir)
+func (p *Processor) downloadFromOCIWithHelm(ctx context.Context, ociURL string) (string, error) {
+	extractDir, err := os.MkdirTemp(p.tempDir, "oci-extracted-*")
+	if err != nil {
+		return "", err
+	}
 	
-	parts := strings.Fields(cmd)
-	execCmd := exec.Command(parts[0], parts[1:]...)
+	reference, err := ociPullArgs(ociURL)
+	if err != nil {
+		return "", err
+	}
+	args := append(append([]string{"pull"}, reference...),
+		"--destination", p.tempDir, "--untar", "--untardir", extractDir)
+
+	execCmd := exec.CommandContext(ctx, "helm", args...)
 	output, err := execCmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to pull OCI chart: %s, output: %s", err, string(output))
+		return "", fmt.Errorf("failed to pull OCI chart: %s: %w", parseHelmError(output), err)
 	}
 	
 	return extractDir, nil
@@ -128,24 +532,22 @@ func (p *Processor) downloadFromOCIWithHelm(ociURL string) (string, error) {
 
 func (p *Processor) createMockOCIChart(ociURL string) (string, error) {
 	// Extract chart name from OCI URL
-	// e.g., oci://dp.apps.rancher.io/charts/ollama -> ollama
-	parts := strings.Split(ociURL, "/")
+	// e.g., oci://dp.apps.rancher.io/charts/ollama:1.16.0 -> ollama
 	chartName := "unknown"
-	if len(parts) > 0 {
-		chartName = parts[len(parts)-1]
-		// Remove version if present (e.g., ollama:1.16.0 -> ollama)
-		if strings.Contains(chartName, ":") {
-			chartName = strings.Split(chartName, ":")[0]
-		}
+	if _, _, chart, _, err := parseOCIReference(ociURL); err == nil {
+		chartName = chart
 	}
 	
-	extractDir := filepath.Join(p.tempDir, fmt.Sprintf("mock-oci-%s-%d", chartName, time.Now().UnixNano()))
-	os.MkdirAll(extractDir, 0755)
+	os.MkdirAll(p.tempDir, 0755)
+	extractDir, err := os.MkdirTemp(p.tempDir, fmt.Sprintf("mock-oci-%s-*", chartName))
+	if err != nil {
+		return "", err
+	}
 	
 	// Create mock values.yaml based on chart name
 	valuesContent := p.generateMockValues(chartName)
 	valuesPath := filepath.Join(extractDir, "values.yaml")
-	err := os.WriteFile(valuesPath, []byte(valuesContent), 0644)
+	err = os.WriteFile(valuesPath, []byte(valuesContent), 0644)
 	if err != nil {
 		return "", fmt.Errorf("failed to create mock values.yaml: %w", err)
 	}
@@ -296,6 +698,11 @@ ingress:
 	}
 }
 
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// extractTarGz unpacks the chart archive src into dest. Archives are usually
+// gzip-compressed, but plain tar files are accepted too.
 func (p *Processor) extractTarGz(src, dest string) error {
 	file, err := os.Open(src)
 	if err != nil {
@@ -303,14 +710,27 @@ func (p *Processor) extractTarGz(src, dest string) error {
 	}
 	defer file.Close()
 
-	gzr, err := gzip.NewReader(file)
-	if err != nil {
-		return err
+	return p.extractArchive(file, dest)
+}
+
+// extractArchive extracts a gzipped or plain tar archive read from r into
+// dest, skipping entries that would land outside dest and failing with
+// ErrChartTooLarge once the files exceed MaxChartSize.
+func (p *Processor) extractArchive(r io.Reader, dest string) error {
+	buffered := bufio.NewReader(r)
+	var archive io.Reader = buffered
+	if magic, _ := buffered.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gzr, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gzr.Close()
+		archive = gzr
 	}
-	defer gzr.Close()
 
-	tr := tar.NewReader(gzr)
+	tr := tar.NewReader(archive)
 
+	var extracted int64
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
@@ -320,7 +740,10 @@ func (p *Processor) extractTarGz(src, dest string) error {
 			return err
 		}
 
-		target := filepath.Join(dest, header.Name)
+		// Treat backslashes as separators so Windows-style traversal such
+		// as ..\..\x is caught by the containment check on every platform
+		name := path.Clean(strings.ReplaceAll(header.Name, "\\", "/"))
+		target := filepath.Join(dest, filepath.FromSlash(name))
 		
 		if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
 			continue
@@ -330,6 +753,11 @@ func (p *Processor) extractTarGz(src, dest string) error {
 		case tar.TypeDir:
 			os.MkdirAll(target, 0755)
 		case tar.TypeReg:
+			// The tar reader never returns more than header.Size per entry
+			extracted += header.Size
+			if p.MaxChartSize > 0 && extracted > p.MaxChartSize {
+				return fmt.Errorf("%w of %d bytes", ErrChartTooLarge, p.MaxChartSize)
+			}
 			os.MkdirAll(filepath.Dir(target), 0755)
 			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, os.FileMode(header.Mode))
 			if err != nil {
@@ -357,23 +785,56 @@ func (p *Processor) parseValues(chartDir string) (map[string]interface{}, error)
 		return nil, err
 	}
 
+	// Whitespace-only files may contain tabs, which YAML rejects
+	if len(bytes.TrimSpace(data)) == 0 {
+		return make(map[string]interface{}), nil
+	}
+
 	var values map[string]interface{}
 	err = yaml.Unmarshal(data, &values)
 	if err != nil {
 		return nil, err
 	}
+	// An empty or comment-only values.yaml unmarshals to a nil map
+	if values == nil {
+		values = make(map[string]interface{})
+	}
 
 	return values, nil
 }
 
+// parseChartMeta reads the chart's Chart.yaml, returning nil when the chart
+// doesn't ship one (e.g. mock OCI charts).
+func (p *Processor) parseChartMeta(chartDir string) (*models.ChartMeta, error) {
+	chartPath := p.findFile(chartDir, "Chart.yaml")
+	if chartPath == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta models.ChartMeta
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+
+	return &meta, nil
+}
+
+// parseQuestions reads the first of QuestionsFiles found in the chart.
 func (p *Processor) parseQuestions(chartDir string) (models.Questions, error) {
-	questionsPath := p.findFile(chartDir, "questions.yaml")
-	if questionsPath == "" {
-		questionsPath = p.findFile(chartDir, "questions.yml")
+	var questionsPath string
+	for _, candidate := range p.QuestionsFiles {
+		if questionsPath = p.findPath(chartDir, candidate); questionsPath != "" {
+			break
+		}
 	}
-	
+
 	if questionsPath == "" {
-		return models.Questions{}, fmt.Errorf("questions.yaml not found")
+		return models.Questions{}, fmt.Errorf("no questions file found (tried %s)", strings.Join(p.QuestionsFiles, ", "))
 	}
 
 	data, err := os.ReadFile(questionsPath)
@@ -405,7 +866,38 @@ func (p *Processor) findFile(dir, filename string) string {
 	return result
 }
 
-func (p *Processor) generateDefaultQuestions(values map[string]interface{}) models.Questions {
+// findPath finds candidate within dir. A bare file name is found like
+// findFile; a relative path such as "rancher/questions.yaml" matches files
+// ending in that path.
+func (p *Processor) findPath(dir, candidate string) string {
+	candidate = path.Clean(filepath.ToSlash(candidate))
+	if !strings.Contains(candidate, "/") {
+		return p.findFile(dir, candidate)
+	}
+
+	var result string
+	filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if strings.HasSuffix(filepath.ToSlash(file), "/"+candidate) {
+			result = file
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return result
+}
+
+func (p *Processor) generateDefaultQuestions(values map[string]interface{}, profile Profile) models.Questions {
+	questions, _ := p.generateQuestions(values, profile)
+	return questions
+}
+
+// generateQuestions builds the default questions for values using profile
+// and, except for ProfileExhaustive, caps them at MaxQuestions, reporting
+// whether any were dropped.
+func (p *Processor) generateQuestions(values map[string]interface{}, profile Profile) (models.Questions, bool) {
 	questions := []models.Question{
 		{
 			Variable:    "name",
@@ -447,7 +939,29 @@ func (p *Processor) generateDefaultQuestions(values map[string]interface{}) mode
 		})
 	}
 
-	return models.Questions{Questions: questions}
+	if p.InferPresets || profile == ProfileExhaustive {
+		questions = append(questions, p.presetQuestions(values, "")...)
+	}
+
+	// Hand-written questions above take precedence over the generic walk
+	walked := p.valueQuestions(values, nil)
+	switch profile {
+	case ProfileMinimal:
+		walked = minimalQuestions(walked)
+	case ProfileExhaustive:
+		walked = append(walked, emptyMapQuestions(values, nil)...)
+	}
+	questions = appendMissingQuestions(questions, walked)
+	forceAdvanced(questions, p.AdvancedPatterns)
+	if len(p.BooleanEnumLabels) == 2 {
+		booleanEnumQuestions(questions, p.BooleanEnumLabels)
+	}
+
+	if profile == ProfileExhaustive {
+		return models.Questions{Questions: withAdvancedToggle(questions)}, false
+	}
+	questions, truncated := limitQuestions(questions, p.MaxQuestions)
+	return models.Questions{Questions: withAdvancedToggle(questions)}, truncated
 }
 
 func (p *Processor) mergeQuestions(existing, defaults models.Questions) models.Questions {
@@ -470,14 +984,58 @@ func (p *Processor) mergeQuestions(existing, defaults models.Questions) models.Q
 	return models.Questions{Questions: merged}
 }
 
+// dedupeQuestions collapses questions sharing a variable into a single entry,
+// keeping the position of the first occurrence and the most complete metadata.
+func (p *Processor) dedupeQuestions(questions models.Questions) models.Questions {
+	indexByVariable := make(map[string]int)
+	deduped := make([]models.Question, 0, len(questions.Questions))
+
+	for _, q := range questions.Questions {
+		idx, exists := indexByVariable[q.Variable]
+		if !exists {
+			indexByVariable[q.Variable] = len(deduped)
+			deduped = append(deduped, q)
+			continue
+		}
+
+		fmt.Printf("Warning: duplicate question variable %q, keeping the most complete definition\n", q.Variable)
+		if questionCompleteness(q) > questionCompleteness(deduped[idx]) {
+			deduped[idx] = q
+		}
+	}
+
+	return models.Questions{Questions: deduped}
+}
+
+// questionCompleteness counts the populated fields of a question.
+func questionCompleteness(q models.Question) int {
+	score := 0
+	for _, populated := range []bool{
+		q.Label != "",
+		q.Description != "",
+		q.Type != "",
+		q.Required || q.RequiredIf != "",
+		q.Default != nil,
+		q.Group != "",
+		len(q.Options) > 0,
+		q.ShowIf != "",
+		len(q.SubQuestions) > 0,
+	} {
+		if populated {
+			score++
+		}
+	}
+	return score
+}
+
 func (p *Processor) hasNestedKey(data map[string]interface{}, keys ...string) bool {
 	current := data
-	for _, key := range keys {
+	for i, key := range keys {
 		if val, ok := current[key]; ok {
 			if nested, ok := val.(map[string]interface{}); ok {
 				current = nested
 			} else {
-				return len(keys) == 1
+				return i == len(keys)-1
 			}
 		} else {
 			return false
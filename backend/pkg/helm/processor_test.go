@@ -1,10 +1,23 @@
 package helm
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"rancher-questions-generator/internal/models"
 )
@@ -70,6 +83,71 @@ func TestExtractTarGz(t *testing.T) {
 	}
 }
 
+func TestExtractPlainTar(t *testing.T) {
+	processor := NewProcessor()
+	root := t.TempDir()
+	dest := filepath.Join(root, "out")
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	content := "replicaCount: 2\n"
+	if err := tw.WriteHeader(&tar.Header{Name: "chart/values.yaml", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("Failed to write tar header: %v", err)
+	}
+	tw.Write([]byte(content))
+	tw.Close()
+
+	archive := filepath.Join(root, "chart.tar")
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	if err := processor.extractTarGz(archive, dest); err != nil {
+		t.Fatalf("extractTarGz failed on a plain tar: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "chart", "values.yaml"))
+	if err != nil {
+		t.Fatalf("Expected values.yaml to be extracted: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("Expected %q, got %q", content, data)
+	}
+}
+
+func TestExtractTarGzSkipsTraversal(t *testing.T) {
+	processor := NewProcessor()
+	root := t.TempDir()
+	dest := filepath.Join(root, "a", "b")
+
+	archive := filepath.Join(root, "chart.tgz")
+	data := buildChartArchive(t, map[string]string{
+		"chart\\values.yaml":   "replicaCount: 1\n",
+		"..\\..\\x":            "escaped",
+		"chart\\..\\..\\..\\y": "escaped",
+		"../../z":              "escaped",
+	})
+	if err := os.WriteFile(archive, data, 0644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	if err := processor.extractTarGz(archive, dest); err != nil {
+		t.Fatalf("extractTarGz failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "chart", "values.yaml")); err != nil {
+		t.Errorf("Expected backslash path to extract under the chart dir: %v", err)
+	}
+	for _, name := range []string{"x", "y", "z"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			t.Errorf("Traversal entry %s escaped the destination", name)
+		}
+	}
+	entries, _ := os.ReadDir(dest)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the chart dir in the destination, got %d entries", len(entries))
+	}
+}
+
 func TestGenerateDefaultQuestions(t *testing.T) {
 	processor := NewProcessor()
 	
@@ -105,7 +183,7 @@ func TestGenerateDefaultQuestions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			questions := processor.generateDefaultQuestions(tt.values)
+			questions := processor.generateDefaultQuestions(tt.values, ProfileStandard)
 			if len(questions.Questions) != tt.expected {
 				t.Errorf("Expected %d questions, got %d", tt.expected, len(questions.Questions))
 			}
@@ -230,6 +308,744 @@ func TestMergeQuestions(t *testing.T) {
 	}
 }
 
+func TestProcessChartDedupesQuestions(t *testing.T) {
+	server := newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":  "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml": "service:\n  type: ClusterIP\n",
+		"mychart/questions.yaml": `questions:
+- variable: service.type
+- variable: service.type
+  label: Service Type
+  description: How the service is exposed
+  type: enum
+  options: [ClusterIP, NodePort]
+  group: Networking
+- variable: name
+  label: Release Name
+`,
+	})
+
+	processor := NewProcessor()
+	result, err := processor.ProcessChart(server.URL + "/mychart.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart failed: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, q := range result.Questions.Questions {
+		counts[q.Variable]++
+		if q.Variable == "service.type" {
+			if q.Label != "Service Type" || q.Type != "enum" || len(q.Options) != 2 {
+				t.Errorf("Expected the richer service.type question to survive, got %+v", q)
+			}
+		}
+	}
+
+	for variable, count := range counts {
+		if count != 1 {
+			t.Errorf("Expected variable %s once, found %d times", variable, count)
+		}
+	}
+	if counts["service.type"] != 1 || counts["name"] != 1 || counts["namespace"] != 1 {
+		t.Errorf("Unexpected question set: %v", counts)
+	}
+}
+
+func TestParseQuestionsCandidates(t *testing.T) {
+	questionsFile := func(variable string) string {
+		return "questions:\n- variable: " + variable + "\n  label: Test\n"
+	}
+
+	tests := []struct {
+		name       string
+		candidates []string
+		files      map[string]string
+		expected   string
+	}{
+		{
+			name:     "rancher subpath",
+			files:    map[string]string{"mychart/rancher/questions.yaml": questionsFile("from.rancher")},
+			expected: "from.rancher",
+		},
+		{
+			name:       "candidates are tried in order",
+			candidates: []string{"rancher/questions.yaml", "questions.yaml"},
+			files: map[string]string{
+				"mychart/questions.yaml":         questionsFile("from.root"),
+				"mychart/rancher/questions.yaml": questionsFile("from.rancher"),
+			},
+			expected: "from.rancher",
+		},
+		{
+			name:       "custom file name",
+			candidates: []string{"ui/form.yaml", "questions.yaml"},
+			files: map[string]string{
+				"mychart/questions.yaml": questionsFile("from.default"),
+				"mychart/ui/form.yaml":   questionsFile("from.custom"),
+			},
+			expected: "from.custom",
+		},
+		{
+			name:       "subpath must match whole segments",
+			candidates: []string{"ui/form.yaml"},
+			files:      map[string]string{"mychart/myui/form.yaml": questionsFile("from.other")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewProcessor()
+			if tt.candidates != nil {
+				processor.QuestionsFiles = tt.candidates
+			}
+			chartDir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(chartDir, filepath.FromSlash(name))
+				os.MkdirAll(filepath.Dir(path), 0755)
+				os.WriteFile(path, []byte(content), 0644)
+			}
+
+			questions, err := processor.parseQuestions(chartDir)
+			if tt.expected == "" {
+				if err == nil {
+					t.Errorf("Expected no questions file to be found, got %+v", questions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseQuestions failed: %v", err)
+			}
+			if len(questions.Questions) != 1 || questions.Questions[0].Variable != tt.expected {
+				t.Errorf("Expected questions from %s, got %+v", tt.expected, questions.Questions)
+			}
+		})
+	}
+}
+
+func TestQuestionsFilesFromEnv(t *testing.T) {
+	t.Setenv("QUESTIONS_FILES", "")
+	if got := NewProcessor().QuestionsFiles; !reflect.DeepEqual(got, defaultQuestionsFiles) {
+		t.Errorf("Expected the default questions files, got %v", got)
+	}
+
+	t.Setenv("QUESTIONS_FILES", " ui/form.yaml, ,questions.yaml ")
+	if got := NewProcessor().QuestionsFiles; !reflect.DeepEqual(got, []string{"ui/form.yaml", "questions.yaml"}) {
+		t.Errorf("Unexpected questions files: %v", got)
+	}
+}
+
+// newChartServer serves a gzipped chart archive built from files on every path.
+func newChartServer(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	archive := buildChartArchive(t, files)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func buildChartArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		header := &tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write tar content: %v", err)
+		}
+	}
+	tw.Close()
+	gzw.Close()
+	return buf.Bytes()
+}
+
+func TestParseValuesEmpty(t *testing.T) {
+	processor := NewProcessor()
+
+	tests := []struct {
+		name   string
+		values string
+	}{
+		{"empty", ""},
+		{"whitespace", "  \n\t\n"},
+		{"comments only", "# Default values for mychart.\n# replicaCount: 1\n"},
+		{"document marker", "---\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chartDir := t.TempDir()
+			os.WriteFile(filepath.Join(chartDir, "values.yaml"), []byte(tt.values), 0644)
+
+			values, err := processor.parseValues(chartDir)
+			if err != nil {
+				t.Fatalf("parseValues failed: %v", err)
+			}
+			if values == nil || len(values) != 0 {
+				t.Fatalf("Expected an empty, non-nil map, got %#v", values)
+			}
+
+			if processor.hasNestedKey(values, "service", "type") {
+				t.Error("Expected no nested keys in empty values")
+			}
+			questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+			if len(questions) != 2 || findQuestion(questions, "name") == nil || findQuestion(questions, "namespace") == nil {
+				t.Errorf("Expected only the name and namespace questions, got %+v", questions)
+			}
+		})
+	}
+}
+
+func TestParseChartMeta(t *testing.T) {
+	processor := NewProcessor()
+	chartDir := t.TempDir()
+	chartYAML := `apiVersion: v2
+name: ollama
+version: 1.16.0
+appVersion: 0.1.26
+description: Get up and running with large language models
+icon: https://example.com/ollama.png
+keywords:
+  - ai
+  - llm
+home: https://ollama.com
+sources:
+  - https://github.com/example/ollama-helm
+maintainers:
+  - name: Jane Doe
+    email: jane@example.com
+  - name: Platform Team
+    url: https://example.com/team
+`
+	os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte(chartYAML), 0644)
+
+	meta, err := processor.parseChartMeta(chartDir)
+	if err != nil {
+		t.Fatalf("parseChartMeta failed: %v", err)
+	}
+	if meta == nil {
+		t.Fatal("Expected chart metadata, got nil")
+	}
+	if meta.Name != "ollama" || meta.Version != "1.16.0" || meta.AppVersion != "0.1.26" {
+		t.Errorf("Unexpected name/version/appVersion: %+v", meta)
+	}
+	if meta.Description != "Get up and running with large language models" {
+		t.Errorf("Unexpected description: %s", meta.Description)
+	}
+	if meta.Icon != "https://example.com/ollama.png" {
+		t.Errorf("Unexpected icon: %s", meta.Icon)
+	}
+	if len(meta.Keywords) != 2 || meta.Keywords[0] != "ai" {
+		t.Errorf("Unexpected keywords: %v", meta.Keywords)
+	}
+	if meta.Home != "https://ollama.com" {
+		t.Errorf("Unexpected home: %s", meta.Home)
+	}
+	if len(meta.Sources) != 1 || meta.Sources[0] != "https://github.com/example/ollama-helm" {
+		t.Errorf("Unexpected sources: %v", meta.Sources)
+	}
+	expectedMaintainers := []models.Maintainer{
+		{Name: "Jane Doe", Email: "jane@example.com"},
+		{Name: "Platform Team", URL: "https://example.com/team"},
+	}
+	if !reflect.DeepEqual(meta.Maintainers, expectedMaintainers) {
+		t.Errorf("Unexpected maintainers: %+v", meta.Maintainers)
+	}
+
+	// A chart without Chart.yaml has no metadata
+	meta, err = processor.parseChartMeta(t.TempDir())
+	if err != nil || meta != nil {
+		t.Errorf("Expected nil metadata without error, got %+v, %v", meta, err)
+	}
+}
+
+func TestProcessChartIgnoresSubchartSchema(t *testing.T) {
+	server := newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":                      "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml":                     "replicaCount: 1\n",
+		"mychart/charts/redis/Chart.yaml":         "name: redis\nversion: 1.0.0\n",
+		"mychart/charts/redis/values.yaml":        "port: 6379\n",
+		"mychart/charts/redis/values.schema.json": `{"type": "object", "required": ["port"]}`,
+	})
+
+	processor := NewProcessor()
+	result, err := processor.ProcessChart(server.URL + "/mychart.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart failed: %v", err)
+	}
+	if result.ValuesSchema != "" {
+		t.Errorf("Expected no schema for a chart without its own, got %q", result.ValuesSchema)
+	}
+
+	server = newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":                      "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml":                     "replicaCount: 1\n",
+		"mychart/values.schema.json":              `{"type": "object"}`,
+		"mychart/charts/redis/Chart.yaml":         "name: redis\nversion: 1.0.0\n",
+		"mychart/charts/redis/values.schema.json": `{"type": "object", "required": ["port"]}`,
+	})
+	result, err = processor.ProcessChart(server.URL + "/mychart.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart failed: %v", err)
+	}
+	if result.ValuesSchema != `{"type": "object"}` {
+		t.Errorf("Expected the root chart's schema, got %q", result.ValuesSchema)
+	}
+}
+
+func TestProcessChartIgnoresSubchartNotes(t *testing.T) {
+	server := newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":                       "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml":                      "replicaCount: 1\n",
+		"mychart/charts/redis/Chart.yaml":          "name: redis\nversion: 1.0.0\n",
+		"mychart/charts/redis/templates/NOTES.txt": "Redis is ready.\n",
+	})
+
+	processor := NewProcessor()
+	result, err := processor.ProcessChart(server.URL + "/mychart.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart failed: %v", err)
+	}
+	if result.Notes != "" {
+		t.Errorf("Expected no notes for a chart without its own, got %q", result.Notes)
+	}
+
+	server = newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":                       "name: mychart\nversion: 1.0.0\n",
+		"mychart/templates/NOTES.txt":              "Thanks for installing mychart.\n",
+		"mychart/charts/redis/Chart.yaml":          "name: redis\nversion: 1.0.0\n",
+		"mychart/charts/redis/templates/NOTES.txt": "Redis is ready.\n",
+	})
+	result, err = processor.ProcessChart(server.URL + "/mychart.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart failed: %v", err)
+	}
+	if result.Notes != "Thanks for installing mychart.\n" {
+		t.Errorf("Expected the root chart's notes, got %q", result.Notes)
+	}
+}
+
+func TestProcessChartIgnoresSubchartReadme(t *testing.T) {
+	server := newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":              "name: mychart\nversion: 1.0.0\n",
+		"mychart/values.yaml":             "replicaCount: 1\n",
+		"mychart/charts/redis/Chart.yaml": "name: redis\nversion: 1.0.0\n",
+		"mychart/charts/redis/README.md":  "# Redis\n",
+	})
+
+	processor := NewProcessor()
+	result, err := processor.ProcessChart(server.URL + "/mychart.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart failed: %v", err)
+	}
+	if result.Readme != "" {
+		t.Errorf("Expected no README for a chart without its own, got %q", result.Readme)
+	}
+
+	server = newChartServer(t, map[string]string{
+		"mychart/Chart.yaml":              "name: mychart\nversion: 1.0.0\n",
+		"mychart/README.md":               "# My Chart\n",
+		"mychart/charts/redis/Chart.yaml": "name: redis\nversion: 1.0.0\n",
+		"mychart/charts/redis/README.md":  "# Redis\n",
+	})
+	result, err = processor.ProcessChart(server.URL + "/mychart.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart failed: %v", err)
+	}
+	if result.Readme != "# My Chart\n" {
+		t.Errorf("Expected the root chart's README, got %q", result.Readme)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	short := "short readme"
+	if got := truncateText(short, 100); got != short {
+		t.Errorf("Expected short text to be unchanged, got %q", got)
+	}
+
+	long := strings.Repeat("é", 100) // two bytes per rune
+	got := truncateText(long, 51)
+	if !strings.HasSuffix(got, "... (truncated)") {
+		t.Errorf("Expected truncation marker, got %q", got)
+	}
+	body := strings.TrimSuffix(got, "\n\n... (truncated)")
+	if len(body) != 50 || !utf8.ValidString(body) {
+		t.Errorf("Expected 50 bytes of valid UTF-8 before the marker, got %d bytes", len(body))
+	}
+}
+
+func TestProcessChartOCIWithoutHelm(t *testing.T) {
+	// An empty PATH guarantees helm cannot be found.
+	t.Setenv("PATH", t.TempDir())
+
+	processor := NewProcessor()
+	result, err := processor.ProcessChart("oci://registry.example.com/charts/ollama:1.0.0")
+	if err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+
+	if result.HelmAvailable == nil || *result.HelmAvailable {
+		t.Fatalf("Expected HelmAvailable to be false, got %v", result.HelmAvailable)
+	}
+	if len(result.Values) == 0 {
+		t.Error("Expected example values when helm is unavailable")
+	}
+
+	if _, err := processor.downloadFromOCI(context.Background(), "oci://registry.example.com/charts/ollama:1.0.0"); !errors.Is(err, ErrHelmUnavailable) {
+		t.Errorf("Expected ErrHelmUnavailable, got %v", err)
+	}
+}
+
+func TestProcessChartHTTPOmitsHelmAvailable(t *testing.T) {
+	server := newChartServer(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "replicaCount: 1\n",
+	})
+
+	result, err := NewProcessor().ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+	if result.HelmAvailable != nil {
+		t.Errorf("Expected HelmAvailable to be unset for HTTP charts, got %v", *result.HelmAvailable)
+	}
+}
+
+func TestProcessChartSeedsImageTagFromAppVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+		want   interface{}
+	}{
+		{"empty tag", "image:\n  repository: nginx\n  tag: \"\"\n", "1.25.3"},
+		{"explicit tag", "image:\n  repository: nginx\n  tag: \"1.24\"\n", "1.24"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newChartServer(t, map[string]string{
+				"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\nappVersion: 1.25.3\n",
+				"testchart/values.yaml": tt.values,
+			})
+
+			result, err := NewProcessor().ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+			if err != nil {
+				t.Fatalf("ProcessChart() error = %v", err)
+			}
+			q := findQuestion(result.Questions.Questions, "image.tag")
+			if q == nil || q.Default != tt.want {
+				t.Errorf("Expected image.tag default %v, got %+v", tt.want, q)
+			}
+		})
+	}
+}
+
+func TestProcessChartTruncatesQuestions(t *testing.T) {
+	var values strings.Builder
+	values.WriteString("image:\n  tag: \"1.0\"\nreplicaCount: 1\nextra:\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&values, "  setting%03d:\n    value: %d\n", i, i)
+	}
+	server := newChartServer(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": values.String(),
+	})
+
+	processor := NewProcessor()
+	processor.MaxQuestions = 20
+	result, err := processor.ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+
+	if !result.QuestionsTruncated {
+		t.Error("Expected QuestionsTruncated to be set")
+	}
+	if len(result.Questions.Questions) != 20 {
+		t.Errorf("Expected 20 questions, got %d", len(result.Questions.Questions))
+	}
+	for _, variable := range []string{"name", "namespace", "replicaCount", "image.tag"} {
+		if findQuestion(result.Questions.Questions, variable) == nil {
+			t.Errorf("Expected %s to survive truncation", variable)
+		}
+	}
+
+	processor.MaxQuestions = 0
+	result, err = processor.ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+	if err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+	if result.QuestionsTruncated || len(result.Questions.Questions) != 104 {
+		t.Errorf("Expected all 104 questions without a cap, got %d (truncated=%v)",
+			len(result.Questions.Questions), result.QuestionsTruncated)
+	}
+}
+
+func TestProcessChartContextCancelsDownload(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Send part of the archive, then stall until the client gives up
+		w.Header().Set("Content-Length", "1048576")
+		w.Write(make([]byte, 1024))
+		w.(http.Flusher).Flush()
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := NewProcessor().ProcessChartContext(ctx, server.URL+"/testchart-0.1.0.tgz")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected cancellation to return promptly, took %s", elapsed)
+	}
+}
+
+func TestProcessChartRetriesTransientFailures(t *testing.T) {
+	archive := buildChartArchive(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "replicaCount: 1\n",
+	})
+
+	tests := []struct {
+		name       string
+		failures   int
+		failStatus int
+		wantErr    bool
+		wantHits   int32
+	}{
+		{"recovers after two 503s", 2, http.StatusServiceUnavailable, false, 3},
+		{"gives up after max attempts", 5, http.StatusBadGateway, true, 3},
+		{"does not retry 404", 5, http.StatusNotFound, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&hits, 1) <= int32(tt.failures) {
+					w.WriteHeader(tt.failStatus)
+					return
+				}
+				w.Write(archive)
+			}))
+			defer server.Close()
+
+			processor := NewProcessor()
+			processor.RetryBackoff = time.Millisecond
+			result, err := processor.ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessChart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&hits); got != tt.wantHits {
+				t.Errorf("Expected %d requests, got %d", tt.wantHits, got)
+			}
+			if !tt.wantErr && (result.Chart == nil || result.Chart.Name != "testchart") {
+				t.Errorf("Expected the chart to be processed, got %+v", result.Chart)
+			}
+		})
+	}
+}
+
+func TestDownloadAndExtractStream(t *testing.T) {
+	archive := buildChartArchive(t, map[string]string{
+		"testchart/Chart.yaml":   "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml":  "replicaCount: 1\n",
+		"../escaped/values.yaml": "escaped: true\n",
+	})
+
+	var hits int32
+	failures := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) <= atomic.LoadInt32(&failures) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+	chartURL := server.URL + "/testchart-0.1.0.tgz"
+
+	processor := NewProcessor()
+	processor.tempDir = t.TempDir()
+	processor.StreamDownloads = true
+	processor.RetryBackoff = time.Millisecond
+
+	chartDir, err := processor.downloadAndExtract(context.Background(), chartURL)
+	if err != nil {
+		t.Fatalf("downloadAndExtract failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(chartDir, "testchart", "values.yaml"))
+	if err != nil || string(data) != "replicaCount: 1\n" {
+		t.Errorf("Expected values.yaml to be extracted, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(processor.tempDir, "escaped")); !os.IsNotExist(err) {
+		t.Error("Expected the traversal entry to be skipped")
+	}
+	if archives, _ := filepath.Glob(filepath.Join(processor.tempDir, "chart-*.tgz")); len(archives) != 0 {
+		t.Errorf("Expected no temporary archive when streaming, found %v", archives)
+	}
+	os.RemoveAll(chartDir)
+
+	// A transient failure falls back to the temporary file path and its retries
+	atomic.StoreInt32(&hits, 0)
+	atomic.StoreInt32(&failures, 2)
+	chartDir, err = processor.downloadAndExtract(context.Background(), chartURL)
+	if err != nil {
+		t.Fatalf("downloadAndExtract with retries failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("Expected 3 requests, got %d", got)
+	}
+	if _, err := os.Stat(filepath.Join(chartDir, "testchart", "Chart.yaml")); err != nil {
+		t.Errorf("Expected Chart.yaml after retrying: %v", err)
+	}
+	os.RemoveAll(chartDir)
+
+	// The size limit applies while streaming, and nothing is left behind
+	atomic.StoreInt32(&failures, 0)
+	processor.MaxChartSize = 32
+	_, err = processor.downloadAndExtract(context.Background(), chartURL)
+	if !errors.Is(err, ErrChartTooLarge) {
+		t.Fatalf("Expected ErrChartTooLarge, got %v", err)
+	}
+	if leftover, _ := filepath.Glob(filepath.Join(processor.tempDir, "extracted-*")); len(leftover) != 0 {
+		t.Errorf("Expected a failed extraction to be removed, found %v", leftover)
+	}
+}
+
+func TestProcessChartWithRepositoryCredentials(t *testing.T) {
+	t.Setenv("DEFAULT_REPOSITORIES", "")
+	archive := buildChartArchive(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "replicaCount: 1\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+	chartURL := server.URL + "/testchart-0.1.0.tgz"
+
+	rm := NewRepositoryManager()
+	processor := NewProcessor()
+	processor.Credentials = rm.AuthForURL
+
+	if _, err := processor.ProcessChart(chartURL); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Expected 401 without cached credentials, got %v", err)
+	}
+
+	auth := &models.Authentication{Username: "admin", Password: "secret"}
+	if err := rm.AddRepositoryWithAuth("private", server.URL, "", "http", auth); err != nil {
+		t.Fatalf("AddRepositoryWithAuth() error = %v", err)
+	}
+	result, err := processor.ProcessChart(chartURL)
+	if err != nil {
+		t.Fatalf("ProcessChart() with cached credentials error = %v", err)
+	}
+	if result.Chart == nil || result.Chart.Name != "testchart" {
+		t.Errorf("Expected testchart, got %+v", result.Chart)
+	}
+}
+
+func TestDownloadDropsCredentialsOnCrossHostRedirect(t *testing.T) {
+	archive := buildChartArchive(t, map[string]string{
+		"testchart/Chart.yaml": "name: testchart\nversion: 0.1.0\n",
+	})
+	var leaked string
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization")
+		w.Write(archive)
+	}))
+	defer cdn.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.Redirect(w, r, cdn.URL+r.URL.Path, http.StatusFound)
+	}))
+	defer origin.Close()
+
+	processor := NewProcessor()
+	processor.Credentials = func(string) *models.Authentication {
+		return &models.Authentication{Token: "token123"}
+	}
+	if _, err := processor.ProcessChart(origin.URL + "/testchart-0.1.0.tgz"); err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+	if leaked != "" {
+		t.Errorf("Expected no credentials on the redirected request, got %q", leaked)
+	}
+}
+
+// TestProcessChartConcurrent exercises a shared Processor the way the API
+// handlers do. Run with -race to detect data races.
+func TestProcessChartConcurrent(t *testing.T) {
+	server := newChartServer(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "replicaCount: 1\nservice:\n  type: ClusterIP\n",
+		"testchart/README.md":   "# testchart\n",
+	})
+
+	processor := NewProcessor()
+	processor.tempDir = t.TempDir()
+
+	const workers = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := processor.ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+			if err != nil {
+				errs <- err
+				return
+			}
+			if result.Chart == nil || result.Chart.Name != "testchart" || result.Values["replicaCount"] != 1 {
+				errs <- fmt.Errorf("unexpected result: %+v", result)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	leftovers, err := os.ReadDir(processor.tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leftovers) != 0 {
+		t.Errorf("Expected temp dir to be cleaned up, found %d entries", len(leftovers))
+	}
+}
+
 func TestFindFile(t *testing.T) {
 	processor := NewProcessor()
 	
@@ -333,7 +1149,7 @@ func BenchmarkGenerateDefaultQuestions(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		processor.generateDefaultQuestions(values)
+		processor.generateDefaultQuestions(values, ProfileStandard)
 	}
 }
 
@@ -0,0 +1,77 @@
+package helm
+
+import (
+	"fmt"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// Profile selects how many questions are generated from a chart's values.
+type Profile string
+
+const (
+	// ProfileMinimal keeps the hand-written questions and only the commonly
+	// configured keys near the top of values.
+	ProfileMinimal Profile = "minimal"
+	// ProfileStandard walks all values, capped at MaxQuestions.
+	ProfileStandard Profile = "standard"
+	// ProfileExhaustive walks all values without a cap, adds preset
+	// selectors, and offers empty maps as YAML blocks.
+	ProfileExhaustive Profile = "exhaustive"
+)
+
+// Profiles lists the valid generation profiles.
+func Profiles() []Profile {
+	return []Profile{ProfileMinimal, ProfileStandard, ProfileExhaustive}
+}
+
+// ParseProfile returns the profile named s; an empty name selects
+// ProfileStandard.
+func ParseProfile(s string) (Profile, error) {
+	if s == "" {
+		return ProfileStandard, nil
+	}
+	for _, profile := range Profiles() {
+		if string(profile) == s {
+			return profile, nil
+		}
+	}
+	return "", fmt.Errorf("unknown profile %q", s)
+}
+
+// minimalMaxPriority is the highest questionPriority kept by ProfileMinimal:
+// common top-level keys and common keys one level down.
+const minimalMaxPriority = 1
+
+// minimalQuestions keeps the questions ProfileMinimal offers.
+func minimalQuestions(questions []models.Question) []models.Question {
+	var kept []models.Question
+	for _, q := range questions {
+		if questionPriority(q) <= minimalMaxPriority {
+			kept = append(kept, q)
+		}
+	}
+	return kept
+}
+
+// emptyMapQuestions emits a multiline YAML question for every empty map in
+// values, such as `podAnnotations: {}`, which the value walk skips.
+func emptyMapQuestions(values map[string]interface{}, path []string) []models.Question {
+	var questions []models.Question
+	for _, key := range sortedKeys(values) {
+		nested, ok := values[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		keyPath := append(append([]string(nil), path...), key)
+		if len(nested) > 0 {
+			questions = append(questions, emptyMapQuestions(nested, keyPath)...)
+			continue
+		}
+		question := pathQuestion(keyPath)
+		question.Type = "multiline"
+		question.Description = "Map of keys to values, edited as YAML"
+		questions = append(questions, question)
+	}
+	return questions
+}
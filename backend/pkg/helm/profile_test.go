@@ -0,0 +1,99 @@
+package helm
+
+import "testing"
+
+func profileValues() map[string]interface{} {
+	return map[string]interface{}{
+		"replicaCount": 1,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.25",
+		},
+		"service": map[string]interface{}{
+			"type": "ClusterIP",
+			"port": 80,
+		},
+		"extraArgs":      "--verbose",
+		"podAnnotations": map[string]interface{}{},
+		"config": map[string]interface{}{
+			"cache": map[string]interface{}{
+				"ttl": 60,
+			},
+		},
+	}
+}
+
+func TestGenerationProfiles(t *testing.T) {
+	processor := NewProcessor()
+
+	counts := make(map[Profile]int)
+	for _, profile := range Profiles() {
+		counts[profile] = len(processor.generateDefaultQuestions(profileValues(), profile).Questions)
+	}
+	if !(counts[ProfileMinimal] < counts[ProfileStandard] && counts[ProfileStandard] < counts[ProfileExhaustive]) {
+		t.Errorf("Expected question counts to grow across profiles, got %v", counts)
+	}
+
+	tests := []struct {
+		profile  Profile
+		variable string
+		want     bool
+	}{
+		{ProfileMinimal, "name", true},
+		{ProfileMinimal, "replicaCount", true},
+		{ProfileMinimal, "image.repository", true},
+		{ProfileMinimal, "service.port", true},
+		{ProfileMinimal, "extraArgs", false},
+		{ProfileMinimal, "config.cache.ttl", false},
+		{ProfileStandard, "extraArgs", true},
+		{ProfileStandard, "config.cache.ttl", true},
+		{ProfileStandard, "podAnnotations", false},
+		{ProfileExhaustive, "config.cache.ttl", true},
+		{ProfileExhaustive, "podAnnotations", true},
+	}
+
+	for _, tt := range tests {
+		questions := processor.generateDefaultQuestions(profileValues(), tt.profile).Questions
+		if got := findQuestion(questions, tt.variable) != nil; got != tt.want {
+			t.Errorf("%s profile: question %s present = %v, want %v", tt.profile, tt.variable, got, tt.want)
+		}
+	}
+
+	questions := processor.generateDefaultQuestions(profileValues(), ProfileExhaustive).Questions
+	if q := findQuestion(questions, "podAnnotations"); q != nil && q.Type != "multiline" {
+		t.Errorf("Expected empty map question to be multiline, got %s", q.Type)
+	}
+}
+
+func TestGenerationProfilesCap(t *testing.T) {
+	processor := NewProcessor()
+	processor.MaxQuestions = 3
+
+	if _, truncated := processor.generateQuestions(profileValues(), ProfileStandard); !truncated {
+		t.Error("Expected the standard profile to be capped by MaxQuestions")
+	}
+	questions, truncated := processor.generateQuestions(profileValues(), ProfileExhaustive)
+	if truncated || len(questions.Questions) <= 3 {
+		t.Errorf("Expected the exhaustive profile to ignore MaxQuestions, got %d questions, truncated=%v", len(questions.Questions), truncated)
+	}
+}
+
+func TestParseProfile(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Profile
+		wantErr bool
+	}{
+		{"", ProfileStandard, false},
+		{"minimal", ProfileMinimal, false},
+		{"exhaustive", ProfileExhaustive, false},
+		{"verbose", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseProfile(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseProfile(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package helm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+// signedChartServer serves a chart archive and a provenance file signed for
+// signedArchive, which may differ from the archive served to simulate
+// tampering. It returns the server and the path of the public keyring.
+func signedChartServer(t *testing.T, served, signedArchive []byte) (*httptest.Server, string) {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("Chart Signer", "", "signer@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keyring bytes.Buffer
+	w, err := armor.Encode(&keyring, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	keyringPath := filepath.Join(t.TempDir(), "pubring.gpg")
+	if err := os.WriteFile(keyringPath, keyring.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256(signedArchive)
+	var prov bytes.Buffer
+	signer, err := clearsign.Encode(&prov, entity.PrivateKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer.Write([]byte("name: testchart\nversion: 0.1.0\n\n...\nfiles:\n  testchart-0.1.0.tgz: sha256:" + hex.EncodeToString(digest[:]) + "\n"))
+	signer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/testchart-0.1.0.tgz":
+			w.Write(served)
+		case "/testchart-0.1.0.tgz.prov":
+			w.Write(prov.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, keyringPath
+}
+
+func TestProcessChartProvenance(t *testing.T) {
+	original := buildChartArchive(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "replicaCount: 1\n",
+	})
+	tampered := buildChartArchive(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "replicaCount: 1\nimage: attacker/backdoor\n",
+	})
+
+	tests := []struct {
+		name    string
+		served  []byte
+		verify  bool
+		wantErr bool
+	}{
+		{name: "verified chart", served: original, verify: true},
+		{name: "tampered chart", served: tampered, verify: true, wantErr: true},
+		{name: "verification disabled", served: tampered, verify: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, keyring := signedChartServer(t, tt.served, original)
+
+			processor := NewProcessor()
+			processor.tempDir = t.TempDir()
+			processor.VerifyCharts = tt.verify
+			processor.Keyring = keyring
+
+			result, err := processor.ProcessChart(server.URL + "/testchart-0.1.0.tgz")
+			if tt.wantErr {
+				if !errors.Is(err, ErrVerificationFailed) {
+					t.Fatalf("Expected ErrVerificationFailed, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessChart() error = %v", err)
+			}
+			if result.Values["replicaCount"] != 1 {
+				t.Errorf("Unexpected values: %v", result.Values)
+			}
+		})
+	}
+}
+
+func TestProcessChartProvenancePrivateRepository(t *testing.T) {
+	archive := buildChartArchive(t, map[string]string{
+		"testchart/Chart.yaml":  "name: testchart\nversion: 0.1.0\n",
+		"testchart/values.yaml": "replicaCount: 1\n",
+	})
+	public, keyring := signedChartServer(t, archive, archive)
+
+	// The private repository rejects every anonymous request, .prov included
+	private := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		resp, err := http.Get(public.URL + r.URL.Path)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	defer private.Close()
+
+	processor := NewProcessor()
+	processor.tempDir = t.TempDir()
+	processor.VerifyCharts = true
+	processor.Keyring = keyring
+	processor.Credentials = func(chartURL string) *models.Authentication {
+		return &models.Authentication{Username: "user", Password: "secret"}
+	}
+
+	if _, err := processor.ProcessChart(private.URL + "/testchart-0.1.0.tgz"); err != nil {
+		t.Errorf("Expected the chart to verify with repository credentials, got %v", err)
+	}
+}
+
+func TestProcessChartWithoutProvenanceFile(t *testing.T) {
+	archive := buildChartArchive(t, map[string]string{
+		"testchart/Chart.yaml": "name: testchart\nversion: 0.1.0\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/testchart-0.1.0.tgz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	processor := NewProcessor()
+	processor.tempDir = t.TempDir()
+	processor.VerifyCharts = true
+	processor.Keyring = filepath.Join(t.TempDir(), "missing.gpg")
+
+	if _, err := processor.ProcessChart(server.URL + "/testchart-0.1.0.tgz"); err != nil {
+		t.Errorf("Expected chart without provenance to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package helm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// registryConfig is the subset of helm's registry config.json (the Docker
+// config format) needed to tell whether a registry has stored credentials.
+type registryConfig struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// registryConfigPath returns the registry config helm uses: the file named
+// by HELM_REGISTRY_CONFIG, or registry/config.json under the
+// HELM_CONFIG_HOME that execHelm sets.
+func (rm *RepositoryManager) registryConfigPath() string {
+	if path := os.Getenv("HELM_REGISTRY_CONFIG"); path != "" {
+		return path
+	}
+	return filepath.Join(rm.helmHome, "registry", "config.json")
+}
+
+// hasRegistryConfigCredentials reports whether helm's registry config holds
+// credentials or a credential helper for the registry in loginURL (a host,
+// optionally followed by a path). A missing or unreadable config counts as
+// no credentials.
+func (rm *RepositoryManager) hasRegistryConfigCredentials(loginURL string) bool {
+	data, err := os.ReadFile(rm.registryConfigPath())
+	if err != nil {
+		return false
+	}
+	var config registryConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return false
+	}
+
+	host := strings.Split(loginURL, "/")[0]
+	for key, entry := range config.Auths {
+		key = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://"), "/")
+		if (key == host || key == loginURL) && (entry.Auth != "" || entry.IdentityToken != "") {
+			return true
+		}
+	}
+	_, hasHelper := config.CredHelpers[host]
+	return hasHelper
+}
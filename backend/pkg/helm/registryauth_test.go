@@ -0,0 +1,132 @@
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestHasRegistryConfigCredentials(t *testing.T) {
+	t.Setenv("HELM_REGISTRY_CONFIG", "")
+	rm := &RepositoryManager{helmHome: t.TempDir()}
+
+	if rm.hasRegistryConfigCredentials("registry.example.com") {
+		t.Error("Expected no credentials without a registry config")
+	}
+
+	seedRegistryConfig(t, rm.helmHome, `{
+		"auths": {
+			"registry.example.com": {"auth": "dXNlcjpwYXNz"},
+			"https://mirror.example.com/": {"identitytoken": "token"},
+			"empty.example.com": {}
+		},
+		"credHelpers": {"ecr.example.com": "ecr-login"}
+	}`)
+
+	tests := []struct {
+		loginURL string
+		want     bool
+	}{
+		{"registry.example.com", true},
+		{"registry.example.com/charts", true},
+		{"mirror.example.com", true},
+		{"ecr.example.com/team", true},
+		{"empty.example.com", false},
+		{"other.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := rm.hasRegistryConfigCredentials(tt.loginURL); got != tt.want {
+			t.Errorf("hasRegistryConfigCredentials(%q) = %v, want %v", tt.loginURL, got, tt.want)
+		}
+	}
+}
+
+func TestExistingRegistryCredentialsSkipLogin(t *testing.T) {
+	t.Setenv("HELM_REGISTRY_CONFIG", "")
+	registry := &fakeRegistry{requiresAuth: true, loggedIn: true}
+	rm := NewRepositoryManager()
+	rm.repositories = make(map[string]*models.Repository)
+	rm.authCache = make(map[string]*models.Authentication)
+	rm.helmHome = t.TempDir()
+	rm.runHelm = registry.run
+	seedRegistryConfig(t, rm.helmHome, `{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz"}}}`)
+
+	auth := &models.Authentication{Username: "user", Password: "pass"}
+	if err := rm.AddRepositoryWithAuth("oci-repo", "oci://registry.example.com/charts", "", "oci", auth); err != nil {
+		t.Fatalf("AddRepositoryWithAuth() error = %v", err)
+	}
+	if _, err := rm.PullChart("oci-repo", "app", "1.0.0"); err != nil {
+		t.Fatalf("PullChart() error = %v", err)
+	}
+	for _, command := range registry.commands {
+		if command == "registry login" {
+			t.Fatalf("Expected the existing helm session to be used, got %v", registry.commands)
+		}
+	}
+
+	// Stale credentials in the config don't stop a login after a 401
+	registry.loggedIn = false
+	if _, err := rm.PullChart("oci-repo", "app", "1.0.0"); err != nil {
+		t.Fatalf("PullChart() after session expiry error = %v", err)
+	}
+	if last := registry.commands[len(registry.commands)-2]; last != "registry login" {
+		t.Errorf("Expected a login after the registry rejected the pull, got %v", registry.commands)
+	}
+}
+
+func TestChangedCredentialsLogInAgain(t *testing.T) {
+	t.Setenv("HELM_REGISTRY_CONFIG", "")
+	registry := &fakeRegistry{requiresAuth: true}
+	rm := NewRepositoryManager()
+	rm.repositories = make(map[string]*models.Repository)
+	rm.authCache = make(map[string]*models.Authentication)
+	rm.helmHome = t.TempDir()
+	rm.runHelm = func(args ...string) ([]byte, error) {
+		// Like helm, a login stores the credentials in the registry config
+		if args[0] == "registry" && args[1] == "login" {
+			seedRegistryConfig(t, rm.helmHome, `{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz"}}}`)
+		}
+		return registry.run(args...)
+	}
+
+	countLogins := func() int {
+		logins := 0
+		for _, command := range registry.commands {
+			if command == "registry login" {
+				logins++
+			}
+		}
+		return logins
+	}
+
+	auth := &models.Authentication{Username: "user", Password: "pass"}
+	if err := rm.AddRepositoryWithAuth("oci-repo", "oci://registry.example.com/charts", "", "oci", auth); err != nil {
+		t.Fatalf("AddRepositoryWithAuth() error = %v", err)
+	}
+	if got := countLogins(); got != 1 {
+		t.Fatalf("Expected 1 login, got %d: %v", got, registry.commands)
+	}
+
+	// Our own config entry doesn't stand in for the new credentials
+	rotated := &models.Authentication{Username: "user", Password: "rotated"}
+	if err := rm.ReplaceRepositoryWithAuth("oci-repo", "oci://registry.example.com/charts", "", "oci", rotated); err != nil {
+		t.Fatalf("ReplaceRepositoryWithAuth() error = %v", err)
+	}
+	if got := countLogins(); got != 2 {
+		t.Errorf("Expected a login with the changed credentials, got %d logins: %v", got, registry.commands)
+	}
+}
+
+// seedRegistryConfig writes config as helm's registry config under helmHome.
+func seedRegistryConfig(t *testing.T, helmHome, config string) {
+	t.Helper()
+	dir := filepath.Join(helmHome, "registry")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create registry config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatalf("Failed to write registry config: %v", err)
+	}
+}
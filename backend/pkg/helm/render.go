@@ -0,0 +1,90 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrRenderFailed is returned when helm template rejects a chart with the
+// given values. The error message carries the chart's own failure message,
+// such as one from fail or required, or a summary of helm's output.
+var ErrRenderFailed = errors.New("helm template failed")
+
+// renderReleaseName is the release name used when previewing manifests.
+const renderReleaseName = "preview"
+
+// RenderChart downloads the chart at chartURL again, since processing does
+// not keep it, and renders it with values using helm template. It returns
+// ErrHelmUnavailable without the helm CLI. Cancelling ctx stops the download
+// and helm.
+func (p *Processor) RenderChart(ctx context.Context, chartURL string, values map[string]interface{}) (string, error) {
+	if !p.isHelmAvailable() {
+		return "", ErrHelmUnavailable
+	}
+
+	chartDir, err := p.downloadAndExtract(ctx, chartURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download chart: %w", err)
+	}
+	defer os.RemoveAll(chartDir)
+
+	chartFile := p.findFile(chartDir, "Chart.yaml")
+	if chartFile == "" {
+		return "", fmt.Errorf("%w: chart has no Chart.yaml", ErrRenderFailed)
+	}
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode values: %w", err)
+	}
+	valuesFile := filepath.Join(chartDir, "render-values.yaml")
+	if err := os.WriteFile(valuesFile, data, 0600); err != nil {
+		return "", err
+	}
+
+	// Warnings on stderr must not end up in the manifests
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "helm", "template", renderReleaseName, filepath.Dir(chartFile), "-f", valuesFile)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message, ok := templateFailure(stderr.Bytes()); ok {
+			slog.Debug("helm template failed", "output", stderr.String())
+			return "", fmt.Errorf("%w: %s", ErrRenderFailed, message)
+		}
+		return "", fmt.Errorf("%w: %s", ErrRenderFailed, parseHelmError(stderr.Bytes()))
+	}
+	return stdout.String(), nil
+}
+
+// templateFailure returns the message of a template execution error, e.g.
+// "replicaCount must be positive" from
+//
+//	Error: execution error at (webapp/templates/deployment.yaml:3:14): replicaCount must be positive
+//
+// The message comes from the chart's fail or required calls and is meant for
+// users; the template location is dropped.
+func templateFailure(output []byte) (string, bool) {
+	const marker = "execution error at ("
+	text := string(output)
+	start := strings.Index(text, marker)
+	if start < 0 {
+		return "", false
+	}
+	rest := text[start+len(marker):]
+	end := strings.Index(rest, "): ")
+	if end < 0 {
+		return "", false
+	}
+	message := strings.TrimSpace(strings.SplitN(rest[end+len("): "):], "\n", 2)[0])
+	return message, message != ""
+}
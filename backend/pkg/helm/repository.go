@@ -1,17 +1,27 @@
 package helm
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"rancher-questions-generator/internal/models"
+
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v3"
 )
 
 type RepositoryManager struct {
@@ -19,8 +29,93 @@ type RepositoryManager struct {
 	authCache    map[string]*models.Authentication // baseURL -> auth
 	helmHome     string
 	mutex        sync.RWMutex
+
+	// runHelm executes the helm CLI. It defaults to execHelm and is replaced
+	// in tests to simulate registry responses.
+	runHelm func(args ...string) ([]byte, error)
+
+	// initStatus records the outcome of registering each default repository.
+	initStatus []RepositoryStatus
+
+	// lazyRepoInit defers helm registry logins for default repositories until
+	// a pull is rejected, keeping startup fast and offline-safe. Set from the
+	// LAZY_REPO_INIT environment variable.
+	lazyRepoInit bool
+
+	// logins records successful registry logins by login host so repeated
+	// operations within loginTTL skip `helm registry login`. It has its own
+	// lock because logins happen while mutex is already held.
+	logins     map[string]loginRecord
+	loginMutex sync.Mutex
+
+	// loginTTL is how long a registry login is reused. Set from the
+	// REGISTRY_LOGIN_TTL environment variable.
+	loginTTL time.Duration
+
+	// chartCache holds each repository's charts, keyed by repository name,
+	// for chartCacheTTL (zero disables caching). Set from the CHART_CACHE_TTL
+	// environment variable. It has its own lock because searches fill it
+	// while holding mutex for reading.
+	chartCache    map[string]chartCacheEntry
+	chartCacheTTL time.Duration
+	cacheMutex    sync.RWMutex
+
+	// disableExampleCharts makes searches return only charts fetched from
+	// real repositories, never the built-in example catalog. Set from the
+	// DISABLE_EXAMPLE_CHARTS environment variable.
+	disableExampleCharts bool
+}
+
+// defaultLoginTTL is how long a registry login is trusted by default.
+const defaultLoginTTL = 30 * time.Minute
+
+// loginRecord remembers when a registry was logged in to and with which
+// credentials, so a change of credentials forces a new login.
+type loginRecord struct {
+	at          time.Time
+	fingerprint string
+}
+
+// RepositoryStatus reports whether a default repository was registered at
+// startup and, if not, why.
+type RepositoryStatus struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
 }
 
+// ErrAuthRequired is returned when a registry rejected an anonymous pull and
+// no working credentials were available to retry it.
+var ErrAuthRequired = errors.New("registry authentication required")
+
+// ErrInvalidRepositoryURL is returned when a repository URL is not an
+// http(s) or oci:// URL with a host.
+var ErrInvalidRepositoryURL = errors.New("invalid repository URL")
+
+// ErrRepositoryExists is returned when adding a repository under a name that
+// is already registered without asking to replace it.
+var ErrRepositoryExists = errors.New("repository already exists")
+
+// ErrRepositoryNotFound is returned when a named repository is not
+// registered.
+var ErrRepositoryNotFound = errors.New("repository not found")
+
+// ErrInvalidRepositoryName is returned when a repository name is not a
+// DNS-1123 label, which helm and our lookups rely on.
+var ErrInvalidRepositoryName = errors.New("invalid repository name")
+
+// repositoryNamePattern matches DNS-1123 labels: lower-case alphanumerics and
+// '-', starting and ending with an alphanumeric.
+var repositoryNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// maxRepositoryNameLength is the DNS-1123 label length limit.
+const maxRepositoryNameLength = 63
+
+// ErrHelmUnavailable is returned when an operation needs the helm CLI and it
+// is not installed.
+var ErrHelmUnavailable = errors.New("helm command not found - please install Helm CLI")
+
 func NewRepositoryManager() *RepositoryManager {
 	helmHome := "/tmp/helm-home"
 	os.MkdirAll(helmHome, 0755)
@@ -29,7 +124,16 @@ func NewRepositoryManager() *RepositoryManager {
 		repositories: make(map[string]*models.Repository),
 		authCache:    make(map[string]*models.Authentication),
 		helmHome:     helmHome,
+		lazyRepoInit: envBool("LAZY_REPO_INIT", false),
+		logins:       make(map[string]loginRecord),
+		loginTTL:     envDuration("REGISTRY_LOGIN_TTL", defaultLoginTTL),
+
+		chartCache:    make(map[string]chartCacheEntry),
+		chartCacheTTL: envDuration("CHART_CACHE_TTL", defaultChartCacheTTL),
+
+		disableExampleCharts: envBool("DISABLE_EXAMPLE_CHARTS", false),
 	}
+	rm.runHelm = rm.execHelm
 	
 	// Initialize helm
 	rm.initHelm()
@@ -40,32 +144,107 @@ func NewRepositoryManager() *RepositoryManager {
 	return rm
 }
 
+// DefaultRepository describes a repository registered when the manager starts.
+type DefaultRepository struct {
+	Name        string `json:"name" yaml:"name"`
+	URL         string `json:"url" yaml:"url"`
+	Type        string `json:"type,omitempty" yaml:"type,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Username    string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password    string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+var builtinDefaultRepositories = []DefaultRepository{
+	{Name: "rancher-partner", URL: "https://git.rancher.io/partner-charts", Type: "http", Description: "Rancher Partner Charts Repository"},
+	{Name: "bitnami", URL: "https://charts.bitnami.com/bitnami", Type: "http", Description: "Bitnami Helm Charts"},
+	{Name: "stable", URL: "https://charts.helm.sh/stable", Type: "http", Description: "Helm Stable Charts (Deprecated)"},
+	{Name: "ingress-nginx", URL: "https://kubernetes.github.io/ingress-nginx", Type: "http", Description: "NGINX Ingress Controller"},
+	{Name: "suse-application-collection", URL: "oci://dp.apps.rancher.io/charts", Type: "oci", Description: "SUSE Application Collection (OCI)"},
+}
+
+// loadDefaultRepositories returns the repositories to register at startup.
+// DEFAULT_REPOSITORIES may hold an inline JSON/YAML list and
+// DEFAULT_REPOSITORIES_FILE a path to one; setting either to an empty value
+// disables defaults. When neither is set the built-in list is used. Entries
+// may include a username and password for registries that need a login.
+func loadDefaultRepositories() ([]DefaultRepository, error) {
+	var data []byte
+	if inline, ok := os.LookupEnv("DEFAULT_REPOSITORIES"); ok {
+		data = []byte(inline)
+	} else if path, ok := os.LookupEnv("DEFAULT_REPOSITORIES_FILE"); ok {
+		if strings.TrimSpace(path) == "" {
+			return nil, nil
+		}
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read default repositories file: %w", err)
+		}
+		data = fileData
+	} else {
+		return builtinDefaultRepositories, nil
+	}
+
+	if strings.TrimSpace(string(data)) == "" {
+		return nil, nil
+	}
+
+	// YAML is a superset of JSON, so one parser handles both formats
+	var repos []DefaultRepository
+	if err := yaml.Unmarshal(data, &repos); err != nil {
+		return nil, fmt.Errorf("failed to parse default repositories: %w", err)
+	}
+	for _, repo := range repos {
+		if repo.Name == "" || repo.URL == "" {
+			return nil, fmt.Errorf("default repository entries require a name and url")
+		}
+	}
+
+	return repos, nil
+}
+
 func (rm *RepositoryManager) addDefaultRepositories() {
-	defaultRepos := []struct {
-		name string
-		url  string
-		description string
-		repoType string
-	}{
-		{"rancher-partner", "https://git.rancher.io/partner-charts", "Rancher Partner Charts Repository", "http"},
-		{"bitnami", "https://charts.bitnami.com/bitnami", "Bitnami Helm Charts", "http"},
-		{"stable", "https://charts.helm.sh/stable", "Helm Stable Charts (Deprecated)", "http"},
-		{"ingress-nginx", "https://kubernetes.github.io/ingress-nginx", "NGINX Ingress Controller", "http"},
-		{"suse-application-collection", "oci://dp.apps.rancher.io/charts", "SUSE Application Collection (OCI)", "oci"},
+	defaultRepos, err := loadDefaultRepositories()
+	if err != nil {
+		fmt.Printf("Invalid default repository configuration, using built-in defaults: %v\n", err)
+		defaultRepos = builtinDefaultRepositories
 	}
 	
 	fmt.Printf("Adding %d default repositories...\n", len(defaultRepos))
+	statuses := make([]RepositoryStatus, 0, len(defaultRepos))
 	for _, repo := range defaultRepos {
-		err := rm.AddRepositoryWithAuth(repo.name, repo.url, repo.description, repo.repoType, nil)
+		status := RepositoryStatus{Name: repo.Name, URL: repo.URL, Ready: true}
+		var auth *models.Authentication
+		if repo.Username != "" {
+			auth = &models.Authentication{Username: repo.Username, Password: repo.Password}
+		}
+		// Metadata is always registered now; in lazy mode the registry login
+		// waits until a pull is rejected (see pullOCIChart).
+		err := rm.addRepository(repo.Name, repo.URL, repo.Description, repo.Type, auth, !rm.lazyRepoInit, false)
 		if err != nil {
-			fmt.Printf("Failed to add default repository %s: %v\n", repo.name, err)
+			fmt.Printf("Failed to add default repository %s: %v\n", repo.Name, err)
+			status.Ready = false
+			status.Error = err.Error()
 		} else {
-			fmt.Printf("Added default repository: %s (%s)\n", repo.name, repo.url)
+			fmt.Printf("Added default repository: %s (%s)\n", repo.Name, repo.URL)
 		}
+		statuses = append(statuses, status)
 	}
+
+	rm.mutex.Lock()
+	rm.initStatus = statuses
+	rm.mutex.Unlock()
 	fmt.Printf("Default repositories initialization complete. Total repositories: %d\n", len(rm.repositories))
 }
 
+// InitStatus returns the registration outcome of each default repository, in
+// configuration order, so operators can see which ones failed at startup.
+func (rm *RepositoryManager) InitStatus() []RepositoryStatus {
+	rm.mutex.RLock()
+	defer rm.mutex.RUnlock()
+
+	return append([]RepositoryStatus(nil), rm.initStatus...)
+}
+
 func (rm *RepositoryManager) initHelm() error {
 	// Set HELM_CONFIG_HOME environment variable
 	os.Setenv("HELM_CONFIG_HOME", rm.helmHome)
@@ -80,12 +259,39 @@ func (rm *RepositoryManager) initHelm() error {
 }
 
 func (rm *RepositoryManager) AddRepository(name, url string) error {
-	return rm.AddRepositoryWithAuth(name, url, "", "http", nil)
+	return rm.AddRepositoryWithAuth(name, url, "", "", nil)
 }
 
+// AddRepositoryWithAuth registers a repository, returning ErrRepositoryExists
+// when the name is already taken.
 func (rm *RepositoryManager) AddRepositoryWithAuth(name, repoURL, description, repoType string, auth *models.Authentication) error {
+	return rm.addRepository(name, repoURL, description, repoType, auth, true, false)
+}
+
+// ReplaceRepositoryWithAuth registers a repository like AddRepositoryWithAuth
+// but replaces any existing repository of the same name.
+func (rm *RepositoryManager) ReplaceRepositoryWithAuth(name, repoURL, description, repoType string, auth *models.Authentication) error {
+	return rm.addRepository(name, repoURL, description, repoType, auth, true, true)
+}
+
+// addRepository registers a repository, logging in to OCI registries with
+// auth right away only when login is set. An existing repository of the
+// same name is replaced only when overwrite is set.
+func (rm *RepositoryManager) addRepository(name, repoURL, description, repoType string, auth *models.Authentication, login, overwrite bool) error {
+	if err := validateRepositoryName(name); err != nil {
+		return err
+	}
+	if err := validateRepositoryURL(repoURL); err != nil {
+		return err
+	}
+	description = sanitizeDescription(description)
+
 	rm.mutex.Lock()
 	defer rm.mutex.Unlock()
+
+	if _, exists := rm.repositories[name]; exists && !overwrite {
+		return fmt.Errorf("%w: %s", ErrRepositoryExists, name)
+	}
 	
 	// Determine repository type if not specified
 	if repoType == "" {
@@ -106,7 +312,7 @@ func (rm *RepositoryManager) AddRepositoryWithAuth(name, repoURL, description, r
 		rm.authCache[baseURL] = auth
 		
 		// Perform helm login for OCI repositories
-		if repoType == "oci" {
+		if repoType == "oci" && login {
 			if err := rm.performHelmLogin(repoURL, auth); err != nil {
 				fmt.Printf("Warning: OCI authentication failed: %v\n", err)
 				// Don't fail repository addition if helm is not available
@@ -131,19 +337,66 @@ func (rm *RepositoryManager) AddRepositoryWithAuth(name, repoURL, description, r
 	}
 	
 	rm.repositories[name] = repo
+	rm.forgetCharts(name)
 	
 	return nil
 }
 
+// validateRepositoryName rejects names that helm repo add or our name-keyed
+// lookups could mishandle, such as ones with slashes, spaces or upper case.
+func validateRepositoryName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidRepositoryName)
+	}
+	if len(name) > maxRepositoryNameLength {
+		return fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidRepositoryName, name, maxRepositoryNameLength)
+	}
+	if !repositoryNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q must consist of lower-case letters, digits and '-', and start and end with a letter or digit",
+			ErrInvalidRepositoryName, name)
+	}
+	return nil
+}
+
+// sanitizeDescription drops control characters, which have no place in a
+// one-line description, and surrounding whitespace.
+func sanitizeDescription(description string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, description))
+}
+
+// validateRepositoryURL rejects URLs that neither the index fetcher nor helm
+// could ever read from.
+func validateRepositoryURL(repoURL string) error {
+	parsed, err := url.Parse(repoURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRepositoryURL, err)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "oci":
+	default:
+		return fmt.Errorf("%w: scheme %q is not http, https or oci", ErrInvalidRepositoryURL, parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("%w: %q has no host", ErrInvalidRepositoryURL, repoURL)
+	}
+	return nil
+}
+
 func (rm *RepositoryManager) RemoveRepository(name string) error {
 	rm.mutex.Lock()
 	defer rm.mutex.Unlock()
 	
 	if _, exists := rm.repositories[name]; !exists {
-		return fmt.Errorf("repository %s not found", name)
+		return fmt.Errorf("%w: %s", ErrRepositoryNotFound, name)
 	}
 	
 	delete(rm.repositories, name)
+	rm.forgetCharts(name)
 	
 	return nil
 }
@@ -157,10 +410,12 @@ func (rm *RepositoryManager) ListRepositories() []*models.Repository {
 		repos = append(repos, repo)
 	}
 	
-	fmt.Printf("ListRepositories called: returning %d repositories\n", len(repos))
-	for _, repo := range repos {
-		fmt.Printf("  - %s: %s\n", repo.Name, repo.URL)
-	}
+	// Sort by name so paginated listings are stable between calls
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Name < repos[j].Name
+	})
+	
+	slog.Debug("listing repositories", "count", len(repos))
 	
 	return repos
 }
@@ -171,14 +426,23 @@ func (rm *RepositoryManager) SearchCharts(query, repository string) ([]*models.C
 	
 	// Try to fetch charts from actual Helm repositories first
 	if repository != "" {
-		if repo, exists := rm.repositories[repository]; exists {
+		if repo := rm.findRepository(repository); repo != nil {
 			charts, err := rm.fetchChartsFromRepository(repo)
 			if err == nil && len(charts) > 0 {
 				return rm.filterCharts(charts, query), nil
 			}
+			if rm.disableExampleCharts {
+				fmt.Printf("Failed to fetch charts from repository %s: %v\n", repository, err)
+				return []*models.Chart{}, nil
+			}
 			fmt.Printf("Failed to fetch charts from repository %s, falling back to examples: %v\n", repository, err)
 		}
 	}
+
+	// Production deployments only show real charts
+	if rm.disableExampleCharts {
+		return []*models.Chart{}, nil
+	}
 	
 	// Enhanced chart catalog with more realistic data - fallback for when real repositories aren't accessible
 	exampleCharts := []*models.Chart{
@@ -290,64 +554,172 @@ func (rm *RepositoryManager) SearchCharts(query, repository string) ([]*models.C
 	return rm.filterCharts(exampleCharts, query, repository), nil
 }
 
-// Helper function to filter charts based on query and repository
+// Relevance scores used to rank search results, highest first.
+const (
+	scoreDescription = iota + 1
+	scoreKeyword
+	scoreNameSubstring
+	scoreNamePrefix
+	scoreExactName
+)
+
+// Helper function to filter charts based on query and repository. Results
+// are ordered by relevance (see chartScore), then by name.
 func (rm *RepositoryManager) filterCharts(charts []*models.Chart, query string, repository ...string) []*models.Chart {
-	var filteredCharts []*models.Chart
+	type scoredChart struct {
+		chart *models.Chart
+		score int
+	}
+
+	var matches []scoredChart
 	repo := ""
 	if len(repository) > 0 {
 		repo = repository[0]
 	}
 	
 	for _, chart := range charts {
-		if repo != "" && chart.Repository != repo {
+		if repo != "" && !strings.EqualFold(chart.Repository, repo) {
 			continue
 		}
-		if query != "" {
-			queryLower := strings.ToLower(query)
-			// Support wildcard matching
-			if strings.Contains(query, "*") {
-				// Simple wildcard support
-				pattern := strings.ReplaceAll(queryLower, "*", ".*")
-				matched, _ := filepath.Match(pattern, strings.ToLower(chart.Name))
-				if !matched {
-					continue
-				}
-			} else {
-				// Search in name, description, and keywords
-				found := false
-				if strings.Contains(strings.ToLower(chart.Name), queryLower) {
-					found = true
-				}
-				if strings.Contains(strings.ToLower(chart.Description), queryLower) {
-					found = true
-				}
-				for _, keyword := range chart.Keywords {
-					if strings.Contains(strings.ToLower(keyword), queryLower) {
-						found = true
-						break
-					}
-				}
-				if !found {
-					continue
-				}
-			}
+		score := chartScore(chart, normalizeSearchText(query))
+		if score == 0 {
+			continue
 		}
-		filteredCharts = append(filteredCharts, chart)
+		matches = append(matches, scoredChart{chart, score})
 	}
 	
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].chart.Name < matches[j].chart.Name
+	})
+	
+	var filteredCharts []*models.Chart
+	for _, match := range matches {
+		filteredCharts = append(filteredCharts, match.chart)
+	}
 	return filteredCharts
 }
 
+// DedupeCharts collapses charts with the same name from different
+// repositories into the first, highest ranked, entry and lists the providing
+// repositories in it, in result order. The input charts are not modified.
+func DedupeCharts(charts []*models.Chart) []*models.Chart {
+	indexByName := make(map[string]int)
+	var deduped []*models.Chart
+	for _, chart := range charts {
+		idx, exists := indexByName[chart.Name]
+		if !exists {
+			collapsed := *chart
+			collapsed.Repositories = []string{chart.Repository}
+			indexByName[chart.Name] = len(deduped)
+			deduped = append(deduped, &collapsed)
+			continue
+		}
+		if !containsString(deduped[idx].Repositories, chart.Repository) {
+			deduped[idx].Repositories = append(deduped[idx].Repositories, chart.Repository)
+		}
+	}
+	return deduped
+}
+
+// findRepository looks a repository up by name, ignoring case. Callers must
+// hold rm.mutex.
+func (rm *RepositoryManager) findRepository(name string) *models.Repository {
+	if repo, exists := rm.repositories[name]; exists {
+		return repo
+	}
+	for repoName, repo := range rm.repositories {
+		if strings.EqualFold(repoName, name) {
+			return repo
+		}
+	}
+	return nil
+}
+
+// normalizeSearchText folds text for search comparison: it decomposes
+// characters (NFKD), drops combining marks so "Señor" matches "senor", and
+// lowercases the result.
+func normalizeSearchText(text string) string {
+	decomposed := norm.NFKD.String(text)
+	var b strings.Builder
+	b.Grow(len(decomposed))
+	for _, r := range decomposed {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// chartScore rates how well a chart matches a normalized query: an exact
+// name match beats a name prefix, then a name substring, a keyword and
+// finally the description. Queries containing "*" are glob patterns matched
+// against the name. Zero means no match; an empty query matches everything.
+func chartScore(chart *models.Chart, query string) int {
+	if query == "" {
+		return scoreDescription
+	}
+	
+	name := normalizeSearchText(chart.Name)
+	if strings.Contains(query, "*") {
+		if matched, _ := filepath.Match(query, name); matched {
+			return scoreNameSubstring
+		}
+		return 0
+	}
+	
+	switch {
+	case name == query:
+		return scoreExactName
+	case strings.HasPrefix(name, query):
+		return scoreNamePrefix
+	case strings.Contains(name, query):
+		return scoreNameSubstring
+	}
+	for _, keyword := range chart.Keywords {
+		if strings.Contains(normalizeSearchText(keyword), query) {
+			return scoreKeyword
+		}
+	}
+	if strings.Contains(normalizeSearchText(chart.Description), query) {
+		return scoreDescription
+	}
+	return 0
+}
+
 // Fetch charts from actual Helm repository (attempts real repository access)
+// fetchChartsFromRepository returns a repository's charts, from the chart
+// cache while it is fresh.
 func (rm *RepositoryManager) fetchChartsFromRepository(repo *models.Repository) ([]*models.Chart, error) {
+	if charts, ok := rm.cachedCharts(repo.Name); ok {
+		return charts, nil
+	}
+	charts, err := rm.fetchChartsUncached(repo)
+	if err == nil && len(charts) > 0 {
+		rm.storeCharts(repo.Name, charts)
+	}
+	return charts, err
+}
+
+func (rm *RepositoryManager) fetchChartsUncached(repo *models.Repository) ([]*models.Chart, error) {
 	if repo.Type == "oci" {
 		return rm.fetchOCICharts(repo)
 	}
 	return rm.fetchHTTPCharts(repo)
 }
 
-// Fetch charts from HTTP-based Helm repository
+// Fetch charts from HTTP-based Helm repository. The index.yaml is read
+// directly when possible; the helm CLI is only used as a fallback.
 func (rm *RepositoryManager) fetchHTTPCharts(repo *models.Repository) ([]*models.Chart, error) {
+	charts, err := rm.fetchIndexCharts(repo)
+	if err == nil {
+		return charts, nil
+	}
+	fmt.Printf("Warning: Failed to read index of repo %s, trying helm CLI: %v\n", repo.Name, err)
+	
 	// Add repository to helm if not already added
 	if err := rm.addHelmRepo(repo); err != nil {
 		fmt.Printf("Warning: Failed to add Helm repo %s: %v\n", repo.Name, err)
@@ -362,7 +734,7 @@ func (rm *RepositoryManager) fetchHTTPCharts(repo *models.Repository) ([]*models
 	}
 	
 	// Search for charts in the repository
-	charts, err := rm.searchHelmCharts(repo.Name)
+	charts, err = rm.searchHelmCharts(repo.Name)
 	if err != nil {
 		fmt.Printf("Warning: Failed to search charts in repo %s: %v\n", repo.Name, err)
 		return nil, err
@@ -712,6 +1084,11 @@ func (rm *RepositoryManager) fetchOCICharts(repo *models.Repository) ([]*models.
 			Keywords:    []string{"velero", "backup", "restore", "disaster recovery"},
 		},
 	}
+
+	// Registries list cosign signatures and attestations as tags too
+	for _, chart := range suseCharts {
+		chart.Versions = chartVersionTags(chart.Versions)
+	}
 	
 	return suseCharts, nil
 }
@@ -758,10 +1135,6 @@ func (rm *RepositoryManager) updateHelmRepo(repoName string) error {
 
 // Search charts in Helm repository
 func (rm *RepositoryManager) searchHelmCharts(repoName string) ([]*models.Chart, error) {
-	if !rm.isHelmAvailable() {
-		return nil, fmt.Errorf("helm CLI not available")
-	}
-	
 	args := []string{"search", "repo", repoName, "--output", "json"}
 	output, err := rm.runHelmCommand(args...)
 	if err != nil {
@@ -780,6 +1153,10 @@ func (rm *RepositoryManager) searchHelmCharts(repoName string) ([]*models.Chart,
 		return nil, fmt.Errorf("failed to parse helm search output: %w", err)
 	}
 	
+	// helm search doesn't report keywords or icons, so read them from the
+	// index helm cached for the repository in one pass.
+	metadata := rm.cachedIndexCharts(repoName)
+	
 	// Convert to our chart format
 	var charts []*models.Chart
 	for _, hc := range helmCharts {
@@ -793,7 +1170,14 @@ func (rm *RepositoryManager) searchHelmCharts(repoName string) ([]*models.Chart,
 			AppVersion:  hc.AppVersion,
 			Description: hc.Description,
 			Repository:  repoName,
-			Keywords:    []string{}, // helm search doesn't provide keywords
+			Keywords:    []string{},
+		}
+		if meta, ok := metadata[chartName]; ok {
+			chart.Keywords = meta.Keywords
+			chart.Icon = meta.Icon
+			chart.Home = meta.Home
+			chart.Sources = meta.Sources
+			chart.Maintainers = meta.Maintainers
 		}
 		charts = append(charts, chart)
 	}
@@ -802,12 +1186,100 @@ func (rm *RepositoryManager) searchHelmCharts(repoName string) ([]*models.Chart,
 }
 
 func (rm *RepositoryManager) PullChart(repository, chartName, version string) (string, error) {
+	chartURL, err := rm.ResolveChartURL(repository, chartName, version)
+	if err != nil {
+		return "", err
+	}
+	
+	// The repository may have been removed since the URL was resolved
+	rm.mutex.RLock()
+	repo, exists := rm.repositories[repository]
+	rm.mutex.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrRepositoryNotFound, repository)
+	}
+	
+	// Handle OCI repositories
+	if repo.Type == "oci" {
+		if err := rm.pullOCIChart(chartURL, repo); err != nil {
+			return "", err
+		}
+	}
+	
+	return chartURL, nil
+}
+
+// pullOCIChart pulls an OCI chart anonymously first, since most public
+// registries need no credentials, and only logs in when the registry answers
+// with 401/403. ErrAuthRequired is returned only when the anonymous pull was
+// rejected and logging in did not help; any other failure is logged and
+// ignored because the chart is fetched again during processing.
+func (rm *RepositoryManager) pullOCIChart(chartURL string, repo *models.Repository) error {
+	reference, err := ociPullArgs(chartURL)
+	if err != nil {
+		return err
+	}
+	tempDir := filepath.Join(rm.helmHome, "temp-charts")
+	os.MkdirAll(tempDir, 0755)
+	args := append(append([]string{"pull"}, reference...), "--destination", tempDir, "--untar")
+
+	output, err := rm.runHelm(args...)
+	if err == nil {
+		fmt.Printf("Successfully pulled OCI chart %s\n", chartURL)
+		return nil
+	}
+	if errors.Is(err, ErrHelmUnavailable) {
+		return nil
+	}
+	if !isAuthFailure(output) {
+		fmt.Printf("Warning: Failed to pull OCI chart %s: %s\n", chartURL, parseHelmError(output))
+		return nil
+	}
+
+	if repo.Auth == nil {
+		return fmt.Errorf("%w for %s", ErrAuthRequired, chartURL)
+	}
+	// A rejected pull means neither our cached login nor any session in
+	// helm's registry config is valid, so log in unconditionally
+	rm.forgetLogin(repo.URL)
+	if err := rm.registryLogin(repo.URL, repo.Auth); err != nil {
+		return fmt.Errorf("%w for %s: %v", ErrAuthRequired, chartURL, err)
+	}
+
+	output, err = rm.runHelm(args...)
+	if err != nil {
+		if isAuthFailure(output) {
+			return fmt.Errorf("%w for %s: credentials were rejected", ErrAuthRequired, chartURL)
+		}
+		fmt.Printf("Warning: Failed to pull OCI chart %s: %s\n", chartURL, parseHelmError(output))
+		return nil
+	}
+
+	fmt.Printf("Successfully pulled OCI chart %s after login\n", chartURL)
+	return nil
+}
+
+// isAuthFailure reports whether helm output indicates the registry refused
+// the request (HTTP 401 or 403).
+func isAuthFailure(output []byte) bool {
+	text := strings.ToLower(string(output))
+	for _, marker := range []string{"401", "403", "unauthorized", "forbidden", "denied"} {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveChartURL builds the URL PullChart would fetch for a chart without
+// contacting the repository, so callers can preview or validate a selection.
+func (rm *RepositoryManager) ResolveChartURL(repository, chartName, version string) (string, error) {
 	rm.mutex.RLock()
 	repo, exists := rm.repositories[repository]
 	rm.mutex.RUnlock()
 	
 	if !exists {
-		return "", fmt.Errorf("repository %s not found", repository)
+		return "", fmt.Errorf("%w: %s", ErrRepositoryNotFound, repository)
 	}
 	
 	// Handle OCI repositories
@@ -819,32 +1291,7 @@ func (rm *RepositoryManager) PullChart(repository, chartName, version string) (s
 		// For OCI registries, construct the full OCI URL
 		// Example: oci://dp.apps.rancher.io/charts/ollama:1.16.0
 		baseURL := strings.TrimPrefix(repo.URL, "oci://")
-		chartURL := fmt.Sprintf("oci://%s/%s:%s", baseURL, chartName, version)
-		
-		// Ensure authentication is performed if needed
-		if repo.Auth != nil {
-			if err := rm.performHelmLogin(repo.URL, repo.Auth); err != nil {
-				fmt.Printf("Warning: OCI authentication failed during chart pull: %v\n", err)
-				// Continue anyway - authentication might be cached or chart might be public
-			}
-		}
-		
-		// Try to pull the chart to validate it exists and extract values
-		if rm.isHelmAvailable() {
-			tempDir := filepath.Join(rm.helmHome, "temp-charts")
-			os.MkdirAll(tempDir, 0755)
-			
-			args := []string{"pull", chartURL, "--destination", tempDir, "--untar"}
-			output, err := rm.runHelmCommand(args...)
-			if err != nil {
-				fmt.Printf("Warning: Failed to pull OCI chart %s: %v\nOutput: %s\n", chartURL, err, string(output))
-				// Return the URL anyway - might work in the processing step
-			} else {
-				fmt.Printf("Successfully pulled OCI chart %s\n", chartURL)
-			}
-		}
-		
-		return chartURL, nil
+		return fmt.Sprintf("oci://%s/%s:%s", baseURL, chartName, version), nil
 	}
 	
 	// Handle HTTP repositories
@@ -873,7 +1320,7 @@ func (rm *RepositoryManager) GetRepositoryCharts(repositoryName string) ([]*mode
 	defer rm.mutex.RUnlock()
 	
 	if _, exists := rm.repositories[repositoryName]; !exists {
-		return nil, fmt.Errorf("repository %s not found", repositoryName)
+		return nil, fmt.Errorf("%w: %s", ErrRepositoryNotFound, repositoryName)
 	}
 	
 	// Return all charts for the specific repository
@@ -898,11 +1345,11 @@ func (rm *RepositoryManager) extractBaseURL(repoURL string) string {
 	if strings.HasPrefix(repoURL, "oci://") {
 		// For OCI URLs like oci://dp.apps.rancher.io/charts/ollama
 		// Extract dp.apps.rancher.io
-		cleanURL := strings.TrimPrefix(repoURL, "oci://")
-		parts := strings.Split(cleanURL, "/")
-		if len(parts) > 0 {
-			return parts[0]
+		if registry, _, _, _, err := parseOCIReference(repoURL); err == nil {
+			return registry
 		}
+		cleanURL := strings.TrimPrefix(repoURL, "oci://")
+		return strings.Split(cleanURL, "/")[0]
 	} else {
 		// For HTTP URLs, extract the host
 		if parsedURL, err := url.Parse(repoURL); err == nil {
@@ -912,8 +1359,26 @@ func (rm *RepositoryManager) extractBaseURL(repoURL string) string {
 	return repoURL
 }
 
-// Perform helm registry login for OCI repositories
+// performHelmLogin logs in to an OCI registry unless helm already has a
+// session for it: one we made within loginTTL with the same credentials, or
+// credentials someone else put in helm's registry config. Config entries for
+// a host we logged in to ourselves may hold other credentials, so they never
+// skip the login.
 func (rm *RepositoryManager) performHelmLogin(registryURL string, auth *models.Authentication) error {
+	loginURL := strings.TrimPrefix(registryURL, "oci://")
+	if auth != nil && rm.loggedIn(loginURL, loginFingerprint(auth)) {
+		return nil
+	}
+	if !rm.loggedInToHost(loginURL) && rm.hasRegistryConfigCredentials(loginURL) {
+		fmt.Printf("Using existing helm registry credentials for %s\n", loginURL)
+		return nil
+	}
+	return rm.registryLogin(registryURL, auth)
+}
+
+// registryLogin runs helm registry login for an OCI registry and records the
+// session in the login cache.
+func (rm *RepositoryManager) registryLogin(registryURL string, auth *models.Authentication) error {
 	if auth == nil {
 		return fmt.Errorf("authentication required for OCI registry")
 	}
@@ -938,16 +1403,59 @@ func (rm *RepositoryManager) performHelmLogin(registryURL string, auth *models.A
 	
 	args := []string{"registry", "login", loginURL, "--username", auth.Username, "--password", auth.Password}
 	
-	output, err := rm.runHelmCommand(args...)
+	_, err := rm.runHelmCommand(args...)
 	if err != nil {
-		fmt.Printf("Helm login failed: %v\nOutput: %s\n", err, string(output))
+		fmt.Printf("Helm login failed: %v\n", err)
 		return fmt.Errorf("helm registry login failed: %w", err)
 	}
 	
 	fmt.Printf("Helm registry login successful for %s\n", loginURL)
+	rm.loginMutex.Lock()
+	rm.logins[loginURL] = loginRecord{at: time.Now(), fingerprint: loginFingerprint(auth)}
+	rm.loginMutex.Unlock()
 	return nil
 }
 
+// loggedIn reports whether loginURL was logged in to with the same
+// credentials within loginTTL.
+func (rm *RepositoryManager) loggedIn(loginURL, fingerprint string) bool {
+	rm.loginMutex.Lock()
+	defer rm.loginMutex.Unlock()
+
+	record, ok := rm.logins[loginURL]
+	return ok && record.fingerprint == fingerprint && time.Since(record.at) < rm.loginTTL
+}
+
+// loggedInToHost reports whether we hold a login record for the registry
+// host of loginURL, whatever its credentials or age.
+func (rm *RepositoryManager) loggedInToHost(loginURL string) bool {
+	host := strings.Split(loginURL, "/")[0]
+	rm.loginMutex.Lock()
+	defer rm.loginMutex.Unlock()
+
+	for recorded := range rm.logins {
+		if strings.Split(recorded, "/")[0] == host {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetLogin drops the cached login for registryURL so the next operation
+// logs in again.
+func (rm *RepositoryManager) forgetLogin(registryURL string) {
+	rm.loginMutex.Lock()
+	defer rm.loginMutex.Unlock()
+	delete(rm.logins, strings.TrimPrefix(registryURL, "oci://"))
+}
+
+// loginFingerprint identifies a set of credentials without keeping the
+// password itself in the login cache.
+func loginFingerprint(auth *models.Authentication) string {
+	sum := sha256.Sum256([]byte(auth.Username + "\x00" + auth.Password))
+	return hex.EncodeToString(sum[:])
+}
+
 // Check if credentials are available for a base URL
 func (rm *RepositoryManager) hasCredentialsForBaseURL(baseURL string) bool {
 	rm.mutex.RLock()
@@ -969,16 +1477,31 @@ func (rm *RepositoryManager) getAuthForURL(repoURL string) *models.Authenticatio
 	return nil
 }
 
+// AuthForURL returns the cached credentials for the host of repoURL, or nil.
+func (rm *RepositoryManager) AuthForURL(repoURL string) *models.Authentication {
+	return rm.getAuthForURL(repoURL)
+}
+
 // Helper function to check if helm is available
 func (rm *RepositoryManager) isHelmAvailable() bool {
 	_, err := exec.LookPath("helm")
 	return err == nil
 }
 
-// Helper function to run helm commands
+// runHelmCommand runs helm and, on failure, wraps the error with a
+// user-safe summary of its output. The output itself is still returned so
+// callers can inspect it.
 func (rm *RepositoryManager) runHelmCommand(args ...string) ([]byte, error) {
+	output, err := rm.runHelm(args...)
+	if err != nil && !errors.Is(err, ErrHelmUnavailable) {
+		err = fmt.Errorf("%s: %w", parseHelmError(output), err)
+	}
+	return output, err
+}
+
+func (rm *RepositoryManager) execHelm(args ...string) ([]byte, error) {
 	if !rm.isHelmAvailable() {
-		return nil, fmt.Errorf("helm command not found - please install Helm CLI")
+		return nil, ErrHelmUnavailable
 	}
 	
 	fmt.Printf("Running helm command: helm %s\n", strings.Join(args, " "))
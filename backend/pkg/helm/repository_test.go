@@ -1,9 +1,16 @@
 package helm
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
-	"time"
 
 	"rancher-questions-generator/internal/models"
 )
@@ -46,6 +53,150 @@ func TestNewRepositoryManager(t *testing.T) {
 	}
 }
 
+func TestDefaultRepositoriesFromEnv(t *testing.T) {
+	t.Setenv("DEFAULT_REPOSITORIES", `[
+		{"name": "internal", "url": "https://charts.internal.example.com", "description": "Internal charts"},
+		{"name": "internal-oci", "url": "oci://registry.internal.example.com/charts", "type": "oci"}
+	]`)
+
+	rm := NewRepositoryManager()
+	repos := rm.ListRepositories()
+	if len(repos) != 2 {
+		t.Fatalf("Expected exactly 2 repositories, got %d", len(repos))
+	}
+
+	byName := make(map[string]*models.Repository)
+	for _, repo := range repos {
+		byName[repo.Name] = repo
+	}
+	if repo, ok := byName["internal"]; !ok || repo.Type != "http" || repo.Description != "Internal charts" {
+		t.Errorf("Unexpected internal repository: %+v", repo)
+	}
+	if repo, ok := byName["internal-oci"]; !ok || repo.Type != "oci" {
+		t.Errorf("Unexpected internal-oci repository: %+v", repo)
+	}
+}
+
+func TestDefaultRepositoriesFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repos.yaml")
+	os.WriteFile(path, []byte("- name: team\n  url: https://charts.team.example.com\n"), 0644)
+	t.Setenv("DEFAULT_REPOSITORIES_FILE", path)
+
+	rm := NewRepositoryManager()
+	repos := rm.ListRepositories()
+	if len(repos) != 1 || repos[0].Name != "team" {
+		t.Errorf("Expected only the team repository, got %v", repos)
+	}
+}
+
+func TestDefaultRepositoriesDisabled(t *testing.T) {
+	t.Setenv("DEFAULT_REPOSITORIES", "")
+
+	rm := NewRepositoryManager()
+	if repos := rm.ListRepositories(); len(repos) != 0 {
+		t.Errorf("Expected no default repositories, got %d", len(repos))
+	}
+}
+
+func TestDefaultRepositoriesInvalidConfig(t *testing.T) {
+	t.Setenv("DEFAULT_REPOSITORIES", "not: [valid")
+
+	rm := NewRepositoryManager()
+	if repos := rm.ListRepositories(); len(repos) != len(builtinDefaultRepositories) {
+		t.Errorf("Expected built-in defaults on invalid config, got %d repositories", len(repos))
+	}
+}
+
+func TestDefaultRepositoriesInitStatus(t *testing.T) {
+	t.Setenv("DEFAULT_REPOSITORIES", `[
+		{"name": "good", "url": "https://charts.example.com"},
+		{"name": "broken", "url": "ftp://charts.example.com"},
+		{"name": "good-oci", "url": "oci://registry.example.com/charts"}
+	]`)
+
+	rm := NewRepositoryManager()
+	statuses := rm.InitStatus()
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 statuses, got %d", len(statuses))
+	}
+
+	for _, status := range statuses {
+		wantReady := status.Name != "broken"
+		if status.Ready != wantReady {
+			t.Errorf("Expected %s ready=%v, got %+v", status.Name, wantReady, status)
+		}
+		if !wantReady && !strings.Contains(status.Error, "ftp") {
+			t.Errorf("Expected failure reason for %s, got %q", status.Name, status.Error)
+		}
+	}
+
+	if repos := rm.ListRepositories(); len(repos) != 2 {
+		t.Errorf("Expected the 2 valid repositories to be registered, got %d", len(repos))
+	}
+}
+
+func TestDefaultRepositoriesLazyLogin(t *testing.T) {
+	// A fake helm on PATH records every invocation
+	binDir := t.TempDir()
+	logPath := filepath.Join(binDir, "calls.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + logPath + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "helm"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake helm: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+	t.Setenv("DEFAULT_REPOSITORIES", `[
+		{"name": "private-oci", "url": "oci://registry.example.com/charts", "username": "user", "password": "pass"}
+	]`)
+
+	tests := []struct {
+		lazy      string
+		wantLogin bool
+	}{
+		{"true", false},
+		{"false", true},
+	}
+
+	for _, tt := range tests {
+		t.Run("LAZY_REPO_INIT="+tt.lazy, func(t *testing.T) {
+			os.Remove(logPath)
+			t.Setenv("LAZY_REPO_INIT", tt.lazy)
+
+			rm := NewRepositoryManager()
+
+			calls, _ := os.ReadFile(logPath)
+			if loggedIn := strings.Contains(string(calls), "registry login"); loggedIn != tt.wantLogin {
+				t.Errorf("Expected login at construction = %v, helm calls: %q", tt.wantLogin, calls)
+			}
+			repos := rm.ListRepositories()
+			if len(repos) != 1 || repos[0].Auth == nil || repos[0].Auth.Username != "user" {
+				t.Errorf("Expected the repository to be registered with its credentials, got %+v", repos)
+			}
+		})
+	}
+}
+
+func TestListRepositoriesDoesNotPrint(t *testing.T) {
+	rm := NewRepositoryManager()
+
+	stdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	repos := rm.ListRepositories()
+	w.Close()
+	os.Stdout = stdout
+
+	output, _ := io.ReadAll(r)
+	if len(output) != 0 {
+		t.Errorf("Expected no stdout output, got %q", output)
+	}
+
+	for i := 1; i < len(repos); i++ {
+		if repos[i-1].Name > repos[i].Name {
+			t.Errorf("Expected repositories sorted by name, got %s before %s", repos[i-1].Name, repos[i].Name)
+		}
+	}
+}
+
 func TestAddRepository(t *testing.T) {
 	rm := NewRepositoryManager()
 	
@@ -105,6 +256,75 @@ func TestAddRepository(t *testing.T) {
 	}
 }
 
+func TestValidateRepositoryName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"bitnami", false},
+		{"ingress-nginx", false},
+		{"repo2", false},
+		{"a", false},
+		{strings.Repeat("a", 63), false},
+		{"", true},
+		{strings.Repeat("a", 64), true},
+		{"My-Repo", true},
+		{"charts/stable", true},
+		{"my repo", true},
+		{"-leading", true},
+		{"trailing-", true},
+		{"'; DROP TABLE repositories; --", true},
+	}
+
+	for _, tt := range tests {
+		err := validateRepositoryName(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateRepositoryName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidRepositoryName) {
+			t.Errorf("Expected ErrInvalidRepositoryName for %q, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestAddRepositorySanitizesDescription(t *testing.T) {
+	rm := NewRepositoryManager()
+	rm.repositories = make(map[string]*models.Repository)
+
+	if err := rm.AddRepositoryWithAuth("charts", "https://charts.example.com", "  Team charts\x1b[31m\n", "", nil); err != nil {
+		t.Fatalf("AddRepositoryWithAuth() error = %v", err)
+	}
+	if got := rm.repositories["charts"].Description; got != "Team charts[31m" {
+		t.Errorf("Unexpected description %q", got)
+	}
+	if err := rm.AddRepositoryWithAuth("Bad Name", "https://charts.example.com", "", "", nil); !errors.Is(err, ErrInvalidRepositoryName) {
+		t.Errorf("Expected ErrInvalidRepositoryName, got %v", err)
+	}
+}
+
+func TestAddRepositoryDuplicateName(t *testing.T) {
+	rm := NewRepositoryManager()
+	rm.repositories = make(map[string]*models.Repository)
+
+	if err := rm.AddRepositoryWithAuth("charts", "https://charts.example.com", "", "", nil); err != nil {
+		t.Fatalf("AddRepositoryWithAuth() error = %v", err)
+	}
+	err := rm.AddRepositoryWithAuth("charts", "https://other.example.com", "", "", nil)
+	if !errors.Is(err, ErrRepositoryExists) {
+		t.Fatalf("Expected ErrRepositoryExists, got %v", err)
+	}
+	if url := rm.repositories["charts"].URL; url != "https://charts.example.com" {
+		t.Errorf("Rejected add replaced the repository URL with %s", url)
+	}
+
+	if err := rm.ReplaceRepositoryWithAuth("charts", "https://other.example.com", "", "", nil); err != nil {
+		t.Fatalf("ReplaceRepositoryWithAuth() error = %v", err)
+	}
+	if url := rm.repositories["charts"].URL; url != "https://other.example.com" {
+		t.Errorf("Expected the repository to be replaced, got %s", url)
+	}
+}
+
 func TestAddRepositoryWithAuth(t *testing.T) {
 	rm := NewRepositoryManager()
 	rm.repositories = make(map[string]*models.Repository) // Clear defaults
@@ -326,6 +546,165 @@ func TestFilterCharts(t *testing.T) {
 	}
 }
 
+func TestFilterChartsRanksByRelevance(t *testing.T) {
+	rm := NewRepositoryManager()
+
+	charts := []*models.Chart{
+		{Name: "pgadmin", Repository: "test", Description: "Admin UI for postgres databases"},
+		{Name: "postgres-exporter", Repository: "test", Description: "Prometheus exporter"},
+		{Name: "cloudnative-pg", Repository: "test", Keywords: []string{"postgres"}},
+		{Name: "my-postgres", Repository: "test"},
+		{Name: "postgres", Repository: "test", Description: "PostgreSQL database"},
+	}
+
+	results := rm.filterCharts(charts, "Postgres")
+
+	var names []string
+	for _, chart := range results {
+		names = append(names, chart.Name)
+	}
+	expected := []string{"postgres", "postgres-exporter", "my-postgres", "cloudnative-pg", "pgadmin"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected order %v, got %v", expected, names)
+	}
+}
+
+func TestDedupeCharts(t *testing.T) {
+	charts := []*models.Chart{
+		{Name: "nginx", Version: "15.4.10", Repository: "bitnami"},
+		{Name: "redis", Version: "18.0.0", Repository: "bitnami"},
+		{Name: "nginx", Version: "1.25.3", Repository: "suse-application-collection"},
+		{Name: "nginx", Version: "15.4.10", Repository: "bitnami"},
+	}
+
+	deduped := DedupeCharts(charts)
+
+	if len(deduped) != 2 {
+		t.Fatalf("Expected 2 charts, got %d", len(deduped))
+	}
+	nginx, redis := deduped[0], deduped[1]
+	if nginx.Name != "nginx" || nginx.Repository != "bitnami" || nginx.Version != "15.4.10" {
+		t.Errorf("Expected the first nginx entry to be kept, got %+v", nginx)
+	}
+	if !reflect.DeepEqual(nginx.Repositories, []string{"bitnami", "suse-application-collection"}) {
+		t.Errorf("Unexpected nginx repositories: %v", nginx.Repositories)
+	}
+	if !reflect.DeepEqual(redis.Repositories, []string{"bitnami"}) {
+		t.Errorf("Unexpected redis repositories: %v", redis.Repositories)
+	}
+
+	// Without dedupe the full results are untouched
+	if len(charts) != 4 || charts[0].Repositories != nil {
+		t.Errorf("Expected the input charts to be unchanged, got %+v", charts[0])
+	}
+}
+
+func TestFilterChartsOrdersTiesByName(t *testing.T) {
+	rm := NewRepositoryManager()
+
+	charts := []*models.Chart{
+		{Name: "zookeeper", Repository: "test"},
+		{Name: "airflow", Repository: "test"},
+		{Name: "kafka", Repository: "test"},
+	}
+
+	results := rm.filterCharts(charts, "")
+	if results[0].Name != "airflow" || results[1].Name != "kafka" || results[2].Name != "zookeeper" {
+		t.Errorf("Expected charts ordered by name, got %s, %s, %s", results[0].Name, results[1].Name, results[2].Name)
+	}
+}
+
+func TestFilterChartsIgnoresAccentsAndCase(t *testing.T) {
+	rm := NewRepositoryManager()
+
+	charts := []*models.Chart{
+		{Name: "café-menu", Repository: "Partner", Description: "Menus for a café"},
+		{Name: "resume-builder", Repository: "partner", Keywords: []string{"Résumé"}},
+		{Name: "nginx", Repository: "bitnami"},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		repository string
+		expected   []string
+	}{
+		{"plain query matches accented name", "cafe", "", []string{"café-menu"}},
+		{"accented query matches plain text", "RÉSUMÉ", "", []string{"resume-builder"}},
+		{"accented keyword", "resume", "", []string{"resume-builder"}},
+		{"mixed-case repository filter", "", "PARTNER", []string{"café-menu", "resume-builder"}},
+		{"repository filter with query", "NGINX", "Bitnami", []string{"nginx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, chart := range rm.filterCharts(charts, tt.query, tt.repository) {
+				names = append(names, chart.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestSearchChartsRepositoryCaseInsensitive(t *testing.T) {
+	rm := NewRepositoryManager()
+
+	charts, err := rm.SearchCharts("", "BITNAMI")
+	if err != nil {
+		t.Fatalf("SearchCharts() error = %v", err)
+	}
+	if len(charts) == 0 {
+		t.Fatal("Expected charts for a mixed-case repository name")
+	}
+	for _, chart := range charts {
+		if chart.Repository != "bitnami" {
+			t.Errorf("Expected only bitnami charts, got %s", chart.Repository)
+		}
+	}
+}
+
+func TestSearchChartsExampleFallback(t *testing.T) {
+	broken := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(broken.Close)
+
+	tests := []struct {
+		name     string
+		disable  string
+		examples bool
+	}{
+		{"examples by default", "", true},
+		{"examples disabled", "true", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DISABLE_EXAMPLE_CHARTS", tt.disable)
+			rm := NewRepositoryManager()
+			rm.repositories = map[string]*models.Repository{
+				"bitnami": {Name: "bitnami", URL: broken.URL, Type: "http"},
+			}
+			rm.runHelm = func(args ...string) ([]byte, error) { return nil, ErrHelmUnavailable }
+
+			// Fetching the repository fails, and so does searching everything
+			for _, repository := range []string{"bitnami", ""} {
+				charts, err := rm.SearchCharts("nginx", repository)
+				if err != nil {
+					t.Fatalf("SearchCharts(%q) failed: %v", repository, err)
+				}
+				if got := len(charts) > 0; got != tt.examples {
+					t.Errorf("SearchCharts(%q) returned %d charts, expected examples %v", repository, len(charts), tt.examples)
+				}
+				if charts == nil {
+					t.Errorf("SearchCharts(%q) returned nil instead of an empty list", repository)
+				}
+			}
+		})
+	}
+}
+
 func TestPullChart(t *testing.T) {
 	rm := NewRepositoryManager()
 	rm.repositories = make(map[string]*models.Repository) // Clear defaults
@@ -384,6 +763,149 @@ func TestPullChart(t *testing.T) {
 	}
 }
 
+// fakeRegistry simulates helm against a registry that may require login.
+type fakeRegistry struct {
+	requiresAuth bool
+	loggedIn     bool
+	commands     []string
+}
+
+func (f *fakeRegistry) run(args ...string) ([]byte, error) {
+	f.commands = append(f.commands, args[0]+" "+args[1])
+	switch {
+	case args[0] == "registry" && args[1] == "login":
+		f.loggedIn = true
+		return []byte("Login Succeeded"), nil
+	case args[0] == "pull" && f.requiresAuth && !f.loggedIn:
+		return []byte("Error: failed to authorize: 401 Unauthorized"), fmt.Errorf("exit status 1")
+	}
+	return nil, nil
+}
+
+func TestPullChartOCIAnonymousFirst(t *testing.T) {
+	tests := []struct {
+		name         string
+		requiresAuth bool
+		auth         *models.Authentication
+		wantCommands []string
+		wantAuthErr  bool
+	}{
+		{
+			name:         "public chart pulls without login",
+			auth:         &models.Authentication{Username: "user", Password: "pass"},
+			wantCommands: []string{"pull oci://registry.example.com/charts/app"},
+		},
+		{
+			name:         "private chart logs in after 401",
+			requiresAuth: true,
+			auth:         &models.Authentication{Username: "user", Password: "pass"},
+			wantCommands: []string{
+				"pull oci://registry.example.com/charts/app",
+				"registry login",
+				"pull oci://registry.example.com/charts/app",
+			},
+		},
+		{
+			name:         "private chart without credentials",
+			requiresAuth: true,
+			wantCommands: []string{"pull oci://registry.example.com/charts/app"},
+			wantAuthErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &fakeRegistry{requiresAuth: tt.requiresAuth}
+			rm := NewRepositoryManager()
+			rm.repositories = make(map[string]*models.Repository)
+			rm.authCache = make(map[string]*models.Authentication)
+			rm.repositories["oci-repo"] = &models.Repository{
+				Name: "oci-repo",
+				URL:  "oci://registry.example.com/charts",
+				Type: "oci",
+				Auth: tt.auth,
+			}
+			rm.runHelm = registry.run
+
+			chartURL, err := rm.PullChart("oci-repo", "app", "1.0.0")
+			if tt.wantAuthErr {
+				if !errors.Is(err, ErrAuthRequired) {
+					t.Fatalf("Expected ErrAuthRequired, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("PullChart() error = %v", err)
+			} else if chartURL != "oci://registry.example.com/charts/app:1.0.0" {
+				t.Errorf("Unexpected chart URL %s", chartURL)
+			}
+
+			if len(registry.commands) != len(tt.wantCommands) {
+				t.Fatalf("Expected commands %v, got %v", tt.wantCommands, registry.commands)
+			}
+			for i, command := range tt.wantCommands {
+				if registry.commands[i] != command {
+					t.Errorf("Command %d = %q, expected %q", i, registry.commands[i], command)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryLoginCaching(t *testing.T) {
+	registry := &fakeRegistry{requiresAuth: true}
+	rm := NewRepositoryManager()
+	rm.repositories = make(map[string]*models.Repository)
+	rm.authCache = make(map[string]*models.Authentication)
+	rm.runHelm = registry.run
+
+	countLogins := func() int {
+		logins := 0
+		for _, command := range registry.commands {
+			if command == "registry login" {
+				logins++
+			}
+		}
+		return logins
+	}
+	add := func(name, password string) {
+		t.Helper()
+		auth := &models.Authentication{Username: "user", Password: password}
+		if err := rm.AddRepositoryWithAuth(name, "oci://registry.example.com/charts", "", "oci", auth); err != nil {
+			t.Fatalf("AddRepositoryWithAuth() error = %v", err)
+		}
+	}
+
+	add("first", "pass")
+	add("second", "pass")
+	if _, err := rm.PullChart("second", "app", "1.0.0"); err != nil {
+		t.Fatalf("PullChart() error = %v", err)
+	}
+	if got := countLogins(); got != 1 {
+		t.Fatalf("Expected one login within the window, got %d: %v", got, registry.commands)
+	}
+
+	// New credentials for the same registry log in again
+	add("third", "rotated")
+	if got := countLogins(); got != 2 {
+		t.Fatalf("Expected a new login after the credentials changed, got %d", got)
+	}
+
+	// A 401 drops the cached login even within the window
+	registry.loggedIn = false
+	if _, err := rm.PullChart("third", "app", "1.0.0"); err != nil {
+		t.Fatalf("PullChart() after session expiry error = %v", err)
+	}
+	if got := countLogins(); got != 3 {
+		t.Fatalf("Expected a login after the registry rejected the pull, got %d", got)
+	}
+
+	// Logins older than the window are repeated
+	rm.loginTTL = 0
+	add("fourth", "rotated")
+	if got := countLogins(); got != 4 {
+		t.Errorf("Expected a login once the window expired, got %d", got)
+	}
+}
+
 func TestExtractBaseURL(t *testing.T) {
 	rm := NewRepositoryManager()
 	
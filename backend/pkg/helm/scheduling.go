@@ -0,0 +1,56 @@
+package helm
+
+import (
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schedulingGroup collects pod scheduling questions.
+const schedulingGroup = "Advanced Scheduling"
+
+// schedulingKeys are the lower-cased names of pod scheduling settings. Their
+// values are Kubernetes structures too deep to ask about field by field.
+var schedulingKeys = map[string]bool{"nodeselector": true, "tolerations": true, "affinity": true}
+
+// isSchedulingValue reports whether key holds pod scheduling settings: a
+// scheduling key whose value is a map, a list or unset.
+func isSchedulingValue(key string, value interface{}) bool {
+	if !schedulingKeys[strings.ToLower(key)] {
+		return false
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return true
+	}
+	return false
+}
+
+// schedulingQuestion offers a scheduling setting as a multiline YAML block
+// in schedulingGroup, shown only while the advanced toggle is on.
+func schedulingQuestion(path []string, value interface{}) models.Question {
+	question := pathQuestion(path)
+	question.Type = "multiline"
+	question.Group = schedulingGroup
+	question.ShowIf = advancedToggle + "=true"
+	question.Description = "Pod scheduling constraints, edited as YAML"
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return question
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return question
+		}
+	default:
+		return question
+	}
+	if out, err := yaml.Marshal(value); err == nil {
+		question.Default = strings.TrimSuffix(string(out), "\n")
+	}
+	return question
+}
@@ -0,0 +1,92 @@
+package helm
+
+import "testing"
+
+func TestSchedulingQuestions(t *testing.T) {
+	processor := NewProcessor()
+	values := map[string]interface{}{
+		"replicaCount": 1,
+		"nodeSelector": map[string]interface{}{},
+		"tolerations": []interface{}{
+			map[string]interface{}{"key": "gpu", "operator": "Exists", "effect": "NoSchedule"},
+		},
+		"affinity": map[string]interface{}{
+			"nodeAffinity": map[string]interface{}{
+				"requiredDuringSchedulingIgnoredDuringExecution": map[string]interface{}{},
+			},
+		},
+		"worker": map[string]interface{}{
+			"enabled":      true,
+			"nodeSelector": map[string]interface{}{"disktype": "ssd"},
+		},
+	}
+
+	questions := processor.generateDefaultQuestions(values, ProfileStandard).Questions
+
+	tests := []struct {
+		variable string
+		showIf   string
+		dflt     interface{}
+	}{
+		{"nodeSelector", "advancedConfig=true", nil},
+		{"tolerations", "advancedConfig=true", "- effect: NoSchedule\n  key: gpu\n  operator: Exists"},
+		{"affinity", "advancedConfig=true", "nodeAffinity:\n    requiredDuringSchedulingIgnoredDuringExecution: {}"},
+		{"worker.nodeSelector", "worker.enabled=true&&advancedConfig=true", "disktype: ssd"},
+	}
+	for _, tt := range tests {
+		q := findQuestion(questions, tt.variable)
+		if q == nil {
+			t.Errorf("Expected question for %s", tt.variable)
+			continue
+		}
+		if q.Type != "multiline" || q.Group != schedulingGroup || q.ShowIf != tt.showIf {
+			t.Errorf("Expected %s to be a gated multiline question in %s, got %+v", tt.variable, schedulingGroup, *q)
+		}
+		if q.Default != tt.dflt {
+			t.Errorf("Expected %s default %q, got %q", tt.variable, tt.dflt, q.Default)
+		}
+	}
+
+	// Nested scheduling fields aren't asked about one by one
+	if q := findQuestion(questions, "affinity.nodeAffinity.requiredDuringSchedulingIgnoredDuringExecution"); q != nil {
+		t.Errorf("Expected no question for nested affinity fields, got %+v", *q)
+	}
+
+	toggles := 0
+	for _, q := range questions {
+		if q.Variable == "advancedConfig" {
+			toggles++
+			if q.Type != "boolean" || q.Default != false || q.Group != "General" {
+				t.Errorf("Unexpected advanced toggle: %+v", q)
+			}
+		}
+	}
+	if toggles != 1 || questions[0].Variable != "advancedConfig" {
+		t.Errorf("Expected one advancedConfig toggle first, got %d (first is %s)", toggles, questions[0].Variable)
+	}
+}
+
+func TestSchedulingToggleOnlyWhenNeeded(t *testing.T) {
+	processor := NewProcessor()
+
+	questions := processor.generateDefaultQuestions(map[string]interface{}{"replicaCount": 1}, ProfileStandard).Questions
+	if findQuestion(questions, "advancedConfig") != nil {
+		t.Error("Expected no advancedConfig toggle without scheduling values")
+	}
+
+	// A chart defining its own toggle keeps it
+	values := map[string]interface{}{"advancedConfig": true, "tolerations": []interface{}{}}
+	questions = processor.generateDefaultQuestions(values, ProfileStandard).Questions
+	toggles := 0
+	for _, q := range questions {
+		if q.Variable == "advancedConfig" {
+			toggles++
+			if q.Default != true {
+				t.Errorf("Expected the chart's advancedConfig default to be kept, got %v", q.Default)
+			}
+		}
+	}
+	if toggles != 1 {
+		t.Errorf("Expected one advancedConfig question, got %d", toggles)
+	}
+}
@@ -0,0 +1,37 @@
+package helm
+
+import (
+	"net/http"
+	"os"
+
+	"rancher-questions-generator/internal/version"
+)
+
+// UserAgent returns the User-Agent sent with outbound HTTP requests: the
+// USER_AGENT environment variable, or rancher-questions-generator/<version>.
+// Requests made by the helm CLI carry helm's own user agent, which it does
+// not let callers change.
+func UserAgent() string {
+	if agent := os.Getenv("USER_AGENT"); agent != "" {
+		return agent
+	}
+	return "rancher-questions-generator/" + version.Version
+}
+
+// userAgentTransport sets UserAgent on requests that don't carry one.
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", UserAgent())
+	}
+	return t.base.RoundTrip(req)
+}
+
+// newTransport returns the transport for outbound HTTP clients.
+func newTransport() http.RoundTripper {
+	return userAgentTransport{base: http.DefaultTransport}
+}
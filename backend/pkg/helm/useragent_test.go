@@ -0,0 +1,61 @@
+package helm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestUserAgent(t *testing.T) {
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		switch r.URL.Path {
+		case "/index.yaml":
+			w.Write([]byte(sampleIndex))
+		default:
+			w.Write([]byte("chart"))
+		}
+	}))
+	defer server.Close()
+
+	fetch := func() {
+		agents = nil
+		rm := NewRepositoryManager()
+		if _, err := rm.fetchIndexCharts(&models.Repository{Name: "test", URL: server.URL}); err != nil {
+			t.Fatalf("fetchIndexCharts() error = %v", err)
+		}
+
+		dest, err := os.CreateTemp(t.TempDir(), "chart-*.tgz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dest.Close()
+		if _, err := NewProcessor().downloadOnce(context.Background(), server.URL+"/chart.tgz", dest); err != nil {
+			t.Fatalf("downloadOnce() error = %v", err)
+		}
+	}
+
+	t.Setenv("USER_AGENT", "")
+	fetch()
+	for _, agent := range agents {
+		if agent != "rancher-questions-generator/dev" {
+			t.Errorf("Expected the default user agent, got %q", agent)
+		}
+	}
+	if len(agents) != 2 {
+		t.Errorf("Expected 2 requests, got %d", len(agents))
+	}
+
+	t.Setenv("USER_AGENT", "acme-catalog/2.0")
+	fetch()
+	for _, agent := range agents {
+		if agent != "acme-catalog/2.0" {
+			t.Errorf("Expected the configured user agent, got %q", agent)
+		}
+	}
+}
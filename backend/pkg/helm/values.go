@@ -0,0 +1,180 @@
+package helm
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FlattenValues converts nested values into a flat map keyed by dotted path,
+// e.g. {"image": {"tag": "1.0"}} becomes {"image.tag": "1.0"}. Arrays are kept
+// as-is under their path, and empty maps are kept so no key is lost.
+func FlattenValues(values map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flattenInto(flat, values, "")
+	return flat
+}
+
+func flattenInto(flat map[string]interface{}, values map[string]interface{}, prefix string) {
+	for key, value := range values {
+		path := prefix + key
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenInto(flat, nested, path+".")
+			continue
+		}
+		flat[path] = value
+	}
+}
+
+// UnflattenValues rebuilds nested values from a map keyed by dotted path. It
+// is the inverse of FlattenValues. Paths are applied in sorted order, so when
+// they conflict (e.g. "a" and "a.b") the deeper path wins.
+func UnflattenValues(flat map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, path := range sortedKeys(flat) {
+		keys := strings.Split(path, ".")
+		current := values
+		for _, key := range keys[:len(keys)-1] {
+			next, ok := current[key].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				current[key] = next
+			}
+			current = next
+		}
+
+		current[keys[len(keys)-1]] = flat[path]
+	}
+	return values
+}
+
+// CoerceFlatValues converts submitted flat values to the types of the values
+// they replace, so "3" stays an integer and "true" stays a boolean when the
+// chart defined them that way. Paths without a reference value are inferred
+// from the string itself. Values whose reference is a string are left alone,
+// and text submitted for a list is split the way arrayQuestion joined it.
+func CoerceFlatValues(flat, reference map[string]interface{}) (map[string]interface{}, error) {
+	coerced := make(map[string]interface{}, len(flat))
+	for path, value := range flat {
+		converted, err := coerceValue(value, reference[path])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", path, err)
+		}
+		coerced[path] = converted
+	}
+	return coerced, nil
+}
+
+func coerceValue(value, reference interface{}) (interface{}, error) {
+	if number, ok := value.(float64); ok {
+		if _, isFloat := reference.(float64); !isFloat && number == math.Trunc(number) {
+			return int(number), nil
+		}
+		return number, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	switch ref := reference.(type) {
+	case string:
+		return text, nil
+	case bool:
+		return strconv.ParseBool(text)
+	case int, int64:
+		return strconv.Atoi(text)
+	case float64:
+		return strconv.ParseFloat(text, 64)
+	case []interface{}:
+		return coerceList(text, ref)
+	case nil:
+		return inferScalar(text), nil
+	default:
+		return text, nil
+	}
+}
+
+// coerceList turns the text form of an array question back into a list: a
+// comma-separated string for scalar lists, YAML for lists of objects.
+func coerceList(text string, reference []interface{}) (interface{}, error) {
+	if !isScalarList(reference) {
+		var items []interface{}
+		if err := yaml.Unmarshal([]byte(text), &items); err != nil {
+			return nil, fmt.Errorf("expected a YAML list: %w", err)
+		}
+		return items, nil
+	}
+
+	items := []interface{}{}
+	for _, part := range strings.Split(text, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, inferScalar(part))
+		}
+	}
+	return items, nil
+}
+
+// inferScalar interprets a string without a reference value, recognising
+// booleans and numbers the same way a YAML parser would.
+func inferScalar(text string) interface{} {
+	switch text {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.Atoi(text); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f
+	}
+	return text
+}
+
+// ValuesDiff describes how one values tree differs from another, keyed by
+// dotted path.
+type ValuesDiff struct {
+	Added   map[string]interface{} `json:"added"`
+	Removed map[string]interface{} `json:"removed"`
+	Changed map[string]ValueChange `json:"changed"`
+}
+
+// ValueChange holds the old and new value of a changed path.
+type ValueChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// DiffValues compares two values trees path by path. Arrays are compared as
+// whole values, as FlattenValues keeps them intact.
+func DiffValues(from, to map[string]interface{}) ValuesDiff {
+	oldFlat, newFlat := FlattenValues(from), FlattenValues(to)
+	diff := ValuesDiff{
+		Added:   make(map[string]interface{}),
+		Removed: make(map[string]interface{}),
+		Changed: make(map[string]ValueChange),
+	}
+
+	for path, oldValue := range oldFlat {
+		newValue, exists := newFlat[path]
+		switch {
+		case !exists:
+			diff.Removed[path] = oldValue
+		case !reflect.DeepEqual(oldValue, newValue):
+			diff.Changed[path] = ValueChange{From: oldValue, To: newValue}
+		}
+	}
+	for path, newValue := range newFlat {
+		if _, exists := oldFlat[path]; !exists {
+			diff.Added[path] = newValue
+		}
+	}
+	return diff
+}
@@ -0,0 +1,174 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlattenValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name: "nested maps",
+			values: map[string]interface{}{
+				"replicaCount": 1,
+				"image": map[string]interface{}{
+					"repository": "nginx",
+					"tag":        "1.25",
+				},
+				"resources": map[string]interface{}{
+					"limits": map[string]interface{}{
+						"cpu": "500m",
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"replicaCount":         1,
+				"image.repository":     "nginx",
+				"image.tag":            "1.25",
+				"resources.limits.cpu": "500m",
+			},
+		},
+		{
+			name: "mixed maps and arrays",
+			values: map[string]interface{}{
+				"ollama": map[string]interface{}{
+					"models": []interface{}{"llama2", "mistral"},
+					"gpu": map[string]interface{}{
+						"enabled": false,
+					},
+				},
+				"ingress": map[string]interface{}{
+					"hosts": []interface{}{
+						map[string]interface{}{"host": "example.com"},
+					},
+					"annotations": map[string]interface{}{},
+				},
+			},
+			expected: map[string]interface{}{
+				"ollama.models":       []interface{}{"llama2", "mistral"},
+				"ollama.gpu.enabled":  false,
+				"ingress.hosts":       []interface{}{map[string]interface{}{"host": "example.com"}},
+				"ingress.annotations": map[string]interface{}{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FlattenValues(tt.values); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("FlattenValues() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnflattenValuesRoundTrip(t *testing.T) {
+	original := map[string]interface{}{
+		"replicaCount": 2,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.25",
+		},
+		"ingress": map[string]interface{}{
+			"enabled":     false,
+			"hosts":       []interface{}{"example.com"},
+			"annotations": map[string]interface{}{},
+		},
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{
+				"cpu": "500m",
+			},
+		},
+	}
+
+	if got := UnflattenValues(FlattenValues(original)); !reflect.DeepEqual(got, original) {
+		t.Errorf("UnflattenValues(FlattenValues()) = %v, expected %v", got, original)
+	}
+}
+
+func TestUnflattenValuesDeeperPathWins(t *testing.T) {
+	got := UnflattenValues(map[string]interface{}{
+		"image":     "nginx",
+		"image.tag": "1.25",
+	})
+	expected := map[string]interface{}{
+		"image": map[string]interface{}{"tag": "1.25"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("UnflattenValues() = %v, expected %v", got, expected)
+	}
+}
+
+func TestCoerceFlatValues(t *testing.T) {
+	reference := map[string]interface{}{
+		"replicaCount":    1,
+		"ingress.enabled": false,
+		"image.tag":       "1.25",
+		"ratio":           0.5,
+	}
+	submitted := map[string]interface{}{
+		"replicaCount":    "3",
+		"ingress.enabled": "true",
+		"image.tag":       "1.26",
+		"ratio":           "0.75",
+		"extra.count":     "10",
+		"extra.flag":      "false",
+		"extra.name":      "demo",
+		"fromJSON":        float64(4),
+	}
+
+	got, err := CoerceFlatValues(submitted, reference)
+	if err != nil {
+		t.Fatalf("CoerceFlatValues() error = %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"replicaCount":    3,
+		"ingress.enabled": true,
+		"image.tag":       "1.26",
+		"ratio":           0.75,
+		"extra.count":     10,
+		"extra.flag":      false,
+		"extra.name":      "demo",
+		"fromJSON":        4,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("CoerceFlatValues() = %v, expected %v", got, expected)
+	}
+
+	if _, err := CoerceFlatValues(map[string]interface{}{"replicaCount": "many"}, reference); err == nil {
+		t.Error("Expected error for non-numeric replicaCount")
+	}
+}
+
+func TestCoerceFlatValuesLists(t *testing.T) {
+	reference := map[string]interface{}{
+		"ollama.models": []interface{}{"llama2"},
+		"ingress.hosts": []interface{}{map[string]interface{}{"host": "a.local"}},
+	}
+	submitted := map[string]interface{}{
+		"ollama.models": "llama2, mistral,,",
+		"ingress.hosts": "- host: b.local\n",
+	}
+
+	got, err := CoerceFlatValues(submitted, reference)
+	if err != nil {
+		t.Fatalf("CoerceFlatValues() error = %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"ollama.models": []interface{}{"llama2", "mistral"},
+		"ingress.hosts": []interface{}{map[string]interface{}{"host": "b.local"}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("CoerceFlatValues() = %#v, want %#v", got, expected)
+	}
+
+	if _, err := CoerceFlatValues(map[string]interface{}{"ingress.hosts": "host: [oops"}, reference); err == nil {
+		t.Error("Expected error for invalid YAML list")
+	}
+}
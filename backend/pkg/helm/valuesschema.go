@@ -0,0 +1,124 @@
+package helm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// ErrInvalidValues reports values that break the chart's values.schema.json.
+var ErrInvalidValues = errors.New("values do not match the chart's values.schema.json")
+
+// SchemaProblem is one place where values break the chart's schema. Path is
+// the dotted values path, empty for the values as a whole.
+type SchemaProblem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValuesSchemaError lists every problem found validating values, ordered by
+// path; it wraps ErrInvalidValues.
+type ValuesSchemaError struct {
+	Problems []SchemaProblem
+}
+
+func (e *ValuesSchemaError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Message
+		if problem.Path != "" {
+			messages[i] = problem.Path + ": " + problem.Message
+		}
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidValues, strings.Join(messages, "; "))
+}
+
+func (e *ValuesSchemaError) Unwrap() error {
+	return ErrInvalidValues
+}
+
+// ValidateValues checks values against schema, the contents of a chart's
+// values.schema.json. Schemas without $schema are read as draft-07, like
+// Helm does. An empty schema accepts any values; a schema that can't be
+// compiled returns an error that is not a *ValuesSchemaError.
+func ValidateValues(schema string, values map[string]interface{}) error {
+	if strings.TrimSpace(schema) == "" {
+		return nil
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+	if err := compiler.AddResource("values.schema.json", strings.NewReader(schema)); err != nil {
+		return fmt.Errorf("invalid values.schema.json: %w", err)
+	}
+	compiled, err := compiler.Compile("values.schema.json")
+	if err != nil {
+		return fmt.Errorf("invalid values.schema.json: %w", err)
+	}
+
+	// The validator expects values as decoded from JSON
+	instance, err := jsonInstance(values)
+	if err != nil {
+		return err
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if err := compiled.Validate(instance); errors.As(err, &validationErr) {
+		problems := schemaProblems(validationErr)
+		sort.SliceStable(problems, func(i, j int) bool {
+			return problems[i].Path < problems[j].Path
+		})
+		return &ValuesSchemaError{Problems: problems}
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func jsonInstance(values map[string]interface{}) (interface{}, error) {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var instance interface{}
+	if err := decoder.Decode(&instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// schemaProblems flattens a validation error into its leaf causes, which
+// name the offending values.
+func schemaProblems(err *jsonschema.ValidationError) []SchemaProblem {
+	if len(err.Causes) == 0 {
+		return []SchemaProblem{{Path: pointerToPath(err.InstanceLocation), Message: err.Message}}
+	}
+	var problems []SchemaProblem
+	for _, cause := range err.Causes {
+		problems = append(problems, schemaProblems(cause)...)
+	}
+	return problems
+}
+
+// pointerToPath converts a JSON pointer such as "/service/port" into a
+// dotted values path.
+func pointerToPath(pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+	}
+	return strings.Join(segments, ".")
+}
@@ -0,0 +1,94 @@
+package helm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const replicaSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "replicaCount": {"type": "integer", "minimum": 1},
+    "service": {
+      "type": "object",
+      "properties": {"port": {"type": "integer"}}
+    }
+  }
+}`
+
+func TestValidateValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		values   map[string]interface{}
+		problems []string
+	}{
+		{
+			name:   "valid values",
+			schema: replicaSchema,
+			values: map[string]interface{}{"replicaCount": 3, "service": map[string]interface{}{"port": 80}},
+		},
+		{
+			name:     "string for integer",
+			schema:   replicaSchema,
+			values:   map[string]interface{}{"replicaCount": "three"},
+			problems: []string{"replicaCount"},
+		},
+		{
+			name:     "nested and multiple problems",
+			schema:   replicaSchema,
+			values:   map[string]interface{}{"replicaCount": 0, "service": map[string]interface{}{"port": "http"}},
+			problems: []string{"replicaCount", "service.port"},
+		},
+		{
+			name:   "no schema",
+			schema: "",
+			values: map[string]interface{}{"replicaCount": "three"},
+		},
+		{
+			name:   "schema without $schema",
+			schema: `{"properties": {"enabled": {"type": "boolean"}}}`,
+			values: map[string]interface{}{"enabled": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateValues(tt.schema, tt.values)
+			if tt.problems == nil {
+				if err != nil {
+					t.Errorf("Expected values to be valid, got %v", err)
+				}
+				return
+			}
+
+			var schemaErr *ValuesSchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("Expected a ValuesSchemaError, got %v", err)
+			}
+			if !errors.Is(err, ErrInvalidValues) {
+				t.Error("Expected the error to wrap ErrInvalidValues")
+			}
+			var paths []string
+			for _, problem := range schemaErr.Problems {
+				paths = append(paths, problem.Path)
+				if problem.Message == "" {
+					t.Errorf("Expected a message for %s", problem.Path)
+				}
+			}
+			if !reflect.DeepEqual(paths, tt.problems) {
+				t.Errorf("Expected problems at %v, got %+v", tt.problems, schemaErr.Problems)
+			}
+		})
+	}
+}
+
+func TestValidateValuesInvalidSchema(t *testing.T) {
+	err := ValidateValues(`{"type": 42}`, map[string]interface{}{})
+	var schemaErr *ValuesSchemaError
+	if err == nil || errors.As(err, &schemaErr) {
+		t.Errorf("Expected a schema compilation error, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package questions
+
+import (
+	"fmt"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// EvaluateCondition evaluates a show_if style expression against answers.
+// Terms compare a variable's answer, as text, with "=" or "!="; "&&" binds
+// tighter than "||", as in Rancher. An empty expression is true, and a term
+// that can't be parsed is false.
+func EvaluateCondition(expression string, answers map[string]interface{}) bool {
+	if strings.TrimSpace(expression) == "" {
+		return true
+	}
+
+	for _, clause := range strings.Split(expression, "||") {
+		if evaluateClause(clause, answers) {
+			return true
+		}
+	}
+	return false
+}
+
+func evaluateClause(clause string, answers map[string]interface{}) bool {
+	for _, term := range strings.Split(clause, "&&") {
+		t, ok := parseConditionTerm(term)
+		if !ok {
+			return false
+		}
+		answer, exists := answers[t.variable]
+		equal := exists && answer != nil && fmt.Sprint(answer) == t.value
+		if equal == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// VisibleQuestions returns the variables of the questions, including
+// subquestions, that Rancher would show for answers. Questions without an
+// answer use their default. Subquestions are shown when their parent is
+// visible and its show_subquestion_if holds: either a full expression, or a
+// bare value compared with the parent's answer.
+func VisibleQuestions(set models.Questions, answers map[string]interface{}) []string {
+	effective := make(map[string]interface{})
+	collectDefaults(effective, set.Questions)
+	for variable, answer := range answers {
+		effective[variable] = answer
+	}
+
+	visible := []string{}
+	var walk func(questions []models.Question)
+	walk = func(questions []models.Question) {
+		for _, q := range questions {
+			if !EvaluateCondition(q.ShowIf, effective) {
+				continue
+			}
+			visible = append(visible, q.Variable)
+			if len(q.SubQuestions) > 0 && subquestionsShown(q, effective) {
+				walk(q.SubQuestions)
+			}
+		}
+	}
+	walk(set.Questions)
+	return visible
+}
+
+// subquestionsShown evaluates a question's show_subquestion_if.
+func subquestionsShown(q models.Question, answers map[string]interface{}) bool {
+	condition := strings.TrimSpace(q.ShowSubquestionIf)
+	if condition == "" {
+		return true
+	}
+	if strings.Contains(condition, "=") {
+		return EvaluateCondition(condition, answers)
+	}
+	answer := answers[q.Variable]
+	return answer != nil && fmt.Sprint(answer) == condition
+}
+
+func collectDefaults(defaults map[string]interface{}, questions []models.Question) {
+	for _, q := range questions {
+		if q.Default != nil {
+			defaults[q.Variable] = q.Default
+		}
+		collectDefaults(defaults, q.SubQuestions)
+	}
+}
@@ -0,0 +1,116 @@
+package questions
+
+import (
+	"strings"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	answers := map[string]interface{}{
+		"ingress.enabled": true,
+		"service.type":    "NodePort",
+		"replicas":        3,
+		"empty":           nil,
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"", true},
+		{"ingress.enabled=true", true},
+		{"ingress.enabled=false", false},
+		{"ingress.enabled!=false", true},
+		{"replicas=3", true},
+		{"service.type=NodePort&&replicas=3", true},
+		{"service.type=NodePort&&replicas=2", false},
+		{"service.type=ClusterIP||replicas=3", true},
+		{"service.type=ClusterIP||replicas=2", false},
+		// && binds tighter than ||
+		{"replicas=2&&ingress.enabled=true||service.type=NodePort", true},
+		{"replicas=2&&service.type=NodePort||ingress.enabled=false", false},
+		{" service.type = NodePort ", true},
+		{"missing=true", false},
+		{"missing!=true", true},
+		{"empty!=x", true},
+		{"malformed", false},
+	}
+
+	for _, tt := range tests {
+		if got := EvaluateCondition(tt.expression, answers); got != tt.want {
+			t.Errorf("EvaluateCondition(%q) = %v, want %v", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestVisibleQuestions(t *testing.T) {
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "advanced", Type: "boolean", Default: false},
+		{Variable: "debug", Type: "boolean", ShowIf: "advanced=true"},
+		{
+			Variable:          "ingress.enabled",
+			Type:              "boolean",
+			Default:           false,
+			ShowSubquestionIf: "true",
+			SubQuestions: []models.Question{
+				{Variable: "ingress.host", Type: "hostname"},
+				{
+					Variable:          "ingress.tls.enabled",
+					Type:              "boolean",
+					ShowSubquestionIf: "ingress.tls.enabled=true&&advanced=true",
+					SubQuestions: []models.Question{
+						{Variable: "ingress.tls.secretName", Type: "string"},
+					},
+				},
+			},
+		},
+		{Variable: "service.nodePort", Type: "int", ShowIf: "service.type=NodePort||service.type=LoadBalancer"},
+	}}
+
+	tests := []struct {
+		name    string
+		answers map[string]interface{}
+		want    []string
+	}{
+		{
+			name: "defaults only",
+			want: []string{"advanced", "ingress.enabled"},
+		},
+		{
+			name:    "first level subquestions",
+			answers: map[string]interface{}{"ingress.enabled": true},
+			want:    []string{"advanced", "ingress.enabled", "ingress.host", "ingress.tls.enabled"},
+		},
+		{
+			name:    "second level needs a compound condition",
+			answers: map[string]interface{}{"ingress.enabled": true, "ingress.tls.enabled": true},
+			want:    []string{"advanced", "ingress.enabled", "ingress.host", "ingress.tls.enabled"},
+		},
+		{
+			name:    "second level visible",
+			answers: map[string]interface{}{"ingress.enabled": true, "ingress.tls.enabled": true, "advanced": true},
+			want:    []string{"advanced", "debug", "ingress.enabled", "ingress.host", "ingress.tls.enabled", "ingress.tls.secretName"},
+		},
+		{
+			name:    "hidden parent hides nested answers",
+			answers: map[string]interface{}{"ingress.enabled": false, "ingress.tls.enabled": true, "advanced": true},
+			want:    []string{"advanced", "debug", "ingress.enabled"},
+		},
+		{
+			name:    "or condition",
+			answers: map[string]interface{}{"service.type": "LoadBalancer"},
+			want:    []string{"advanced", "ingress.enabled", "service.nodePort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VisibleQuestions(set, tt.answers)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("VisibleQuestions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package questions
+
+import (
+	"sort"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// OrderByGroupWeight returns a copy of set with its questions ordered so that
+// groups appear by ascending weight. Groups without a weight count as 0, and
+// ties keep the order in which groups first appear. Questions within a group
+// keep their relative order. Without any weights the order is unchanged.
+func OrderByGroupWeight(set models.Questions) models.Questions {
+	ordered := set.Clone()
+	if len(set.Groups) == 0 {
+		return ordered
+	}
+
+	weights := make(map[string]int, len(set.Groups))
+	for _, group := range set.Groups {
+		weights[group.Name] = group.Weight
+	}
+
+	firstSeen := make(map[string]int)
+	for i, q := range ordered.Questions {
+		if _, seen := firstSeen[q.Group]; !seen {
+			firstSeen[q.Group] = i
+		}
+	}
+
+	sort.SliceStable(ordered.Questions, func(i, j int) bool {
+		a, b := ordered.Questions[i].Group, ordered.Questions[j].Group
+		if weights[a] != weights[b] {
+			return weights[a] < weights[b]
+		}
+		return firstSeen[a] < firstSeen[b]
+	})
+	return ordered
+}
+
+// GroupCount is the number of top-level questions in a group.
+type GroupCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// GroupCounts lists the distinct groups of set in order of first appearance,
+// with how many top-level questions each holds.
+func GroupCounts(set models.Questions) []GroupCount {
+	counts := []GroupCount{}
+	index := make(map[string]int)
+	for _, q := range set.Questions {
+		i, seen := index[q.Group]
+		if !seen {
+			i = len(counts)
+			index[q.Group] = i
+			counts = append(counts, GroupCount{Name: q.Group})
+		}
+		counts[i].Count++
+	}
+	return counts
+}
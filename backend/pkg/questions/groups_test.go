@@ -0,0 +1,60 @@
+package questions
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func variables(set models.Questions) string {
+	names := make([]string, len(set.Questions))
+	for i, q := range set.Questions {
+		names[i] = q.Variable
+	}
+	return strings.Join(names, ",")
+}
+
+func TestOrderByGroupWeight(t *testing.T) {
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "name", Group: "General"},
+		{Variable: "service.type", Group: "Networking"},
+		{Variable: "persistence.size", Group: "Storage"},
+		{Variable: "ingress.host", Group: "Networking"},
+		{Variable: "namespace", Group: "General"},
+	}}
+
+	if got := variables(OrderByGroupWeight(set)); got != "name,service.type,persistence.size,ingress.host,namespace" {
+		t.Errorf("Expected order to be unchanged without weights, got %s", got)
+	}
+
+	set.Groups = []models.GroupMeta{{Name: "Storage", Weight: -10}, {Name: "General", Weight: 5}}
+	ordered := OrderByGroupWeight(set)
+	if got := variables(ordered); got != "persistence.size,service.type,ingress.host,name,namespace" {
+		t.Errorf("Unexpected weighted order: %s", got)
+	}
+	if len(ordered.Groups) != 2 {
+		t.Errorf("Expected group weights to be kept, got %v", ordered.Groups)
+	}
+	if set.Questions[0].Variable != "name" {
+		t.Error("OrderByGroupWeight modified its input")
+	}
+}
+
+func TestGroupCounts(t *testing.T) {
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "name", Group: "General"},
+		{Variable: "service.type", Group: "Networking"},
+		{Variable: "namespace", Group: "General"},
+		{Variable: "persistence.size", Group: "Storage"},
+		{Variable: "ingress.host", Group: "Networking"},
+		{Variable: "replicas", Group: "General"},
+	}}
+
+	got := GroupCounts(set)
+	expected := []GroupCount{{"General", 3}, {"Networking", 2}, {"Storage", 1}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("GroupCounts() = %v, want %v", got, expected)
+	}
+}
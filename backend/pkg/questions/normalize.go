@@ -0,0 +1,48 @@
+package questions
+
+import (
+	"encoding/json"
+	"math"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// maxExactFloatInt is the largest integer a float64 holds exactly.
+const maxExactFloatInt = 1 << 53
+
+// NormalizeDefaults returns a copy of set whose defaults have the Go types
+// YAML should render: integral numbers decoded from JSON (float64 or
+// json.Number) become int64, other json.Numbers become float64, and bools and
+// strings are untouched. Defaults of float questions stay floats.
+func NormalizeDefaults(set models.Questions) models.Questions {
+	normalized := set.Clone()
+	normalizeDefaults(normalized.Questions)
+	return normalized
+}
+
+func normalizeDefaults(questions []models.Question) {
+	for i := range questions {
+		questions[i].Default = normalizeNumber(questions[i].Default, questions[i].Type == "float")
+		normalizeDefaults(questions[i].SubQuestions)
+	}
+}
+
+func normalizeNumber(value interface{}, keepFloat bool) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if !keepFloat {
+			if i, err := v.Int64(); err == nil {
+				return i
+			}
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case float64:
+		if !keepFloat && v == math.Trunc(v) && math.Abs(v) <= maxExactFloatInt {
+			return int64(v)
+		}
+	}
+	return value
+}
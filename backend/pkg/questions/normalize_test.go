@@ -0,0 +1,41 @@
+package questions
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestNormalizeDefaults(t *testing.T) {
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "replicas", Type: "int", Default: float64(3)},
+		{Variable: "port", Type: "int", Default: json.Number("80")},
+		{Variable: "big", Type: "int", Default: json.Number("9007199254740993")},
+		{Variable: "ratio", Type: "float", Default: json.Number("1")},
+		{Variable: "half", Type: "string", Default: float64(0.5)},
+		{Variable: "enabled", Type: "boolean", Default: true},
+		{Variable: "tag", Type: "string", Default: "80"},
+		{
+			Variable:     "parent",
+			Type:         "boolean",
+			SubQuestions: []models.Question{{Variable: "child", Type: "int", Default: float64(10)}},
+		},
+	}}
+
+	normalized := NormalizeDefaults(set)
+
+	expected := []interface{}{int64(3), int64(80), int64(9007199254740993), float64(1), 0.5, true, "80", nil}
+	for i, want := range expected {
+		if got := normalized.Questions[i].Default; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s default = %#v, want %#v", normalized.Questions[i].Variable, got, want)
+		}
+	}
+	if got := normalized.Questions[7].SubQuestions[0].Default; got != int64(10) {
+		t.Errorf("Expected subquestion default to be normalized, got %#v", got)
+	}
+	if set.Questions[0].Default != float64(3) {
+		t.Error("NormalizeDefaults modified its input")
+	}
+}
@@ -0,0 +1,113 @@
+package questions
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ordering is a house style for the order of questions, e.g.
+//
+//	groups:
+//	  General: -10
+//	  Advanced: 100
+//	variables:
+//	  image.repository: -1
+//
+// Lower weights come first. Unlisted groups and variables weigh 0 and keep
+// their generated order.
+type Ordering struct {
+	Groups    map[string]int `yaml:"groups" json:"groups"`
+	Variables map[string]int `yaml:"variables" json:"variables"`
+}
+
+// ParseOrdering parses an ordering from YAML or JSON.
+func ParseOrdering(data []byte) (*Ordering, error) {
+	// YAML is a superset of JSON, so one parser handles both formats
+	var ordering Ordering
+	if err := yaml.Unmarshal(data, &ordering); err != nil {
+		return nil, fmt.Errorf("failed to parse question ordering: %w", err)
+	}
+	return &ordering, nil
+}
+
+// LoadOrderingFile reads the ordering at path. An empty path means no
+// ordering and returns nil without error.
+func LoadOrderingFile(path string) (*Ordering, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read question ordering file: %w", err)
+	}
+	return ParseOrdering(data)
+}
+
+// Apply returns a copy of set ordered by the ordering: groups by weight, then
+// questions within each group, and subquestions among themselves, by
+// variable weight. Ties keep the order in which groups first appear and the
+// original order of questions. A nil ordering leaves the order unchanged.
+func (o *Ordering) Apply(set models.Questions) models.Questions {
+	ordered := set.Clone()
+	if o == nil || (len(o.Groups) == 0 && len(o.Variables) == 0) {
+		return ordered
+	}
+
+	firstSeen := make(map[string]int)
+	for i, q := range ordered.Questions {
+		if _, seen := firstSeen[q.Group]; !seen {
+			firstSeen[q.Group] = i
+		}
+	}
+
+	sort.SliceStable(ordered.Questions, func(i, j int) bool {
+		a, b := ordered.Questions[i], ordered.Questions[j]
+		if o.Groups[a.Group] != o.Groups[b.Group] {
+			return o.Groups[a.Group] < o.Groups[b.Group]
+		}
+		if firstSeen[a.Group] != firstSeen[b.Group] {
+			return firstSeen[a.Group] < firstSeen[b.Group]
+		}
+		return o.Variables[a.Variable] < o.Variables[b.Variable]
+	})
+	o.orderSubQuestions(ordered.Questions)
+	return ordered
+}
+
+// ApplyAfter is like Apply but leaves the questions whose variables appear in
+// authored, such as a chart's own questions.yaml, first and in the order they
+// have; only the other questions are ordered after them.
+func (o *Ordering) ApplyAfter(set, authored models.Questions) models.Questions {
+	fixed := make(map[string]bool, len(authored.Questions))
+	for _, q := range authored.Questions {
+		fixed[q.Variable] = true
+	}
+
+	ordered := set.Clone()
+	var kept, rest []models.Question
+	for _, q := range ordered.Questions {
+		if fixed[q.Variable] {
+			kept = append(kept, q)
+		} else {
+			rest = append(rest, q)
+		}
+	}
+	ordered.Questions = append(kept, o.Apply(models.Questions{Questions: rest}).Questions...)
+	return ordered
+}
+
+func (o *Ordering) orderSubQuestions(questions []models.Question) {
+	for i := range questions {
+		subs := questions[i].SubQuestions
+		sort.SliceStable(subs, func(a, b int) bool {
+			return o.Variables[subs[a].Variable] < o.Variables[subs[b].Variable]
+		})
+		o.orderSubQuestions(subs)
+	}
+}
@@ -0,0 +1,104 @@
+package questions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestOrderingApply(t *testing.T) {
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "name", Group: "General"},
+		{Variable: "namespace", Group: "General"},
+		{Variable: "image.repository", Group: "Image"},
+		{Variable: "image.tag", Group: "Image"},
+		{Variable: "debug", Group: "Advanced"},
+		{Variable: "service.type", Group: "Service"},
+		{
+			Variable: "persistence.enabled",
+			Group:    "Persistence",
+			SubQuestions: []models.Question{
+				{Variable: "persistence.size"},
+				{Variable: "persistence.storageClass"},
+			},
+		},
+	}}
+
+	ordering, err := ParseOrdering([]byte(`
+groups:
+  Advanced: 100
+  Service: -5
+variables:
+  namespace: -1
+  image.tag: -1
+  persistence.storageClass: -1
+`))
+	if err != nil {
+		t.Fatalf("ParseOrdering failed: %v", err)
+	}
+
+	ordered := ordering.Apply(set)
+	want := "service.type,namespace,name,image.tag,image.repository,persistence.enabled,debug"
+	if got := variables(ordered); got != want {
+		t.Errorf("Apply() order = %s, want %s", got, want)
+	}
+	subs := ordered.Questions[5].SubQuestions
+	if subs[0].Variable != "persistence.storageClass" || subs[1].Variable != "persistence.size" {
+		t.Errorf("Expected weighted subquestion order, got %s, %s", subs[0].Variable, subs[1].Variable)
+	}
+	if set.Questions[0].Variable != "name" {
+		t.Error("Expected Apply not to modify its input")
+	}
+
+	// Without an ordering nothing moves
+	var none *Ordering
+	if got := variables(none.Apply(set)); got != variables(set) {
+		t.Errorf("Expected a nil ordering to keep the order, got %s", got)
+	}
+}
+
+func TestOrderingApplyAfter(t *testing.T) {
+	authored := models.Questions{Questions: []models.Question{
+		{Variable: "debug", Group: "Advanced"},
+		{Variable: "image.tag", Group: "Image"},
+	}}
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "debug", Group: "Advanced"},
+		{Variable: "image.tag", Group: "Image"},
+		{Variable: "image.repository", Group: "Image"},
+		{Variable: "service.type", Group: "Service"},
+		{Variable: "replicaCount", Group: "Advanced"},
+	}}
+
+	ordering := &Ordering{Groups: map[string]int{"Advanced": 100, "Service": -5}}
+	want := "debug,image.tag,service.type,image.repository,replicaCount"
+	if got := variables(ordering.ApplyAfter(set, authored)); got != want {
+		t.Errorf("ApplyAfter() order = %s, want %s", got, want)
+	}
+}
+
+func TestLoadOrderingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ordering.json")
+	os.WriteFile(path, []byte(`{"groups": {"General": -1}, "variables": {"image.tag": 2}}`), 0644)
+
+	ordering, err := LoadOrderingFile(path)
+	if err != nil {
+		t.Fatalf("LoadOrderingFile failed: %v", err)
+	}
+	if ordering.Groups["General"] != -1 || ordering.Variables["image.tag"] != 2 {
+		t.Errorf("Unexpected ordering: %+v", ordering)
+	}
+
+	if ordering, err := LoadOrderingFile(""); ordering != nil || err != nil {
+		t.Errorf("Expected no ordering for an empty path, got %+v, %v", ordering, err)
+	}
+	if _, err := LoadOrderingFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	os.WriteFile(path, []byte("groups: [not, a, map]"), 0644)
+	if _, err := LoadOrderingFile(path); err == nil {
+		t.Error("Expected an error for a malformed file")
+	}
+}
@@ -0,0 +1,177 @@
+package questions
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// schemaDraft is the JSON Schema dialect Helm validates values.schema.json
+// against.
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// ValuesSchema builds a values.schema.json document from a questions set and
+// the chart's values. Only questions whose variable is a path in values are
+// described, so synthetic questions such as name or namespace are left out,
+// and each JSON type comes from the value rather than the question type.
+// Dotted variables become nested object properties, and required questions
+// are listed in the "required" array of the object that holds them.
+// Subquestions are included alongside their parents. Constraints the chart's
+// own value would break are dropped, so the values.yaml always validates.
+func ValuesSchema(set models.Questions, values map[string]interface{}) map[string]interface{} {
+	root := objectSchema()
+	root["$schema"] = schemaDraft
+	addQuestionSchemas(root, set.Questions, values)
+	return root
+}
+
+func addQuestionSchemas(root map[string]interface{}, questions []models.Question, values map[string]interface{}) {
+	for _, q := range questions {
+		if value, ok := lookupValue(values, q.Variable); ok {
+			addQuestionSchema(root, q, value)
+		}
+		addQuestionSchemas(root, q.SubQuestions, values)
+	}
+}
+
+// lookupValue returns the value at the dotted path variable in values.
+func lookupValue(values map[string]interface{}, variable string) (interface{}, bool) {
+	if variable == "" {
+		return nil, false
+	}
+	var current interface{} = values
+	for _, key := range strings.Split(variable, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// addQuestionSchema places the schema for q at its dotted path under root,
+// creating intermediate objects. A question nested under another question's
+// path replaces the shallower one, matching how values are unflattened.
+func addQuestionSchema(root map[string]interface{}, q models.Question, value interface{}) {
+	keys := strings.Split(q.Variable, ".")
+	parent := root
+	for _, key := range keys[:len(keys)-1] {
+		properties := parent["properties"].(map[string]interface{})
+		child, ok := properties[key].(map[string]interface{})
+		if !ok || child["type"] != "object" || child["properties"] == nil {
+			child = objectSchema()
+			properties[key] = child
+		}
+		parent = child
+	}
+
+	leaf := keys[len(keys)-1]
+	properties := parent["properties"].(map[string]interface{})
+	if existing, ok := properties[leaf].(map[string]interface{}); ok && existing["properties"] != nil {
+		return
+	}
+	properties[leaf] = QuestionSchema(q, value)
+
+	if q.Required {
+		required, _ := parent["required"].([]string)
+		if !containsOption(required, leaf) {
+			parent["required"] = append(required, leaf)
+		}
+	}
+}
+
+// QuestionSchema describes the chart value behind a question as a JSON
+// Schema fragment: its type and default come from value, and the question
+// adds a title, description and whichever constraints value satisfies.
+func QuestionSchema(q models.Question, value interface{}) map[string]interface{} {
+	schema := map[string]interface{}{}
+	if valueType := schemaType(value); valueType != "" {
+		schema["type"] = valueType
+	}
+	if q.Label != "" {
+		schema["title"] = q.Label
+	}
+	if q.Description != "" {
+		schema["description"] = q.Description
+	}
+	if value != nil {
+		schema["default"] = value
+	}
+
+	switch v := value.(type) {
+	case string:
+		if q.Type == "enum" && containsOption(q.Options, v) {
+			schema["enum"] = q.Options
+		}
+		if q.MinLength != nil && len(v) >= *q.MinLength {
+			schema["minLength"] = *q.MinLength
+		}
+		if q.MaxLength != nil && len(v) <= *q.MaxLength {
+			schema["maxLength"] = *q.MaxLength
+		}
+	default:
+		if number, ok := schemaNumber(value); ok {
+			if q.Min != nil && number >= float64(*q.Min) {
+				schema["minimum"] = *q.Min
+			}
+			if q.Max != nil && number <= float64(*q.Max) {
+				schema["maximum"] = *q.Max
+			}
+		}
+	}
+	return schema
+}
+
+// schemaType maps a chart value to its JSON Schema type. A null value could
+// be set to anything, so it gets no type.
+func schemaType(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case float32, float64:
+		return "number"
+	case json.Number:
+		if _, err := v.Int64(); err != nil {
+			return "number"
+		}
+		return "integer"
+	case nil:
+		return ""
+	}
+	if isNumber(value) {
+		return "integer"
+	}
+	return ""
+}
+
+// schemaNumber returns a numeric value as a float64.
+func schemaNumber(value interface{}) (float64, bool) {
+	if !isNumber(value) {
+		return 0, false
+	}
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	f, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+	return f, err == nil
+}
+
+func objectSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+}
@@ -0,0 +1,182 @@
+package questions
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestValuesSchema(t *testing.T) {
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "name", Type: "string", Required: true},
+		{Variable: "replicaCount", Type: "int", Required: true, Min: intPtr(1), Max: intPtr(10), Default: "1"},
+		{Variable: "service.type", Type: "enum", Options: []string{"ClusterIP", "NodePort"}, Default: "ClusterIP"},
+		{Variable: "service.port", Type: "int", Required: true},
+		{
+			Variable: "auth.enabled",
+			Type:     "boolean",
+			SubQuestions: []models.Question{
+				{Variable: "auth.password", Type: "password", Required: true, MinLength: intPtr(8)},
+			},
+		},
+		{Variable: "ingress.host", Type: "hostname", ValidChars: "a-z."},
+		{Variable: "extraArgs", Type: "multiline"},
+	}}
+	values := map[string]interface{}{
+		"replicaCount": 1,
+		"service":      map[string]interface{}{"type": "ClusterIP", "port": 80},
+		"auth":         map[string]interface{}{"enabled": false, "password": ""},
+		"ingress":      map[string]interface{}{"host": ""},
+		"extraArgs":    []interface{}{},
+	}
+
+	schema := ValuesSchema(set, values)
+
+	if schema["$schema"] != schemaDraft || schema["type"] != "object" {
+		t.Fatalf("Unexpected root schema: %v", schema)
+	}
+	// name is not a chart value, so it is neither described nor required
+	if required := schema["required"]; !reflect.DeepEqual(required, []string{"replicaCount"}) {
+		t.Errorf("Expected root required [replicaCount], got %v", required)
+	}
+	properties := schema["properties"].(map[string]interface{})
+	if _, ok := properties["name"]; ok {
+		t.Error("Expected no schema for the name question")
+	}
+
+	replicas := properties["replicaCount"].(map[string]interface{})
+	if replicas["type"] != "integer" || replicas["default"] != 1 || replicas["minimum"] != 1 || replicas["maximum"] != 10 {
+		t.Errorf("Unexpected replicaCount schema: %v", replicas)
+	}
+
+	service := properties["service"].(map[string]interface{})
+	if service["type"] != "object" {
+		t.Errorf("Expected service to be an object, got %v", service["type"])
+	}
+	if required := service["required"]; !reflect.DeepEqual(required, []string{"port"}) {
+		t.Errorf("Expected service required [port], got %v", required)
+	}
+	serviceType := service["properties"].(map[string]interface{})["type"].(map[string]interface{})
+	if !reflect.DeepEqual(serviceType["enum"], []string{"ClusterIP", "NodePort"}) || serviceType["default"] != "ClusterIP" {
+		t.Errorf("Unexpected service.type schema: %v", serviceType)
+	}
+
+	auth := properties["auth"].(map[string]interface{})
+	if required := auth["required"]; !reflect.DeepEqual(required, []string{"password"}) {
+		t.Errorf("Expected subquestion to be required in auth, got %v", required)
+	}
+	// The empty default would break a minLength of 8
+	password := auth["properties"].(map[string]interface{})["password"].(map[string]interface{})
+	if _, ok := password["minLength"]; password["type"] != "string" || ok {
+		t.Errorf("Unexpected auth.password schema: %v", password)
+	}
+
+	host := properties["ingress"].(map[string]interface{})["properties"].(map[string]interface{})["host"].(map[string]interface{})
+	if _, ok := host["pattern"]; ok {
+		t.Errorf("Expected valid_chars not to become a pattern, got %v", host["pattern"])
+	}
+
+	if extraArgs := properties["extraArgs"].(map[string]interface{}); extraArgs["type"] != "array" {
+		t.Errorf("Expected extraArgs to be an array, got %v", extraArgs["type"])
+	}
+}
+
+func TestValuesSchemaNestedOverridesLeaf(t *testing.T) {
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "image", Type: "string"},
+		{Variable: "image.tag", Type: "string"},
+	}}
+	values := map[string]interface{}{"image": map[string]interface{}{"tag": "1.0"}}
+
+	properties := ValuesSchema(set, values)["properties"].(map[string]interface{})
+	image := properties["image"].(map[string]interface{})
+	if image["type"] != "object" {
+		t.Fatalf("Expected image to become an object, got %v", image)
+	}
+	if _, ok := image["properties"].(map[string]interface{})["tag"]; !ok {
+		t.Error("Expected image.tag under image")
+	}
+}
+
+func TestValuesSchemaAcceptsChartValues(t *testing.T) {
+	valuesYAML := `replicaCount: 1
+resources:
+  limits:
+    cpu: 500m
+    memory: 512
+ratio: 0.5
+ingress:
+  enabled: false
+  host: ""
+  tls: []
+service:
+  type: ClusterIP
+  port: 80
+password: ""
+tolerations: []
+podAnnotations: {}
+extra: null
+`
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(valuesYAML), &values); err != nil {
+		t.Fatalf("Failed to parse values: %v", err)
+	}
+
+	// Questions as generated or shipped by charts, with quoted defaults
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "name", Type: "string", Required: true},
+		{Variable: "namespace", Type: "string", Required: true},
+		{Variable: "advancedConfig", Type: "boolean", Default: false},
+		{Variable: "replicaCount", Type: "int", Default: "1", Min: intPtr(1), Required: true},
+		{Variable: "resources.limits.cpu", Type: "string", Default: "500m"},
+		{Variable: "resources.limits.memory", Type: "string", Default: "512"},
+		{Variable: "ratio", Type: "string", Default: "0.5"},
+		{Variable: "ingress.enabled", Type: "boolean", Default: "false", SubQuestions: []models.Question{
+			{Variable: "ingress.host", Type: "hostname", Required: true, ValidChars: "a-z0-9.-"},
+			{Variable: "ingress.tls", Type: "multiline"},
+		}},
+		{Variable: "service.type", Type: "enum", Options: []string{"ClusterIP", "NodePort"}},
+		{Variable: "service.port", Type: "int", Default: "80", Max: intPtr(65535)},
+		{Variable: "password", Type: "password", Required: true, MinLength: intPtr(8)},
+		{Variable: "tolerations", Type: "multiline", ShowIf: "advancedConfig=true"},
+		{Variable: "podAnnotations", Type: "multiline"},
+		{Variable: "extra", Type: "string"},
+	}}
+
+	data, err := json.Marshal(ValuesSchema(set, values))
+	if err != nil {
+		t.Fatalf("Failed to marshal schema: %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("values.schema.json", strings.NewReader(string(data))); err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	compiled, err := compiler.Compile("values.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to compile schema: %v", err)
+	}
+
+	// Validate the values as Helm does, after a JSON round trip
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		t.Fatalf("Failed to marshal values: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var instance interface{}
+	if err := decoder.Decode(&instance); err != nil {
+		t.Fatalf("Failed to decode values: %v", err)
+	}
+	if err := compiled.Validate(instance); err != nil {
+		t.Errorf("Expected the chart's values to validate against its schema:\n%s\n%v", data, err)
+	}
+}
@@ -0,0 +1,133 @@
+package questions
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// knownTypes are the question types the Rancher UI can render.
+var knownTypes = map[string]bool{
+	"string":       true,
+	"int":          true,
+	"float":        true,
+	"boolean":      true,
+	"enum":         true,
+	"password":     true,
+	"multiline":    true,
+	"storageclass": true,
+	"hostname":     true,
+	"pvc":          true,
+	"secret":       true,
+	"cron":         true,
+}
+
+// RegisterTypes adds custom question types, e.g. ones provided by a Rancher
+// UI extension, to the known set. It is meant to be called at startup, before
+// any validation runs concurrently.
+func RegisterTypes(types ...string) {
+	for _, questionType := range types {
+		if questionType = strings.TrimSpace(questionType); questionType != "" {
+			knownTypes[questionType] = true
+		}
+	}
+}
+
+// IsKnownType reports whether questionType is a type Rancher can render.
+func IsKnownType(questionType string) bool {
+	return knownTypes[questionType]
+}
+
+// KnownTypes returns the supported question types in sorted order.
+func KnownTypes() []string {
+	types := make([]string, 0, len(knownTypes))
+	for questionType := range knownTypes {
+		types = append(types, questionType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ValidateTypeOverrides checks that every override maps to a known type.
+func ValidateTypeOverrides(overrides map[string]string) error {
+	var problems []string
+	for _, variable := range sortedVariables(overrides) {
+		if !IsKnownType(overrides[variable]) {
+			problems = append(problems, fmt.Sprintf("unknown type %q for %s", overrides[variable], variable))
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ApplyTypeOverrides sets the type of every question (or subquestion) whose
+// variable appears in overrides, replacing whatever type was inferred. Group
+// weights are kept.
+func ApplyTypeOverrides(set models.Questions, overrides map[string]string) models.Questions {
+	if len(overrides) == 0 {
+		return set
+	}
+	updated := models.Questions{Questions: overrideTypes(set.Questions, overrides)}
+	if set.Groups != nil {
+		updated.Groups = append([]models.GroupMeta(nil), set.Groups...)
+	}
+	return updated
+}
+
+func overrideTypes(list []models.Question, overrides map[string]string) []models.Question {
+	if list == nil {
+		return nil
+	}
+	result := make([]models.Question, len(list))
+	for i, question := range list {
+		if questionType, ok := overrides[question.Variable]; ok {
+			question.Type = questionType
+		}
+		question.SubQuestions = overrideTypes(question.SubQuestions, overrides)
+		result[i] = question
+	}
+	return result
+}
+
+// SetTypeByPattern sets questionType on every question (or subquestion) whose
+// variable matches the glob pattern, e.g. "resources.*", and returns the
+// updated set with the matched variables in question order. "*" matches any
+// run of characters, dots included.
+func SetTypeByPattern(set models.Questions, pattern, questionType string) (models.Questions, []string, error) {
+	if !IsKnownType(questionType) {
+		return set, nil, &ValidationError{Problems: []string{fmt.Sprintf("unknown type %q", questionType)}}
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return set, nil, &ValidationError{Problems: []string{fmt.Sprintf("invalid pattern %q", pattern)}}
+	}
+
+	updated := set.Clone()
+	matched := setTypeByPattern(updated.Questions, pattern, questionType)
+	return updated, matched, nil
+}
+
+func setTypeByPattern(list []models.Question, pattern, questionType string) []string {
+	var matched []string
+	for i := range list {
+		if ok, _ := path.Match(pattern, list[i].Variable); ok {
+			list[i].Type = questionType
+			matched = append(matched, list[i].Variable)
+		}
+		matched = append(matched, setTypeByPattern(list[i].SubQuestions, pattern, questionType)...)
+	}
+	return matched
+}
+
+func sortedVariables(overrides map[string]string) []string {
+	variables := make([]string, 0, len(overrides))
+	for variable := range overrides {
+		variables = append(variables, variable)
+	}
+	sort.Strings(variables)
+	return variables
+}
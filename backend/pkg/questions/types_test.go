@@ -0,0 +1,132 @@
+package questions
+
+import (
+	"reflect"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestApplyTypeOverrides(t *testing.T) {
+	set := models.Questions{Questions: []models.Question{
+		{Variable: "auth.token", Type: "string"},
+		{Variable: "service.port", Type: "string"},
+		{
+			Variable:     "config.enabled",
+			Type:         "boolean",
+			SubQuestions: []models.Question{{Variable: "config.yaml", Type: "string"}},
+		},
+	}, Groups: []models.GroupMeta{{Name: "Security", Weight: 10}}}
+
+	result := ApplyTypeOverrides(set, map[string]string{
+		"auth.token":  "password",
+		"config.yaml": "multiline",
+		"missing":     "int",
+	})
+
+	if result.Questions[0].Type != "password" {
+		t.Errorf("Expected auth.token to be password, got %s", result.Questions[0].Type)
+	}
+	if result.Questions[1].Type != "string" {
+		t.Errorf("Expected service.port to keep its type, got %s", result.Questions[1].Type)
+	}
+	if result.Questions[2].SubQuestions[0].Type != "multiline" {
+		t.Errorf("Expected subquestion override, got %s", result.Questions[2].SubQuestions[0].Type)
+	}
+	if set.Questions[0].Type != "string" {
+		t.Error("ApplyTypeOverrides modified its input")
+	}
+	if len(result.Groups) != 1 || result.Groups[0] != set.Groups[0] {
+		t.Errorf("Expected group weights to be kept, got %v", result.Groups)
+	}
+}
+
+func TestValidateTypeOverrides(t *testing.T) {
+	if err := ValidateTypeOverrides(map[string]string{"a": "password", "b": "int"}); err != nil {
+		t.Errorf("Unexpected error for valid overrides: %v", err)
+	}
+
+	err := ValidateTypeOverrides(map[string]string{"a": "password", "b": "passwrd"})
+	if err == nil {
+		t.Fatal("Expected error for unknown type")
+	}
+	validationErr, ok := err.(*ValidationError)
+	if !ok || len(validationErr.Problems) != 1 {
+		t.Fatalf("Expected one problem, got %v", err)
+	}
+}
+
+func TestSetTypeByPattern(t *testing.T) {
+	set := models.Questions{
+		Questions: []models.Question{
+			{Variable: "resources.limits.cpu", Type: "int"},
+			{Variable: "resources.requests.memory", Type: "string"},
+			{Variable: "replicaCount", Type: "int"},
+			{
+				Variable:     "worker.enabled",
+				Type:         "boolean",
+				SubQuestions: []models.Question{{Variable: "worker.resources.limits.cpu", Type: "int"}},
+			},
+		},
+		Groups: []models.GroupMeta{{Name: "Resources", Weight: 1}},
+	}
+
+	tests := []struct {
+		pattern string
+		changed []string
+	}{
+		{"resources.*", []string{"resources.limits.cpu", "resources.requests.memory"}},
+		{"*.limits.cpu", []string{"resources.limits.cpu", "worker.resources.limits.cpu"}},
+		{"replicaCount", []string{"replicaCount"}},
+		{"missing.*", nil},
+	}
+
+	for _, tt := range tests {
+		updated, changed, err := SetTypeByPattern(set, tt.pattern, "string")
+		if err != nil {
+			t.Fatalf("SetTypeByPattern(%q) error = %v", tt.pattern, err)
+		}
+		if !reflect.DeepEqual(changed, tt.changed) {
+			t.Errorf("SetTypeByPattern(%q) changed %v, want %v", tt.pattern, changed, tt.changed)
+		}
+
+		matched := make(map[string]bool)
+		for _, variable := range tt.changed {
+			matched[variable] = true
+		}
+		var check func(original, result []models.Question)
+		check = func(original, result []models.Question) {
+			for i := range original {
+				want := original[i].Type
+				if matched[original[i].Variable] {
+					want = "string"
+				}
+				if result[i].Type != want {
+					t.Errorf("SetTypeByPattern(%q): %s has type %s, want %s", tt.pattern, result[i].Variable, result[i].Type, want)
+				}
+				check(original[i].SubQuestions, result[i].SubQuestions)
+			}
+		}
+		check(set.Questions, updated.Questions)
+
+		if len(updated.Groups) != 1 {
+			t.Errorf("Expected group weights to be kept, got %v", updated.Groups)
+		}
+	}
+
+	// The input is not modified
+	if set.Questions[0].Type != "int" {
+		t.Errorf("Expected the original questions to be unchanged, got %s", set.Questions[0].Type)
+	}
+}
+
+func TestSetTypeByPatternErrors(t *testing.T) {
+	set := models.Questions{Questions: []models.Question{{Variable: "a", Type: "string"}}}
+
+	if _, _, err := SetTypeByPattern(set, "*", "bogus"); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+	if _, _, err := SetTypeByPattern(set, "[", "string"); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
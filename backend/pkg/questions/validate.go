@@ -0,0 +1,420 @@
+package questions
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// ValidationError lists every problem found in a set of questions.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid questions: " + strings.Join(e.Problems, "; ")
+}
+
+// MaxSubQuestionDepth bounds how deeply subquestions may nest. Rancher
+// renders any depth, but deeper trees are almost always a malformed import.
+const MaxSubQuestionDepth = 5
+
+// ValidateQuestions checks a questions set for structural problems that the
+// Rancher UI can't handle, returning a *ValidationError describing all of them.
+// It also returns warnings about questions that work but are likely to
+// confuse users; those never make the set invalid.
+func ValidateQuestions(questions models.Questions) ([]string, error) {
+	var problems []string
+
+	problems = append(problems, findNestingProblems(questions.Questions, 1)...)
+	problems = append(problems, findDuplicateVariables(questions.Questions)...)
+	problems = append(problems, findUnknownTypes(questions.Questions)...)
+	problems = append(problems, findConditionCycles(questions.Questions)...)
+	problems = append(problems, findRequiredIfProblems(questions.Questions)...)
+	problems = append(problems, findEnumDefaultProblems(questions.Questions)...)
+	problems = append(problems, findDefaultTypeProblems(questions.Questions)...)
+
+	warnings := findDuplicateLabels(questions.Questions)
+
+	if len(problems) > 0 {
+		return warnings, &ValidationError{Problems: problems}
+	}
+	return warnings, nil
+}
+
+// findNestingProblems reports questions whose subquestions nest deeper than
+// MaxSubQuestionDepth. depth is the level of questions, starting at 1.
+func findNestingProblems(questions []models.Question, depth int) []string {
+	var problems []string
+	for _, q := range questions {
+		if len(q.SubQuestions) == 0 {
+			continue
+		}
+		if depth >= MaxSubQuestionDepth {
+			problems = append(problems, fmt.Sprintf("question %s nests subquestions deeper than %d levels", q.Variable, MaxSubQuestionDepth))
+			continue
+		}
+		problems = append(problems, findNestingProblems(q.SubQuestions, depth+1)...)
+	}
+	return problems
+}
+
+// findDuplicateVariables reports variables used by more than one question at
+// any level, since both would write the same value.
+func findDuplicateVariables(questions []models.Question) []string {
+	counts := make(map[string]int)
+	var order []string
+	var count func(questions []models.Question)
+	count = func(questions []models.Question) {
+		for _, q := range questions {
+			if counts[q.Variable] == 0 {
+				order = append(order, q.Variable)
+			}
+			counts[q.Variable]++
+			count(q.SubQuestions)
+		}
+	}
+	count(questions)
+
+	var problems []string
+	for _, variable := range order {
+		if counts[variable] > 1 {
+			problems = append(problems, fmt.Sprintf("variable %s is used by %d questions", variable, counts[variable]))
+		}
+	}
+	return problems
+}
+
+// findDuplicateLabels warns about questions that share a label with another
+// question shown alongside them: top-level questions in the same group, or
+// subquestions of the same parent. Labels are compared ignoring case and
+// surrounding space, and empty labels are skipped.
+func findDuplicateLabels(questions []models.Question) []string {
+	type labelKey struct{ group, label string }
+	variables := make(map[labelKey][]string)
+	var order []models.Question
+	for _, q := range questions {
+		label := strings.ToLower(strings.TrimSpace(q.Label))
+		if label == "" {
+			continue
+		}
+		key := labelKey{q.Group, label}
+		if variables[key] == nil {
+			order = append(order, q)
+		}
+		variables[key] = append(variables[key], q.Variable)
+	}
+
+	var warnings []string
+	for _, first := range order {
+		shared := variables[labelKey{first.Group, strings.ToLower(strings.TrimSpace(first.Label))}]
+		if len(shared) < 2 {
+			continue
+		}
+		where := "without a group"
+		if first.Group != "" {
+			where = fmt.Sprintf("in group %s", first.Group)
+		}
+		warnings = append(warnings, fmt.Sprintf("label %q is used by %d questions %s: %s",
+			strings.TrimSpace(first.Label), len(shared), where, strings.Join(shared, ", ")))
+	}
+	for _, q := range questions {
+		for _, warning := range findDuplicateLabels(q.SubQuestions) {
+			warnings = append(warnings, fmt.Sprintf("subquestions of %s: %s", q.Variable, warning))
+		}
+	}
+	return warnings
+}
+
+// findUnknownTypes reports questions, including subquestions, whose type the
+// Rancher UI can't render. An empty type is allowed; Rancher treats it as a
+// string.
+func findUnknownTypes(questions []models.Question) []string {
+	var problems []string
+	for _, q := range questions {
+		if q.Type != "" && !IsKnownType(q.Type) {
+			problems = append(problems, fmt.Sprintf("question %s has unknown type %q", q.Variable, q.Type))
+		}
+		problems = append(problems, findUnknownTypes(q.SubQuestions)...)
+	}
+	return problems
+}
+
+// findRequiredIfProblems reports questions, including subquestions, whose
+// required_if expression has a term that isn't "variable=value" or
+// "variable!=value", as such a term would never hold.
+func findRequiredIfProblems(questions []models.Question) []string {
+	var problems []string
+	for _, q := range questions {
+		if strings.TrimSpace(q.RequiredIf) != "" && !isValidCondition(q.RequiredIf) {
+			problems = append(problems, fmt.Sprintf("question %s has invalid required_if %q", q.Variable, q.RequiredIf))
+		}
+		problems = append(problems, findRequiredIfProblems(q.SubQuestions)...)
+	}
+	return problems
+}
+
+// isValidCondition reports whether every term of a show_if style expression
+// parses.
+func isValidCondition(expression string) bool {
+	for _, clause := range strings.Split(expression, "||") {
+		for _, term := range strings.Split(clause, "&&") {
+			if _, ok := parseConditionTerm(term); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// findEnumDefaultProblems reports enum questions, including subquestions,
+// whose default is not one of their options.
+func findEnumDefaultProblems(questions []models.Question) []string {
+	var problems []string
+	for _, q := range questions {
+		if q.Type == "enum" && q.Default != nil {
+			value := fmt.Sprint(q.Default)
+			if value != "" && !containsOption(q.Options, value) {
+				problems = append(problems, fmt.Sprintf("enum question %s has default %q which is not one of its options [%s]",
+					q.Variable, value, strings.Join(q.Options, ", ")))
+			}
+		}
+		problems = append(problems, findEnumDefaultProblems(q.SubQuestions)...)
+	}
+	return problems
+}
+
+// findDefaultTypeProblems reports questions, including subquestions, whose
+// default doesn't fit their type: int needs a whole number, float a number
+// and boolean a bool. Rancher questions usually quote defaults, so strings
+// such as "80" or "true" that parse as the type are accepted too. Text types
+// such as string or password accept any scalar. Enum defaults are checked by findEnumDefaultProblems, and custom
+// types are left alone.
+func findDefaultTypeProblems(questions []models.Question) []string {
+	var problems []string
+	for _, q := range questions {
+		if q.Default != nil {
+			if expected, ok := defaultFitsType(q.Type, q.Default); !ok {
+				problems = append(problems, fmt.Sprintf("question %s of type %s has default %v (%T) but needs %s",
+					q.Variable, questionTypeName(q.Type), q.Default, q.Default, expected))
+			}
+		}
+		problems = append(problems, findDefaultTypeProblems(q.SubQuestions)...)
+	}
+	return problems
+}
+
+// defaultFitsType reports whether value is a valid default for
+// questionType and, if not, describes what is expected.
+func defaultFitsType(questionType string, value interface{}) (string, bool) {
+	text, quoted := value.(string)
+	text = strings.TrimSpace(text)
+	switch questionType {
+	case "int":
+		if quoted {
+			_, err := strconv.ParseInt(text, 10, 64)
+			return "a whole number", err == nil
+		}
+		return "a whole number", isWholeNumber(value)
+	case "float":
+		if quoted {
+			_, err := strconv.ParseFloat(text, 64)
+			return "a number", err == nil
+		}
+		return "a number", isNumber(value)
+	case "boolean":
+		if quoted {
+			return "true or false", text == "true" || text == "false"
+		}
+		_, ok := value.(bool)
+		return "true or false", ok
+	case "", "string", "password", "multiline", "hostname", "cron", "storageclass", "pvc", "secret":
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return "a single value", false
+		}
+	}
+	return "", true
+}
+
+func questionTypeName(questionType string) string {
+	if questionType == "" {
+		return "string"
+	}
+	return questionType
+}
+
+// isNumber reports whether value holds a number as decoded from YAML or JSON.
+func isNumber(value interface{}) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	case json.Number:
+		_, err := v.Float64()
+		return err == nil
+	}
+	return false
+}
+
+// isWholeNumber reports whether value holds a number without a fractional
+// part, such as 3 or 3.0.
+func isWholeNumber(value interface{}) bool {
+	switch v := value.(type) {
+	case float32:
+		return float64(v) == math.Trunc(float64(v))
+	case float64:
+		return v == math.Trunc(v)
+	case json.Number:
+		_, err := v.Int64()
+		return err == nil
+	}
+	return isNumber(value)
+}
+
+func containsOption(options []string, value string) bool {
+	for _, option := range options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ConditionVariables returns the variables referenced by a show_if style
+// expression such as "a=true&&b!=x||c=1".
+func ConditionVariables(expression string) []string {
+	var variables []string
+	for _, clause := range strings.Split(expression, "||") {
+		for _, term := range strings.Split(clause, "&&") {
+			if t, ok := parseConditionTerm(term); ok {
+				variables = append(variables, t.variable)
+			}
+		}
+	}
+	return variables
+}
+
+// conditionTerm is one comparison in a show_if expression.
+type conditionTerm struct {
+	variable string
+	value    string
+	negate   bool
+}
+
+// parseConditionTerm parses "variable=value" or "variable!=value".
+func parseConditionTerm(term string) (conditionTerm, bool) {
+	term = strings.TrimSpace(term)
+	idx := strings.Index(term, "=")
+	if idx <= 0 {
+		return conditionTerm{}, false
+	}
+	left := term[:idx]
+	negate := strings.HasSuffix(left, "!")
+	variable := strings.TrimSpace(strings.TrimSuffix(left, "!"))
+	if variable == "" {
+		return conditionTerm{}, false
+	}
+	return conditionTerm{variable: variable, value: strings.TrimSpace(term[idx+1:]), negate: negate}, true
+}
+
+// findConditionCycles builds a dependency graph from show_if and
+// show_subquestion_if references (plus the implicit dependency of a
+// subquestion on its parent) and reports every cycle in it.
+func findConditionCycles(questions []models.Question) []string {
+	graph := make(map[string][]string)
+	addConditionEdges(graph, questions, "")
+
+	nodes := make([]string, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	reported := make(map[string]bool)
+	var problems []string
+	var path []string
+
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = visiting
+		path = append(path, node)
+
+		for _, dep := range graph[node] {
+			if _, known := graph[dep]; !known {
+				// References to plain values rather than questions can't loop
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				cycle := cycleFrom(path, dep)
+				if key := canonicalCycle(cycle); !reported[key] {
+					reported[key] = true
+					problems = append(problems, fmt.Sprintf("show_if cycle detected: %s", strings.Join(append(cycle, dep), " -> ")))
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[node] = done
+	}
+
+	for _, node := range nodes {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+
+	return problems
+}
+
+func addConditionEdges(graph map[string][]string, questions []models.Question, parent string) {
+	for _, q := range questions {
+		if _, exists := graph[q.Variable]; !exists {
+			graph[q.Variable] = nil
+		}
+		graph[q.Variable] = append(graph[q.Variable], ConditionVariables(q.ShowIf)...)
+		if parent != "" {
+			graph[q.Variable] = append(graph[q.Variable], parent)
+		}
+		if len(q.SubQuestions) > 0 {
+			// show_subquestion_if is usually a bare value compared against the
+			// parent, but may also be a full expression referencing others
+			for _, sub := range q.SubQuestions {
+				graph[sub.Variable] = append(graph[sub.Variable], ConditionVariables(q.ShowSubquestionIf)...)
+			}
+			addConditionEdges(graph, q.SubQuestions, q.Variable)
+		}
+	}
+}
+
+// cycleFrom returns the portion of path starting at node.
+func cycleFrom(path []string, node string) []string {
+	for i, v := range path {
+		if v == node {
+			cycle := make([]string, len(path)-i)
+			copy(cycle, path[i:])
+			return cycle
+		}
+	}
+	return nil
+}
+
+// canonicalCycle identifies a cycle independent of its starting point.
+func canonicalCycle(cycle []string) string {
+	sorted := append([]string(nil), cycle...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,446 @@
+package questions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestValidateQuestionsAcyclicChain(t *testing.T) {
+	questions := models.Questions{
+		Questions: []models.Question{
+			{Variable: "security.enabled", Type: "boolean"},
+			{Variable: "security.neuvector.enabled", Type: "boolean", ShowIf: "security.enabled=true"},
+			{Variable: "security.neuvector.username", Type: "string", ShowIf: "security.neuvector.enabled=true&&security.enabled=true"},
+			{Variable: "external.ref", Type: "string", ShowIf: "some.value=x"},
+		},
+	}
+
+	if _, err := ValidateQuestions(questions); err != nil {
+		t.Errorf("Expected acyclic chain to validate, got %v", err)
+	}
+}
+
+func TestValidateQuestionsCycles(t *testing.T) {
+	tests := []struct {
+		name         string
+		questions    []models.Question
+		participants []string
+	}{
+		{
+			name: "two-node cycle",
+			questions: []models.Question{
+				{Variable: "a", ShowIf: "b=true"},
+				{Variable: "b", ShowIf: "a=true"},
+			},
+			participants: []string{"a", "b"},
+		},
+		{
+			name: "three-node cycle",
+			questions: []models.Question{
+				{Variable: "a", ShowIf: "c=true"},
+				{Variable: "b", ShowIf: "a!=false"},
+				{Variable: "c", ShowIf: "x=1||b=true"},
+				{Variable: "x"},
+			},
+			participants: []string{"a", "b", "c"},
+		},
+		{
+			name: "subquestion depends on its parent's dependant",
+			questions: []models.Question{
+				{
+					Variable: "parent",
+					ShowIf:   "child=true",
+					SubQuestions: []models.Question{
+						{Variable: "child"},
+					},
+				},
+			},
+			participants: []string{"parent", "child"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateQuestions(models.Questions{Questions: tt.questions})
+			if err == nil {
+				t.Fatal("Expected a cycle error")
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected *ValidationError, got %T", err)
+			}
+			if len(validationErr.Problems) != 1 {
+				t.Fatalf("Expected the cycle to be reported once, got %v", validationErr.Problems)
+			}
+			for _, participant := range tt.participants {
+				if !strings.Contains(validationErr.Problems[0], participant) {
+					t.Errorf("Expected cycle message to name %s, got %s", participant, validationErr.Problems[0])
+				}
+			}
+		})
+	}
+}
+
+func TestConditionVariables(t *testing.T) {
+	got := ConditionVariables("a=true && b.c!=x || d=1")
+	expected := []string{"a", "b.c", "d"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestValidateQuestionsTypes(t *testing.T) {
+	RegisterTypes("x-color-picker")
+	t.Cleanup(func() { delete(knownTypes, "x-color-picker") })
+
+	tests := []struct {
+		name     string
+		question models.Question
+		wantErr  bool
+	}{
+		{
+			name:     "known type",
+			question: models.Question{Variable: "debug", Type: "boolean"},
+		},
+		{
+			name:     "no type",
+			question: models.Question{Variable: "name"},
+		},
+		{
+			name:     "registered type",
+			question: models.Question{Variable: "theme.color", Type: "x-color-picker"},
+		},
+		{
+			name:     "typo",
+			question: models.Question{Variable: "debug", Type: "boolan"},
+			wantErr:  true,
+		},
+		{
+			name: "subquestion typo",
+			question: models.Question{
+				Variable:     "ingress.enabled",
+				Type:         "boolean",
+				SubQuestions: []models.Question{{Variable: "ingress.host", Type: "hostnmae"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateQuestions(models.Questions{Questions: []models.Question{tt.question}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateQuestions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "unknown type") {
+				t.Errorf("Expected an unknown type error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateQuestionsNestedSubQuestions(t *testing.T) {
+	// nested builds ingress.enabled -> ingress.tls.enabled -> leaf
+	nested := func(leaf models.Question) models.Question {
+		return models.Question{
+			Variable: "ingress.enabled",
+			Type:     "boolean",
+			SubQuestions: []models.Question{{
+				Variable:     "ingress.tls.enabled",
+				Type:         "boolean",
+				SubQuestions: []models.Question{leaf},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		set     []models.Question
+		wantErr string
+	}{
+		{
+			name: "valid two levels",
+			set:  []models.Question{nested(models.Question{Variable: "ingress.tls.secretName", Type: "string"})},
+		},
+		{
+			name:    "enum default at second level",
+			set:     []models.Question{nested(models.Question{Variable: "ingress.tls.issuer", Type: "enum", Default: "vault", Options: []string{"letsencrypt"}})},
+			wantErr: "not one of its options",
+		},
+		{
+			name:    "unknown type at second level",
+			set:     []models.Question{nested(models.Question{Variable: "ingress.tls.secretName", Type: "strng"})},
+			wantErr: "unknown type",
+		},
+		{
+			name:    "show_if cycle at second level",
+			set:     []models.Question{nested(models.Question{Variable: "ingress.tls.secretName", Type: "string", ShowIf: "ingress.tls.secretName=x"})},
+			wantErr: "cycle",
+		},
+		{
+			name: "duplicate across levels",
+			set: []models.Question{
+				nested(models.Question{Variable: "ingress.host", Type: "hostname"}),
+				{Variable: "ingress.host", Type: "hostname"},
+			},
+			wantErr: "variable ingress.host is used by 2 questions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateQuestions(models.Questions{Questions: tt.set})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateQuestionsMaxDepth(t *testing.T) {
+	question := models.Question{Variable: "leaf", Type: "string"}
+	for i := MaxSubQuestionDepth; i > 0; i-- {
+		question = models.Question{Variable: fmt.Sprintf("level%d", i), Type: "boolean", SubQuestions: []models.Question{question}}
+	}
+
+	_, err := ValidateQuestions(models.Questions{Questions: []models.Question{question}})
+	if err == nil || !strings.Contains(err.Error(), "deeper than") {
+		t.Errorf("Expected a nesting depth error, got %v", err)
+	}
+	if _, err := ValidateQuestions(models.Questions{Questions: question.SubQuestions}); err != nil {
+		t.Errorf("Unexpected error at the maximum depth: %v", err)
+	}
+}
+
+func TestValidateQuestionsEnumDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		question models.Question
+		wantErr  bool
+	}{
+		{
+			name:     "default among options",
+			question: models.Question{Variable: "service.type", Type: "enum", Default: "ClusterIP", Options: []string{"ClusterIP", "NodePort"}},
+		},
+		{
+			name:     "no default",
+			question: models.Question{Variable: "service.type", Type: "enum", Options: []string{"ClusterIP", "NodePort"}},
+		},
+		{
+			name:     "default outside options",
+			question: models.Question{Variable: "service.type", Type: "enum", Default: "ExternalName", Options: []string{"ClusterIP", "NodePort"}},
+			wantErr:  true,
+		},
+		{
+			name: "subquestion default outside options",
+			question: models.Question{
+				Variable: "ingress.enabled",
+				Type:     "boolean",
+				SubQuestions: []models.Question{
+					{Variable: "ingress.className", Type: "enum", Default: "traefik", Options: []string{"nginx"}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateQuestions(models.Questions{Questions: []models.Question{tt.question}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateQuestions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "not one of its options") {
+				t.Errorf("Expected a clear enum default error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateQuestionsDefaultTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		question models.Question
+		wantErr  string
+	}{
+		{name: "int with int", question: models.Question{Variable: "replicas", Type: "int", Default: 3}},
+		{name: "int with int64", question: models.Question{Variable: "replicas", Type: "int", Default: int64(3)}},
+		{name: "int with whole float", question: models.Question{Variable: "replicas", Type: "int", Default: 3.0}},
+		{name: "int with json number", question: models.Question{Variable: "replicas", Type: "int", Default: json.Number("3")}},
+		{name: "float with int", question: models.Question{Variable: "ratio", Type: "float", Default: 1}},
+		{name: "float with float", question: models.Question{Variable: "ratio", Type: "float", Default: 0.5}},
+		{name: "boolean with bool", question: models.Question{Variable: "enabled", Type: "boolean", Default: false}},
+		{name: "string with string", question: models.Question{Variable: "name", Type: "string", Default: "web"}},
+		{name: "string with number", question: models.Question{Variable: "tag", Type: "string", Default: 1.25}},
+		{name: "untyped with string", question: models.Question{Variable: "name", Default: "web"}},
+		{name: "password with string", question: models.Question{Variable: "auth.password", Type: "password", Default: "secret"}},
+		{name: "no default", question: models.Question{Variable: "replicas", Type: "int"}},
+		{name: "int with quoted int", question: models.Question{Variable: "port", Type: "int", Default: "80"}},
+		{name: "float with quoted float", question: models.Question{Variable: "ratio", Type: "float", Default: "0.5"}},
+		{name: "boolean with quoted bool", question: models.Question{Variable: "enabled", Type: "boolean", Default: "true"}},
+		{
+			name:     "int with string",
+			question: models.Question{Variable: "replicas", Type: "int", Default: "three"},
+			wantErr:  "question replicas of type int has default three (string) but needs a whole number",
+		},
+		{
+			name:     "int with quoted fraction",
+			question: models.Question{Variable: "replicas", Type: "int", Default: "2.5"},
+			wantErr:  "needs a whole number",
+		},
+		{
+			name:     "int with fraction",
+			question: models.Question{Variable: "replicas", Type: "int", Default: 2.5},
+			wantErr:  "needs a whole number",
+		},
+		{
+			name:     "float with string",
+			question: models.Question{Variable: "ratio", Type: "float", Default: "half"},
+			wantErr:  "needs a number",
+		},
+		{
+			name:     "boolean with string",
+			question: models.Question{Variable: "enabled", Type: "boolean", Default: "yes"},
+			wantErr:  "question enabled of type boolean has default yes (string) but needs true or false",
+		},
+		{
+			name:     "string with list",
+			question: models.Question{Variable: "hosts", Type: "string", Default: []interface{}{"a"}},
+			wantErr:  "needs a single value",
+		},
+		{
+			name: "subquestion mismatch",
+			question: models.Question{
+				Variable: "persistence.enabled",
+				Type:     "boolean",
+				SubQuestions: []models.Question{
+					{Variable: "persistence.size", Type: "int", Default: "10Gi"},
+				},
+			},
+			wantErr: "question persistence.size of type int",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateQuestions(models.Questions{Questions: []models.Question{tt.question}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateQuestionsRequiredIf(t *testing.T) {
+	tests := []struct {
+		name       string
+		requiredIf string
+		wantErr    bool
+	}{
+		{"empty", "", false},
+		{"single term", "auth.enabled=true", false},
+		{"compound", "auth.enabled=true&&auth.mode!=ldap||sso=false", false},
+		{"bare variable", "auth.enabled", true},
+		{"missing variable", "=true", true},
+		{"dangling operator", "auth.enabled=true&&", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := models.Question{Variable: "auth.password", Type: "password", RequiredIf: tt.requiredIf}
+			_, err := ValidateQuestions(models.Questions{Questions: []models.Question{question}})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "question auth.password has invalid required_if") {
+					t.Errorf("Expected an invalid required_if error, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateQuestionsDuplicateLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		set      []models.Question
+		warnings []string
+	}{
+		{
+			name: "same label in one group",
+			set: []models.Question{
+				{Variable: "web.port", Label: "Port", Group: "Networking"},
+				{Variable: "admin.port", Label: "port ", Group: "Networking"},
+			},
+			warnings: []string{`label "Port" is used by 2 questions in group Networking: web.port, admin.port`},
+		},
+		{
+			name: "same label across groups",
+			set: []models.Question{
+				{Variable: "web.port", Label: "Port", Group: "Web"},
+				{Variable: "admin.port", Label: "Port", Group: "Admin"},
+			},
+		},
+		{
+			name: "same label without a group",
+			set: []models.Question{
+				{Variable: "a", Label: "Enabled"},
+				{Variable: "b", Label: "Enabled"},
+			},
+			warnings: []string{`label "Enabled" is used by 2 questions without a group: a, b`},
+		},
+		{
+			name: "empty labels",
+			set: []models.Question{
+				{Variable: "a", Group: "General"},
+				{Variable: "b", Label: " ", Group: "General"},
+			},
+		},
+		{
+			name: "subquestions of one parent",
+			set: []models.Question{
+				{Variable: "ingress.enabled", Label: "Enabled", Type: "boolean", SubQuestions: []models.Question{
+					{Variable: "ingress.host", Label: "Host"},
+					{Variable: "ingress.hostname", Label: "Host"},
+				}},
+				{Variable: "host", Label: "Host"},
+			},
+			warnings: []string{`subquestions of ingress.enabled: label "Host" is used by 2 questions without a group: ingress.host, ingress.hostname`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := ValidateQuestions(models.Questions{Questions: tt.set})
+			if err != nil {
+				t.Fatalf("Expected duplicate labels not to be an error, got %v", err)
+			}
+			if fmt.Sprint(warnings) != fmt.Sprint(tt.warnings) {
+				t.Errorf("ValidateQuestions() warnings = %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package session
 
 import (
-	"fmt"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,69 +13,224 @@ import (
 	"github.com/google/uuid"
 )
 
+// DefaultMaxRevisions is how many earlier question sets a session keeps for
+// undo unless Manager.MaxRevisions says otherwise.
+const DefaultMaxRevisions = 10
+
+// DefaultReuseWindow is how long after creation a session can be reused for
+// the same chart URL unless Manager.ReuseWindow says otherwise.
+const DefaultReuseWindow = 5 * time.Minute
+
+// ErrNoRevisions is returned by Undo when a session has no earlier
+// questions to restore.
+var ErrNoRevisions = errors.New("no revisions to undo")
+
+// Manager creates and updates sessions on top of a SessionStore. Its mutex
+// serializes read-modify-write updates so concurrent edits to one session
+// don't overwrite each other.
 type Manager struct {
-	sessions map[string]*models.Session
-	mutex    sync.RWMutex
+	// MaxRevisions caps the question snapshots kept per session for undo;
+	// zero or less disables the history. Set it before use.
+	MaxRevisions int
+
+	// ReuseWindow is how recently a session must have been created for
+	// FindByChartURL to return it; zero or less disables reuse.
+	ReuseWindow time.Duration
+
+	store SessionStore
+	mutex sync.Mutex
 }
 
+// NewManager returns a manager backed by an in-memory store.
 func NewManager() *Manager {
-	return &Manager{
-		sessions: make(map[string]*models.Session),
-	}
+	return NewManagerWithStore(NewMemoryStore())
 }
 
-func (m *Manager) CreateSession(chartURL string) *models.Session {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+// NewManagerWithStore returns a manager that keeps its sessions in store.
+func NewManagerWithStore(store SessionStore) *Manager {
+	return &Manager{store: store, MaxRevisions: DefaultMaxRevisions, ReuseWindow: DefaultReuseWindow}
+}
 
+func (m *Manager) CreateSession(chartURL string) *models.Session {
 	sessionID := uuid.New().String()
+	now := time.Now()
 	session := &models.Session{
 		ID:        sessionID,
 		ChartURL:  chartURL,
 		Values:    make(map[string]interface{}),
 		Questions: models.Questions{Questions: []models.Question{}},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
-	m.sessions[sessionID] = session
-	return session
+	if err := m.store.Put(session); err != nil {
+		// Callers save the session once processed, which reports the failure
+		slog.Warn("failed to store new session", "session_id", sessionID, "error", err)
+	}
+	return session.Clone()
 }
 
+// GetSession returns a deep copy of the stored session. Changes to it are
+// not visible to other callers until written back with SaveSession or one
+// of the Update methods.
 func (m *Manager) GetSession(sessionID string) (*models.Session, error) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	return m.store.Get(sessionID)
+}
+
+// FindByChartURL returns the most recently created session for chartURL
+// that is younger than ReuseWindow. URLs are compared after normalizing, and
+// sessions still being processed, which have no questions yet, are ignored.
+// It returns ErrSessionNotFound when there is no such session.
+func (m *Manager) FindByChartURL(chartURL string) (*models.Session, error) {
+	if m.ReuseWindow <= 0 {
+		return nil, ErrSessionNotFound
+	}
+	sessions, err := m.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	target := normalizeChartURL(chartURL)
+	var found *models.Session
+	for _, session := range sessions {
+		if normalizeChartURL(session.ChartURL) != target || len(session.Questions.Questions) == 0 {
+			continue
+		}
+		if time.Since(session.CreatedAt) > m.ReuseWindow {
+			continue
+		}
+		if found == nil || session.CreatedAt.After(found.CreatedAt) {
+			found = session
+		}
+	}
+	if found == nil {
+		return nil, ErrSessionNotFound
+	}
+	return found, nil
+}
+
+// normalizeChartURL makes equivalent chart URLs compare equal: surrounding
+// space and trailing slashes are dropped and the scheme and host lowercased.
+func normalizeChartURL(chartURL string) string {
+	chartURL = strings.TrimRight(strings.TrimSpace(chartURL), "/")
+	parsed, err := url.Parse(chartURL)
+	if err != nil || parsed.Host == "" {
+		return chartURL
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	return parsed.String()
+}
+
+// SaveSession replaces the stored session with a copy of the given one,
+// keeping its original creation time.
+func (m *Manager) SaveSession(session *models.Session) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
-	session, exists := m.sessions[sessionID]
-	if !exists {
-		return nil, fmt.Errorf("session not found")
+	existing, err := m.store.Get(session.ID)
+	if err != nil {
+		return err
 	}
 
-	return session, nil
+	saved := session.Clone()
+	saved.CreatedAt = existing.CreatedAt
+	saved.UpdatedAt = time.Now()
+	return m.store.Put(saved)
 }
 
+// UpdateSession replaces the session's questions, keeping the previous set
+// as a revision that Undo can restore.
 func (m *Manager) UpdateSession(sessionID string, questions models.Questions) error {
+	return m.update(sessionID, func(session *models.Session) {
+		m.pushRevision(session)
+		session.Questions = questions
+	})
+}
+
+// Undo restores the session's most recent question revision and returns the
+// updated session. It returns ErrNoRevisions when there is nothing to undo.
+func (m *Manager) Undo(sessionID string) (*models.Session, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	session, exists := m.sessions[sessionID]
-	if !exists {
-		return fmt.Errorf("session not found")
+	session, err := m.store.Get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	last := len(session.Revisions) - 1
+	if last < 0 {
+		return nil, ErrNoRevisions
 	}
 
-	session.Questions = questions
+	session.Questions = session.Revisions[last]
+	session.Revisions = session.Revisions[:last]
 	session.UpdatedAt = time.Now()
-	return nil
+	if err := m.store.Put(session); err != nil {
+		return nil, err
+	}
+	return session.Clone(), nil
+}
+
+// pushRevision records the session's current questions, dropping the oldest
+// revisions beyond MaxRevisions.
+func (m *Manager) pushRevision(session *models.Session) {
+	if m.MaxRevisions <= 0 {
+		session.Revisions = nil
+		return
+	}
+	revisions := append(session.Revisions, session.Questions)
+	if over := len(revisions) - m.MaxRevisions; over > 0 {
+		revisions = append([]models.Questions(nil), revisions[over:]...)
+	}
+	session.Revisions = revisions
+}
+
+func (m *Manager) UpdateValues(sessionID string, values map[string]interface{}) error {
+	return m.update(sessionID, func(session *models.Session) {
+		session.Values = values
+	})
+}
+
+// update applies change to the stored session and writes it back.
+func (m *Manager) update(sessionID string, change func(*models.Session)) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	session, err := m.store.Get(sessionID)
+	if err != nil {
+		return err
+	}
+
+	change(session)
+	session.UpdatedAt = time.Now()
+	return m.store.Put(session)
 }
 
 func (m *Manager) DeleteSession(sessionID string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if _, exists := m.sessions[sessionID]; !exists {
-		return fmt.Errorf("session not found")
+	return m.store.Delete(sessionID)
+}
+
+// Clear deletes every session and returns how many were removed. Sessions
+// that vanish while clearing, e.g. by expiring, are not counted.
+func (m *Manager) Clear() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	sessions, err := m.store.List()
+	if err != nil {
+		slog.Warn("failed to list sessions to clear", "error", err)
+		return 0
 	}
 
-	delete(m.sessions, sessionID)
-	return nil
-}
\ No newline at end of file
+	removed := 0
+	for _, session := range sessions {
+		if err := m.store.Delete(session.ID); err == nil {
+			removed++
+		}
+	}
+	return removed
+}
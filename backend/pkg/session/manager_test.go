@@ -1,6 +1,9 @@
 package session
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -14,8 +17,8 @@ func TestNewManager(t *testing.T) {
 		t.Fatal("NewManager() returned nil")
 	}
 	
-	if manager.sessions == nil {
-		t.Error("sessions map not initialized")
+	if _, ok := manager.store.(*MemoryStore); !ok {
+		t.Errorf("Expected an in-memory store by default, got %T", manager.store)
 	}
 }
 
@@ -165,6 +168,156 @@ func TestDeleteSession(t *testing.T) {
 	}
 }
 
+func TestClear(t *testing.T) {
+	manager := NewManager()
+
+	if removed := manager.Clear(); removed != 0 {
+		t.Errorf("Expected 0 sessions removed from an empty manager, got %d", removed)
+	}
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ids = append(ids, manager.CreateSession("https://charts.example.com/chart.tgz").ID)
+	}
+
+	if removed := manager.Clear(); removed != 3 {
+		t.Errorf("Expected 3 sessions removed, got %d", removed)
+	}
+	for _, id := range ids {
+		if _, err := manager.GetSession(id); err == nil {
+			t.Errorf("Session %s still exists after Clear", id)
+		}
+	}
+
+	// New sessions work after clearing
+	session := manager.CreateSession("https://charts.example.com/chart.tgz")
+	if _, err := manager.GetSession(session.ID); err != nil {
+		t.Errorf("Failed to get session created after Clear: %v", err)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	manager := NewManager()
+	session := manager.CreateSession("https://charts.example.com/chart.tgz")
+
+	if _, err := manager.Undo(session.ID); !errors.Is(err, ErrNoRevisions) {
+		t.Errorf("Expected ErrNoRevisions before any edit, got %v", err)
+	}
+	if _, err := manager.Undo("non-existent"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+
+	edit := func(variables ...string) models.Questions {
+		set := models.Questions{Questions: []models.Question{}}
+		for _, variable := range variables {
+			set.Questions = append(set.Questions, models.Question{Variable: variable, Type: "string"})
+		}
+		if err := manager.UpdateSession(session.ID, set); err != nil {
+			t.Fatalf("Failed to update session: %v", err)
+		}
+		return set
+	}
+	edit("a")
+	edit("a", "b")
+	edit("a", "b", "c")
+
+	for _, want := range []int{2, 1, 0} {
+		undone, err := manager.Undo(session.ID)
+		if err != nil {
+			t.Fatalf("Undo failed: %v", err)
+		}
+		if got := len(undone.Questions.Questions); got != want {
+			t.Errorf("Expected %d questions after undo, got %d", want, got)
+		}
+		stored, _ := manager.GetSession(session.ID)
+		if got := len(stored.Questions.Questions); got != want {
+			t.Errorf("Expected %d stored questions after undo, got %d", want, got)
+		}
+	}
+
+	if _, err := manager.Undo(session.ID); !errors.Is(err, ErrNoRevisions) {
+		t.Errorf("Expected ErrNoRevisions once history is exhausted, got %v", err)
+	}
+}
+
+func TestUndoKeepsMaxRevisions(t *testing.T) {
+	manager := NewManager()
+	manager.MaxRevisions = 2
+	session := manager.CreateSession("https://charts.example.com/chart.tgz")
+
+	for i := 1; i <= 5; i++ {
+		set := models.Questions{Questions: []models.Question{{Variable: fmt.Sprintf("v%d", i), Type: "string"}}}
+		if err := manager.UpdateSession(session.ID, set); err != nil {
+			t.Fatalf("Failed to update session: %v", err)
+		}
+	}
+
+	stored, _ := manager.GetSession(session.ID)
+	if len(stored.Revisions) != 2 {
+		t.Fatalf("Expected 2 revisions kept, got %d", len(stored.Revisions))
+	}
+
+	for _, want := range []string{"v4", "v3"} {
+		undone, err := manager.Undo(session.ID)
+		if err != nil {
+			t.Fatalf("Undo failed: %v", err)
+		}
+		if got := undone.Questions.Questions[0].Variable; got != want {
+			t.Errorf("Expected %s after undo, got %s", want, got)
+		}
+	}
+	if _, err := manager.Undo(session.ID); !errors.Is(err, ErrNoRevisions) {
+		t.Errorf("Expected ErrNoRevisions past the kept history, got %v", err)
+	}
+}
+
+func TestFindByChartURL(t *testing.T) {
+	manager := NewManager()
+
+	if _, err := manager.FindByChartURL("https://charts.example.com/chart.tgz"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound without sessions, got %v", err)
+	}
+
+	// Sessions still being processed have no questions and aren't reused
+	session := manager.CreateSession("https://Charts.Example.com/chart.tgz")
+	if _, err := manager.FindByChartURL("https://charts.example.com/chart.tgz"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected unprocessed session to be skipped, got %v", err)
+	}
+
+	session.Questions = models.Questions{Questions: []models.Question{{Variable: "name", Type: "string"}}}
+	if err := manager.SaveSession(session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	manager.CreateSession("https://charts.example.com/other.tgz")
+
+	for _, chartURL := range []string{
+		"https://Charts.Example.com/chart.tgz",
+		"https://charts.example.com/chart.tgz",
+		" https://charts.example.com/chart.tgz/",
+	} {
+		found, err := manager.FindByChartURL(chartURL)
+		if err != nil {
+			t.Errorf("FindByChartURL(%q) failed: %v", chartURL, err)
+			continue
+		}
+		if found.ID != session.ID {
+			t.Errorf("FindByChartURL(%q) = %s, want %s", chartURL, found.ID, session.ID)
+		}
+	}
+
+	// Outside the window the session is no longer reused
+	manager.ReuseWindow = 10 * time.Millisecond
+	time.Sleep(20 * time.Millisecond)
+	if _, err := manager.FindByChartURL("https://charts.example.com/chart.tgz"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound after the reuse window, got %v", err)
+	}
+
+	manager.ReuseWindow = 0
+	if _, err := manager.FindByChartURL("https://charts.example.com/chart.tgz"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected reuse to be disabled, got %v", err)
+	}
+}
+
 func TestConcurrentAccess(t *testing.T) {
 	manager := NewManager()
 	numGoroutines := 100
@@ -281,7 +434,10 @@ func TestSessionDataIntegrity(t *testing.T) {
 		"array": []interface{}{1, 2, 3},
 	}
 	
-	session.Values = complexValues
+	// Sessions returned by the manager are copies, so values must be written back
+	if err := manager.UpdateValues(session.ID, complexValues); err != nil {
+		t.Fatalf("Failed to update session values: %v", err)
+	}
 	
 	complexQuestions := models.Questions{
 		Questions: []models.Question{
@@ -373,6 +529,57 @@ func TestSessionDataIntegrity(t *testing.T) {
 	}
 }
 
+func TestGetSessionReturnsCopy(t *testing.T) {
+	manager := NewManager()
+	session := manager.CreateSession("https://charts.example.com/chart.tgz")
+
+	stored := &models.Session{
+		ID: session.ID,
+		Values: map[string]interface{}{
+			"image": map[string]interface{}{"tag": "1.0"},
+			"hosts": []interface{}{"a.example.com"},
+		},
+		Questions: models.Questions{
+			Questions: []models.Question{
+				{
+					Variable:     "service.type",
+					Options:      []string{"ClusterIP", "NodePort"},
+					SubQuestions: []models.Question{{Variable: "service.nodePort"}},
+				},
+			},
+		},
+	}
+	if err := manager.SaveSession(stored); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	// Mutating the saved input must not leak into the store either
+	stored.Values["image"].(map[string]interface{})["tag"] = "changed"
+
+	retrieved, _ := manager.GetSession(session.ID)
+	retrieved.Values["image"].(map[string]interface{})["tag"] = "2.0"
+	retrieved.Values["hosts"].([]interface{})[0] = "b.example.com"
+	retrieved.Values["new"] = true
+	retrieved.Questions.Questions[0].Variable = "mutated"
+	retrieved.Questions.Questions[0].Options[0] = "LoadBalancer"
+	retrieved.Questions.Questions[0].SubQuestions[0].Variable = "mutated"
+
+	fresh, _ := manager.GetSession(session.ID)
+	if tag := fresh.Values["image"].(map[string]interface{})["tag"]; tag != "1.0" {
+		t.Errorf("Stored nested value changed to %v", tag)
+	}
+	if host := fresh.Values["hosts"].([]interface{})[0]; host != "a.example.com" {
+		t.Errorf("Stored list value changed to %v", host)
+	}
+	if _, exists := fresh.Values["new"]; exists {
+		t.Error("Stored values gained a key added to a copy")
+	}
+	q := fresh.Questions.Questions[0]
+	if q.Variable != "service.type" || q.Options[0] != "ClusterIP" || q.SubQuestions[0].Variable != "service.nodePort" {
+		t.Errorf("Stored question changed: %+v", q)
+	}
+}
+
 func TestSessionTimestamps(t *testing.T) {
 	manager := NewManager()
 	
@@ -482,4 +689,75 @@ func BenchmarkConcurrentOperations(b *testing.B) {
 			manager.DeleteSession(session.ID)
 		}
 	})
-}
\ No newline at end of file
+}
+// recordingStore is a SessionStore that logs every call before passing it to
+// an in-memory store, optionally failing Put.
+type recordingStore struct {
+	*MemoryStore
+	calls  []string
+	putErr error
+}
+
+func (s *recordingStore) Get(id string) (*models.Session, error) {
+	s.calls = append(s.calls, "Get "+id)
+	return s.MemoryStore.Get(id)
+}
+
+func (s *recordingStore) Put(session *models.Session) error {
+	s.calls = append(s.calls, "Put "+session.ID)
+	if s.putErr != nil {
+		return s.putErr
+	}
+	return s.MemoryStore.Put(session)
+}
+
+func (s *recordingStore) Delete(id string) error {
+	s.calls = append(s.calls, "Delete "+id)
+	return s.MemoryStore.Delete(id)
+}
+
+func TestManagerDelegatesToStore(t *testing.T) {
+	store := &recordingStore{MemoryStore: NewMemoryStore()}
+	manager := NewManagerWithStore(store)
+
+	session := manager.CreateSession("https://charts.example.com/chart.tgz")
+	id := session.ID
+	if _, err := manager.GetSession(id); err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if err := manager.UpdateSession(id, models.Questions{}); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+	if err := manager.UpdateValues(id, map[string]interface{}{"a": 1}); err != nil {
+		t.Fatalf("UpdateValues() error = %v", err)
+	}
+	if err := manager.SaveSession(session); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	if err := manager.DeleteSession(id); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	if _, err := manager.GetSession(id); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound after delete, got %v", err)
+	}
+
+	expected := []string{
+		"Put " + id,
+		"Get " + id,
+		"Get " + id, "Put " + id,
+		"Get " + id, "Put " + id,
+		"Get " + id, "Put " + id,
+		"Delete " + id,
+		"Get " + id,
+	}
+	if strings.Join(store.calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected store calls %v, got %v", expected, store.calls)
+	}
+
+	// Store failures reach the caller
+	stored := manager.CreateSession("https://charts.example.com/chart.tgz")
+	store.putErr = errors.New("store unavailable")
+	if err := manager.UpdateValues(stored.ID, nil); err == nil || err.Error() != "store unavailable" {
+		t.Errorf("Expected the store error, got %v", err)
+	}
+}
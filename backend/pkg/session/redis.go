@@ -0,0 +1,167 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"rancher-questions-generator/internal/models"
+	"rancher-questions-generator/pkg/questions"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisKeyPrefix namespaces session keys in a shared Redis.
+	redisKeyPrefix = "rqg:session:"
+	// defaultSessionTTL is how long an untouched session lives in Redis.
+	defaultSessionTTL = 24 * time.Hour
+	// redisConnectTimeout bounds the startup connectivity check.
+	redisConnectTimeout = 3 * time.Second
+)
+
+// RedisStore keeps sessions in Redis as JSON so several replicas behind a
+// load balancer share them. Every write resets the session's expiry to ttl.
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisStore returns a store using client whose sessions expire after ttl
+// without writes.
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+func (s *RedisStore) Get(id string) (*models.Session, error) {
+	data, err := s.client.Get(context.Background(), redisKeyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSession(data)
+}
+
+func (s *RedisStore) Put(session *models.Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(context.Background(), redisKeyPrefix+session.ID, data, s.ttl).Err()
+}
+
+func (s *RedisStore) Delete(id string) error {
+	deleted, err := s.client.Del(context.Background(), redisKeyPrefix+id).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *RedisStore) List() ([]*models.Session, error) {
+	ctx := context.Background()
+	var sessions []*models.Session
+	iter := s.client.Scan(ctx, 0, redisKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		data, err := s.client.Get(ctx, iter.Val()).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// Expired or deleted since the scan saw it
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		session, err := decodeSession(data)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, iter.Err()
+}
+
+// decodeSession reads a session written by Put. Numbers are restored to the
+// types YAML decoding produces (int for integers) rather than float64, so
+// values and defaults render the same as before they were stored.
+func decodeSession(data []byte) (*models.Session, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var session models.Session
+	if err := decoder.Decode(&session); err != nil {
+		return nil, err
+	}
+
+	if values, ok := restoreNumbers(session.Values).(map[string]interface{}); ok {
+		session.Values = values
+	}
+	session.Questions = questions.NormalizeDefaults(session.Questions)
+	session.OriginalQuestions = questions.NormalizeDefaults(session.OriginalQuestions)
+	for i, revision := range session.Revisions {
+		session.Revisions[i] = questions.NormalizeDefaults(revision)
+	}
+	return &session, nil
+}
+
+func restoreNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			if i, err := v.Int64(); err == nil {
+				return int(i)
+			}
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = restoreNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = restoreNumbers(item)
+		}
+	}
+	return value
+}
+
+// NewStoreFromEnv returns a RedisStore when REDIS_URL is set (e.g.
+// redis://redis:6379/0), with sessions expiring after SESSION_TTL (default
+// 24h). Without REDIS_URL, or when Redis can't be reached at startup, it
+// falls back to an in-memory store and logs a warning.
+func NewStoreFromEnv() SessionStore {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		return NewMemoryStore()
+	}
+
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		slog.Warn("invalid REDIS_URL, using in-memory sessions", "error", err)
+		return NewMemoryStore()
+	}
+	client := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		slog.Warn("Redis unreachable, using in-memory sessions", "addr", options.Addr, "error", err)
+		client.Close()
+		return NewMemoryStore()
+	}
+
+	ttl := defaultSessionTTL
+	if value, err := time.ParseDuration(os.Getenv("SESSION_TTL")); err == nil && value > 0 {
+		ttl = value
+	}
+	return NewRedisStore(client, ttl)
+}
@@ -0,0 +1,125 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"rancher-questions-generator/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisStore(t *testing.T, ttl time.Duration) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisStore(client, ttl), server
+}
+
+func TestRedisStoreLifecycle(t *testing.T) {
+	store, _ := newTestRedisStore(t, time.Hour)
+	manager := NewManagerWithStore(store)
+
+	session := manager.CreateSession("https://charts.example.com/chart.tgz")
+	values := map[string]interface{}{
+		"replicaCount": 3,
+		"ratio":        0.5,
+		"image":        map[string]interface{}{"tag": "1.25"},
+		"ports":        []interface{}{80, 443},
+	}
+	if err := manager.UpdateValues(session.ID, values); err != nil {
+		t.Fatalf("UpdateValues() error = %v", err)
+	}
+	set := models.Questions{Questions: []models.Question{{Variable: "replicaCount", Type: "int", Default: 3}}}
+	if err := manager.UpdateSession(session.ID, set); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+
+	stored, err := manager.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if stored.ChartURL != session.ChartURL || !stored.CreatedAt.Equal(session.CreatedAt) {
+		t.Errorf("Unexpected stored session: %+v", stored)
+	}
+	// Numbers come back with the types they were stored with
+	if stored.Values["replicaCount"] != 3 || stored.Values["ratio"] != 0.5 {
+		t.Errorf("Unexpected values %v", stored.Values)
+	}
+	if ports := stored.Values["ports"].([]interface{}); ports[1] != 443 {
+		t.Errorf("Unexpected ports %v", ports)
+	}
+	if tag := stored.Values["image"].(map[string]interface{})["tag"]; tag != "1.25" {
+		t.Errorf("Expected the string tag to stay a string, got %#v", tag)
+	}
+	if got := stored.Questions.Questions[0].Default; got != int64(3) {
+		t.Errorf("Expected an integer default, got %#v", got)
+	}
+
+	sessions, err := store.List()
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("List() = %d sessions, %v; want 1", len(sessions), err)
+	}
+
+	if err := manager.DeleteSession(session.ID); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	if _, err := manager.GetSession(session.ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound after delete, got %v", err)
+	}
+	if err := manager.DeleteSession(session.ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestRedisStoreExpiry(t *testing.T) {
+	store, server := newTestRedisStore(t, time.Hour)
+	manager := NewManagerWithStore(store)
+
+	session := manager.CreateSession("https://charts.example.com/chart.tgz")
+	server.FastForward(45 * time.Minute)
+
+	// Writes push the expiry back out
+	if err := manager.UpdateValues(session.ID, map[string]interface{}{"a": 1}); err != nil {
+		t.Fatalf("UpdateValues() error = %v", err)
+	}
+	server.FastForward(45 * time.Minute)
+	if _, err := manager.GetSession(session.ID); err != nil {
+		t.Fatalf("Expected the session to outlive its original TTL after an update, got %v", err)
+	}
+
+	server.FastForward(16 * time.Minute)
+	if _, err := manager.GetSession(session.ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected the session to expire, got %v", err)
+	}
+}
+
+func TestNewStoreFromEnv(t *testing.T) {
+	t.Setenv("REDIS_URL", "")
+	if _, ok := NewStoreFromEnv().(*MemoryStore); !ok {
+		t.Error("Expected an in-memory store without REDIS_URL")
+	}
+
+	server := miniredis.RunT(t)
+	t.Setenv("REDIS_URL", "redis://"+server.Addr())
+	t.Setenv("SESSION_TTL", "10m")
+	store, ok := NewStoreFromEnv().(*RedisStore)
+	if !ok {
+		t.Fatal("Expected a Redis store with a reachable REDIS_URL")
+	}
+	defer store.client.Close()
+	if store.ttl != 10*time.Minute {
+		t.Errorf("Expected SESSION_TTL to set the expiry, got %s", store.ttl)
+	}
+
+	// Unreachable Redis falls back rather than failing startup
+	addr := server.Addr()
+	server.Close()
+	t.Setenv("REDIS_URL", "redis://"+addr)
+	if _, ok := NewStoreFromEnv().(*MemoryStore); !ok {
+		t.Error("Expected an in-memory fallback when Redis is unreachable")
+	}
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"errors"
+	"sync"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// ErrSessionNotFound is returned when no session exists for an ID.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionStore persists sessions for a Manager. Implementations must be safe
+// for concurrent use, return ErrSessionNotFound for unknown IDs, and never
+// share session data with callers: Get returns a copy and Put stores one.
+type SessionStore interface {
+	Get(id string) (*models.Session, error)
+	Put(session *models.Session) error
+	Delete(id string) error
+	List() ([]*models.Session, error)
+}
+
+// MemoryStore keeps sessions in process memory. Sessions are lost on restart
+// and not shared between replicas.
+type MemoryStore struct {
+	sessions map[string]*models.Session
+	mutex    sync.RWMutex
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]*models.Session)}
+}
+
+func (s *MemoryStore) Get(id string) (*models.Session, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	session, exists := s.sessions[id]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+	return session.Clone(), nil
+}
+
+func (s *MemoryStore) Put(session *models.Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sessions[session.ID] = session.Clone()
+	return nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.sessions[id]; !exists {
+		return ErrSessionNotFound
+	}
+	delete(s.sessions, id)
+	return nil
+}
+
+func (s *MemoryStore) List() ([]*models.Session, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sessions := make([]*models.Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session.Clone())
+	}
+	return sessions, nil
+}
@@ -0,0 +1,45 @@
+package session
+
+import (
+	"errors"
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+
+	if _, err := store.Get("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+	if err := store.Delete("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+
+	session := &models.Session{ID: "one", Values: map[string]interface{}{"tag": "1.0"}}
+	if err := store.Put(session); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	store.Put(&models.Session{ID: "two"})
+
+	// Neither the stored input nor returned copies share data with the store
+	session.Values["tag"] = "changed"
+	got, _ := store.Get("one")
+	got.Values["tag"] = "also changed"
+	if fresh, _ := store.Get("one"); fresh.Values["tag"] != "1.0" {
+		t.Errorf("Stored value changed to %v", fresh.Values["tag"])
+	}
+
+	sessions, err := store.List()
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("List() = %d sessions, %v; want 2", len(sessions), err)
+	}
+
+	if err := store.Delete("one"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if sessions, _ := store.List(); len(sessions) != 1 || sessions[0].ID != "two" {
+		t.Errorf("Unexpected sessions after delete: %v", sessions)
+	}
+}
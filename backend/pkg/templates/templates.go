@@ -0,0 +1,233 @@
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"rancher-questions-generator/internal/models"
+)
+
+// Template is a named, reusable section of questions that can be merged into
+// a session, mirroring the section templates offered by the frontend.
+type Template struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Questions   []models.Question `json:"questions"`
+}
+
+var builtin = map[string]Template{
+	"ai": {
+		Name:        "ai",
+		Description: "AI Compare configuration with GPU, observability and Ollama settings",
+		Questions: []models.Question{
+			{
+				Variable:    "advancedConfig",
+				Label:       "Enable Advanced",
+				Description: "Show advanced settings like Images, Pipeline Integration, and Automation. Keep disabled for simplified deployment.",
+				Type:        "boolean",
+				Default:     false,
+				Group:       "Main Configuration",
+			},
+			{
+				Variable:    "ollama.gpu.enabled",
+				Label:       "Enable GPU",
+				Description: "Enable GPU acceleration for Ollama. Requires a node with GPU drivers and the k8s device plugin installed.",
+				Type:        "boolean",
+				Default:     false,
+				Group:       "Main Configuration",
+			},
+			{
+				Variable:    "ollama.hardware.type",
+				Label:       "Ollama GPU Type",
+				Description: "Select the target GPU hardware architecture for the Ollama deployment.",
+				Type:        "enum",
+				Required:    true,
+				Default:     "nvidia",
+				Options:     []string{"nvidia", "apple"},
+				ShowIf:      "ollama.gpu.enabled=true",
+				Group:       "Main Configuration",
+			},
+			{
+				Variable:    "frontend.enabled",
+				Label:       "Enable Load Simulator",
+				Description: "Deploy HTTP load simulator for traffic generation and observability monitoring.",
+				Type:        "boolean",
+				Default:     false,
+				Group:       "Main Configuration",
+			},
+			{
+				Variable:    "aiCompare.observability.enabled",
+				Label:       "Enable Observability",
+				Description: "Enable OpenTelemetry observability for GenAI monitoring, cost tracking, and performance metrics.",
+				Type:        "boolean",
+				Default:     false,
+				Group:       "Main Configuration",
+			},
+			{
+				Variable:    "ollama.resources.requests.cpu",
+				Label:       "Ollama CPU Request",
+				Description: "The amount of CPU to reserve for the main Ollama container (e.g., '500m', '1').",
+				Type:        "string",
+				Default:     "2",
+				ShowIf:      "advancedConfig=true",
+				Group:       "Ollama Settings",
+			},
+			{
+				Variable:    "ollama.resources.requests.memory",
+				Label:       "Ollama Memory Request",
+				Description: "The amount of memory to reserve for the main Ollama container (e.g., '1Gi', '2048Mi').",
+				Type:        "string",
+				Default:     "2Gi",
+				ShowIf:      "advancedConfig=true",
+				Group:       "Ollama Settings",
+			},
+			{
+				Variable:    "ollama.persistence.enabled",
+				Label:       "Enable Ollama Model Persistence",
+				Description: "If true, a PersistentVolumeClaim will be created to store Ollama models.",
+				Type:        "boolean",
+				Default:     false,
+				Group:       "Ollama Settings",
+			},
+			{
+				Variable:    "ollama.persistence.size",
+				Label:       "Ollama Model Storage Size",
+				Description: "The size of the PersistentVolumeClaim for Ollama models (e.g., '10Gi', '50Gi').",
+				Type:        "string",
+				Default:     "10Gi",
+				ShowIf:      "ollama.persistence.enabled=true",
+				Group:       "Ollama Settings",
+			},
+		},
+	},
+	"security": {
+		Name:        "security",
+		Description: "NeuVector integration and DLP configuration",
+		Questions: []models.Question{
+			{
+				Variable:    "security.enabled",
+				Label:       "Enable Security Features",
+				Description: "Enable advanced security configurations and integrations",
+				Type:        "boolean",
+				Default:     false,
+				Group:       "Security Settings",
+			},
+			{
+				Variable:    "security.neuvector.enabled",
+				Label:       "Enable NeuVector Integration",
+				Description: "Automatically configure NeuVector DLP sensors. Requires NeuVector to be deployed.",
+				Type:        "boolean",
+				Default:     false,
+				ShowIf:      "security.enabled=true",
+				Group:       "Security Settings",
+			},
+			{
+				Variable:    "security.neuvector.controllerUrl",
+				Label:       "NeuVector Controller URL",
+				Description: "URL to the NeuVector controller service for API configuration",
+				Type:        "string",
+				Default:     "https://neuvector-svc-controller.neuvector.svc.cluster.local:10443",
+				ShowIf:      "security.neuvector.enabled=true",
+				Group:       "Security Settings",
+			},
+			{
+				Variable:    "security.neuvector.username",
+				Label:       "NeuVector Admin Username",
+				Description: "Administrative username for NeuVector API access",
+				Type:        "string",
+				Default:     "admin",
+				ShowIf:      "security.neuvector.enabled=true",
+				Group:       "Security Settings",
+			},
+			{
+				Variable:    "security.neuvector.password",
+				Label:       "NeuVector Admin Password",
+				Description: "Administrative password for NeuVector API access",
+				Type:        "password",
+				Default:     "admin",
+				ShowIf:      "security.neuvector.enabled=true",
+				Group:       "Security Settings",
+			},
+		},
+	},
+	"observability": {
+		Name:        "observability",
+		Description: "OpenTelemetry, monitoring, and GPU stats collection",
+		Questions: []models.Question{
+			{
+				Variable:    "observability.enabled",
+				Label:       "Enable OpenTelemetry Observability",
+				Description: "Enable OpenLIT observability for request tracing, monitoring, and performance metrics.",
+				Type:        "boolean",
+				Default:     false,
+				Group:       "Observability Settings",
+			},
+			{
+				Variable:    "observability.otlpEndpoint",
+				Label:       "OpenTelemetry Collector Endpoint",
+				Description: "OTLP endpoint URL for sending telemetry data",
+				Type:        "string",
+				Default:     "http://opentelemetry-collector.observability.svc.cluster.local:4318",
+				ShowIf:      "observability.enabled=true",
+				Group:       "Observability Settings",
+			},
+			{
+				Variable:    "observability.collectGpuStats",
+				Label:       "Enable GPU Statistics Collection",
+				Description: "Collect GPU utilization and memory usage statistics. Requires GPU nodes.",
+				Type:        "boolean",
+				Default:     false,
+				ShowIf:      "observability.enabled=true",
+				Group:       "Observability Settings",
+			},
+			{
+				Variable:    "observability.sampleRate",
+				Label:       "Trace Sample Rate",
+				Description: "Percentage of traces to sample (0.0 to 1.0)",
+				Type:        "string",
+				Default:     "0.1",
+				ShowIf:      "observability.enabled=true",
+				Group:       "Observability Settings",
+			},
+		},
+	},
+}
+
+// Get looks up a built-in template by name, ignoring case.
+func Get(name string) (Template, error) {
+	template, exists := builtin[strings.ToLower(name)]
+	if !exists {
+		return Template{}, fmt.Errorf("template %s not found", name)
+	}
+	return template, nil
+}
+
+// Names returns the names of all built-in templates in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply merges the template's questions into existing ones. Questions already
+// present in existing take precedence, matching how chart questions win over
+// generated defaults. Group weights of existing are kept.
+func (t Template) Apply(existing models.Questions) models.Questions {
+	merged := existing.Clone()
+	present := make(map[string]bool, len(merged.Questions))
+	for _, q := range merged.Questions {
+		present[q.Variable] = true
+	}
+
+	for _, q := range t.Questions {
+		if !present[q.Variable] {
+			merged.Questions = append(merged.Questions, q)
+		}
+	}
+
+	return merged
+}
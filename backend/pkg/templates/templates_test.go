@@ -0,0 +1,72 @@
+package templates
+
+import (
+	"testing"
+
+	"rancher-questions-generator/internal/models"
+)
+
+func TestGet(t *testing.T) {
+	for _, name := range []string{"ai", "Security", "OBSERVABILITY"} {
+		if _, err := Get(name); err != nil {
+			t.Errorf("Expected template %s to exist: %v", name, err)
+		}
+	}
+
+	if _, err := Get("unknown"); err == nil {
+		t.Error("Expected error for unknown template")
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+	expected := []string{"ai", "observability", "security"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %d templates, got %v", len(expected), names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("Expected %s at position %d, got %s", name, i, names[i])
+		}
+	}
+}
+
+func TestApplyKeepsExistingQuestions(t *testing.T) {
+	template, _ := Get("security")
+	existing := models.Questions{
+		Questions: []models.Question{
+			{Variable: "security.enabled", Label: "Chart Security Toggle", Type: "boolean"},
+			{Variable: "replicaCount", Label: "Replicas", Type: "int"},
+		},
+		Groups: []models.GroupMeta{{Name: "Security Settings", Weight: -1}},
+	}
+
+	merged := template.Apply(existing)
+
+	if len(merged.Groups) != 1 || merged.Groups[0] != existing.Groups[0] {
+		t.Errorf("Expected group weights to be kept, got %v", merged.Groups)
+	}
+
+	if len(merged.Questions) != 2+len(template.Questions)-1 {
+		t.Errorf("Expected %d questions, got %d", 2+len(template.Questions)-1, len(merged.Questions))
+	}
+	if merged.Questions[0].Label != "Chart Security Toggle" {
+		t.Errorf("Existing question was overridden: %+v", merged.Questions[0])
+	}
+
+	showIf := make(map[string]string)
+	for _, q := range merged.Questions {
+		showIf[q.Variable] = q.ShowIf
+	}
+	chain := map[string]string{
+		"security.neuvector.enabled":       "security.enabled=true",
+		"security.neuvector.controllerUrl": "security.neuvector.enabled=true",
+		"security.neuvector.username":      "security.neuvector.enabled=true",
+		"security.neuvector.password":      "security.neuvector.enabled=true",
+	}
+	for variable, condition := range chain {
+		if showIf[variable] != condition {
+			t.Errorf("Expected %s show_if %q, got %q", variable, condition, showIf[variable])
+		}
+	}
+}
@@ -14,7 +14,7 @@ export const api = {
 
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || 'Failed to process chart');
+      throw new Error(error.error?.message || error.error || 'Failed to process chart');
     }
 
     return response.json();
@@ -25,7 +25,7 @@ export const api = {
 
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || 'Failed to get chart');
+      throw new Error(error.error?.message || error.error || 'Failed to get chart');
     }
 
     return response.json();
@@ -42,7 +42,7 @@ export const api = {
 
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || 'Failed to update questions');
+      throw new Error(error.error?.message || error.error || 'Failed to update questions');
     }
   },
 
@@ -51,7 +51,7 @@ export const api = {
 
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || 'Failed to download questions.yaml');
+      throw new Error(error.error?.message || error.error || 'Failed to download questions.yaml');
     }
 
     return response.text();